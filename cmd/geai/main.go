@@ -82,8 +82,11 @@ var (
 		utils.LightModeFlag,
 		utils.SyncModeFlag,
 		utils.GCModeFlag,
+		utils.StateExportIntervalFlag,
+		utils.StateExportDirFlag,
 		utils.LightServFlag,
 		utils.LightPeersFlag,
+		utils.LightServMaxResponseFlag,
 		utils.LightKDFFlag,
 		utils.CacheFlag,
 		utils.CacheDatabaseFlag,
@@ -91,6 +94,7 @@ var (
 		utils.TrieCacheGenFlag,
 		utils.ListenPortFlag,
 		utils.MaxPeersFlag,
+		utils.MinPeersFlag,
 		utils.MaxPendingPeersFlag,
 		utils.EtherAIbaseFlag,
 		utils.GasPriceFlag,
@@ -101,6 +105,8 @@ var (
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
 		utils.NetrestrictFlag,
+		utils.PreferIPv6Flag,
+		utils.PreferIPv4Flag,
 		utils.NodeKeyFileFlag,
 		utils.NodeKeyHexFlag,
 		utils.DeveloperFlag,
@@ -289,18 +295,15 @@ func startNode(ctx *cli.Context, stack *node.Node) {
 		if err := stack.Service(&ethereumai); err != nil {
 			utils.Fatalf("EthereumAI service not running: %v", err)
 		}
-		// Use a reduced number of threads if requested
-		if threads := ctx.GlobalInt(utils.MinerThreadsFlag.Name); threads > 0 {
-			type threaded interface {
-				SetThreads(threads int)
-			}
-			if th, ok := ethereumai.Engine().(threaded); ok {
-				th.SetThreads(threads)
-			}
-		}
 		// Set the gas price to the limits from the CLI and start mining
 		ethereumai.TxPool().SetGasPrice(utils.GlobalBig(ctx, utils.GasPriceFlag.Name))
-		if err := ethereumai.StartMining(true); err != nil {
+		// Only run the default local threads if the nonce search isn't delegated
+		var threads *int
+		if ctx.GlobalIsSet(utils.MinerThreadsFlag.Name) || !ethereumai.RemoteSealing() {
+			count := ctx.GlobalInt(utils.MinerThreadsFlag.Name)
+			threads = &count
+		}
+		if err := ethereumai.StartMining(threads); err != nil {
 			utils.Fatalf("Failed to start mining: %v", err)
 		}
 	}
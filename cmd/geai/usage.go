@@ -76,10 +76,13 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.RinkebyFlag,
 			utils.SyncModeFlag,
 			utils.GCModeFlag,
+			utils.StateExportIntervalFlag,
+			utils.StateExportDirFlag,
 			utils.EaiStatsURLFlag,
 			utils.IdentityFlag,
 			utils.LightServFlag,
 			utils.LightPeersFlag,
+			utils.LightServMaxResponseFlag,
 			utils.LightKDFFlag,
 		},
 	},
@@ -170,11 +173,14 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.BootnodesV5Flag,
 			utils.ListenPortFlag,
 			utils.MaxPeersFlag,
+			utils.MinPeersFlag,
 			utils.MaxPendingPeersFlag,
 			utils.NATFlag,
 			utils.NoDiscoverFlag,
 			utils.DiscoveryV5Flag,
 			utils.NetrestrictFlag,
+			utils.PreferIPv6Flag,
+			utils.PreferIPv4Flag,
 			utils.NodeKeyFileFlag,
 			utils.NodeKeyHexFlag,
 		},
@@ -175,6 +175,14 @@ var (
 		Usage: `Blockchain garbage collection mode ("full", "archive")`,
 		Value: "full",
 	}
+	StateExportIntervalFlag = cli.DurationFlag{
+		Name:  "exportstate.interval",
+		Usage: "Interval of the automatic head state exports (0 = disabled)",
+	}
+	StateExportDirFlag = DirectoryFlag{
+		Name:  "exportstate.dir",
+		Usage: "Directory of the automatic head state exports (default = inside the datadir)",
+	}
 	LightServFlag = cli.IntFlag{
 		Name:  "lightserv",
 		Usage: "Maximum percentage of time allowed for serving LES requests (0-90)",
@@ -185,6 +193,11 @@ var (
 		Usage: "Maximum number of LES client peers",
 		Value: eai.DefaultConfig.LightPeers,
 	}
+	LightServMaxResponseFlag = cli.IntFlag{
+		Name:  "lightservmaxresponse",
+		Usage: "Maximum size in bytes of a single LES response (0 = default)",
+		Value: 0,
+	}
 	LightKDFFlag = cli.BoolFlag{
 		Name:  "lightkdf",
 		Usage: "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -449,6 +462,11 @@ var (
 		Usage: "Maximum number of network peers (network disabled if set to 0)",
 		Value: 25,
 	}
+	MinPeersFlag = cli.IntFlag{
+		Name:  "minpeers",
+		Usage: "Number of network peers below which discovery is ramped up (disabled if set to 0)",
+		Value: 0,
+	}
 	MaxPendingPeersFlag = cli.IntFlag{
 		Name:  "maxpendpeers",
 		Usage: "Maximum number of pending connection attempts (defaults used if set to 0)",
@@ -499,6 +517,14 @@ var (
 		Name:  "netrestrict",
 		Usage: "Restricts network communication to the given IP networks (CIDR masks)",
 	}
+	PreferIPv6Flag = cli.BoolFlag{
+		Name:  "preferipv6",
+		Usage: "Dial discovered IPv6 peers before IPv4 ones",
+	}
+	PreferIPv4Flag = cli.BoolFlag{
+		Name:  "preferipv4",
+		Usage: "Dial discovered IPv4 peers before IPv6 ones",
+	}
 
 	// ATM the url is left to the user and deployment to
 	JSpathFlag = cli.StringFlag{
@@ -834,6 +860,9 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	}
 	log.Info("Maximum peer count", "EAI", eaiPeers, "LES", lightPeers, "total", cfg.MaxPeers)
 
+	if ctx.GlobalIsSet(MinPeersFlag.Name) {
+		cfg.MinPeers = ctx.GlobalInt(MinPeersFlag.Name)
+	}
 	if ctx.GlobalIsSet(MaxPendingPeersFlag.Name) {
 		cfg.MaxPendingPeers = ctx.GlobalInt(MaxPendingPeersFlag.Name)
 	}
@@ -858,6 +887,15 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		}
 		cfg.NetRestrict = list
 	}
+	if ctx.GlobalIsSet(PreferIPv6Flag.Name) && ctx.GlobalIsSet(PreferIPv4Flag.Name) {
+		Fatalf("Options %q and %q are mutually exclusive", PreferIPv6Flag.Name, PreferIPv4Flag.Name)
+	}
+	if ctx.GlobalIsSet(PreferIPv6Flag.Name) {
+		cfg.PreferIPv6 = ctx.GlobalBool(PreferIPv6Flag.Name)
+	}
+	if ctx.GlobalIsSet(PreferIPv4Flag.Name) {
+		cfg.PreferIPv4 = ctx.GlobalBool(PreferIPv4Flag.Name)
+	}
 
 	if ctx.GlobalBool(DeveloperFlag.Name) {
 		// --dev mode can't use p2p networking.
@@ -1037,6 +1075,9 @@ func SetEaiConfig(ctx *cli.Context, stack *node.Node, cfg *eai.Config) {
 	if ctx.GlobalIsSet(LightPeersFlag.Name) {
 		cfg.LightPeers = ctx.GlobalInt(LightPeersFlag.Name)
 	}
+	if ctx.GlobalIsSet(LightServMaxResponseFlag.Name) {
+		cfg.LightServMaxResponseSize = ctx.GlobalInt(LightServMaxResponseFlag.Name)
+	}
 	if ctx.GlobalIsSet(NetworkIdFlag.Name) {
 		cfg.NetworkId = ctx.GlobalUint64(NetworkIdFlag.Name)
 	}
@@ -1051,6 +1092,13 @@ func SetEaiConfig(ctx *cli.Context, stack *node.Node, cfg *eai.Config) {
 	}
 	cfg.NoPruning = ctx.GlobalString(GCModeFlag.Name) == "archive"
 
+	if ctx.GlobalIsSet(StateExportIntervalFlag.Name) {
+		cfg.StateExportInterval = ctx.GlobalDuration(StateExportIntervalFlag.Name)
+	}
+	if ctx.GlobalIsSet(StateExportDirFlag.Name) {
+		cfg.StateExportDir = ctx.GlobalString(StateExportDirFlag.Name)
+	}
+
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cfg.TrieCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
 	}
@@ -1103,6 +1151,7 @@ func SetEaiConfig(ctx *cli.Context, stack *node.Node, cfg *eai.Config) {
 		log.Info("Using developer account", "address", developer.Address)
 
 		cfg.Genesis = core.DeveloperGenesisBlock(uint64(ctx.GlobalInt(DeveloperPeriodFlag.Name)), developer.Address)
+		cfg.AllowAutoEtherAIbase = true
 		if !ctx.GlobalIsSet(GasPriceFlag.Name) {
 			cfg.GasPrice = big.NewInt(1)
 		}
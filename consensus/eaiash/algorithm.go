@@ -48,6 +48,10 @@ const (
 	loopAccesses       = 64      // Number of accesses in hashimoto loop
 )
 
+// EpochLength is the number of blocks sharing the same verification cache and
+// mining dataset.
+const EpochLength = epochLength
+
 // cacheSize returns the size of the eaiash verification cache that belongs to a certain
 // block number.
 func cacheSize(block uint64) uint64 {
@@ -260,9 +264,10 @@ func generateDatasetItem(cache []uint32, index uint32, keccak512 hasher) []byte
 	return mix
 }
 
-// generateDataset generates the entire eaiash dataset for mining.
+// generateDataset generates the entire eaiash dataset for mining, spreading the
+// work over the given number of threads (0 = all CPU cores).
 // This method places the result into dest in machine byte order.
-func generateDataset(dest []uint32, epoch uint64, cache []uint32) {
+func generateDataset(dest []uint32, epoch uint64, cache []uint32, threads int) {
 	// Print some debug logs to allow analysis on low end devices
 	logger := log.New("epoch", epoch)
 
@@ -287,7 +292,9 @@ func generateDataset(dest []uint32, epoch uint64, cache []uint32) {
 	dataset := *(*[]byte)(unsafe.Pointer(&header))
 
 	// Generate the dataset on many goroutines since it takes a while
-	threads := runtime.NumCPU()
+	if threads <= 0 {
+		threads = runtime.NumCPU()
+	}
 	size := uint64(len(dataset))
 
 	var pend sync.WaitGroup
@@ -640,14 +640,17 @@ func TestDatasetGeneration(t *testing.T) {
 		cache := make([]uint32, tt.cacheSize/4)
 		generateCache(cache, tt.epoch, seedHash(tt.epoch*epochLength+1))
 
-		dataset := make([]uint32, tt.datasetSize/4)
-		generateDataset(dataset, tt.epoch, cache)
-
 		want := make([]uint32, tt.datasetSize/4)
 		prepare(want, tt.dataset)
 
-		if !reflect.DeepEqual(dataset, want) {
-			t.Errorf("dataset %d: content mismatch: have %x, want %x", i, dataset, want)
+		// The content must not depend on the number of generator threads
+		for _, threads := range []int{0, 1, 3} {
+			dataset := make([]uint32, tt.datasetSize/4)
+			generateDataset(dataset, tt.epoch, cache, threads)
+
+			if !reflect.DeepEqual(dataset, want) {
+				t.Errorf("dataset %d, %d threads: content mismatch: have %x, want %x", i, threads, dataset, want)
+			}
 		}
 	}
 }
@@ -660,7 +663,7 @@ func TestHashimoto(t *testing.T) {
 	generateCache(cache, 0, make([]byte, 32))
 
 	dataset := make([]uint32, 32*1024/4)
-	generateDataset(dataset, 0, cache)
+	generateDataset(dataset, 0, cache, 0)
 
 	// Create a block to verify
 	hash := hexutil.MustDecode("0xc9149cc0386e689d789a1c2f3d5d169a61a6218ed30e74414dc736e442ef3d1f")
@@ -719,7 +722,7 @@ func TestConcurrentDiskCacheGeneration(t *testing.T) {
 
 		go func(idx int) {
 			defer pend.Done()
-			eaiash := New(Config{cachedir, 0, 1, "", 0, 0, ModeNormal})
+			eaiash := New(Config{cachedir, 0, 1, "", 0, 0, ModeNormal, 0, 0, "", 0, nil})
 			if err := eaiash.VerifySeal(nil, block.Header()); err != nil {
 				t.Errorf("proc %d: block verification failed: %v", idx, err)
 			}
@@ -744,7 +747,7 @@ func BenchmarkSmallDatasetGeneration(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		dataset := make([]uint32, 32*65536/4)
-		generateDataset(dataset, 0, cache)
+		generateDataset(dataset, 0, cache, 0)
 	}
 }
 
@@ -767,7 +770,7 @@ func BenchmarkHashimotoFullSmall(b *testing.B) {
 	generateCache(cache, 0, make([]byte, 32))
 
 	dataset := make([]uint32, 32*65536/4)
-	generateDataset(dataset, 0, cache)
+	generateDataset(dataset, 0, cache, 0)
 
 	hash := hexutil.MustDecode("0xc9149cc0386e689d789a1c2f3d5d169a61a6218ed30e74414dc736e442ef3d1f")
 
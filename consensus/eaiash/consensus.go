@@ -231,7 +231,7 @@ func (eaiash *Eaiash) verifyHeader(chain consensus.ChainReader, header, parent *
 			return errLargeBlockTime
 		}
 	} else {
-		if header.Time.Cmp(big.NewInt(time.Now().Add(allowedFutureBlockTime).Unix())) > 0 {
+		if header.Time.Cmp(big.NewInt(time.Now().Add(eaiash.FutureBlockTolerance()).Unix())) > 0 {
 			return consensus.ErrFutureBlock
 		}
 	}
@@ -288,6 +288,9 @@ func (eaiash *Eaiash) verifyHeader(chain consensus.ChainReader, header, parent *
 // the difficulty that a new block should have when created at time
 // given the parent block's time and difficulty.
 func (eaiash *Eaiash) CalcDifficulty(chain consensus.ChainReader, time uint64, parent *types.Header) *big.Int {
+	if target := eaiash.config.TargetBlockTime; target > 0 {
+		return calcDifficultyTargeted(time, parent, target)
+	}
 	return CalcDifficulty(chain.Config(), time, parent)
 }
 
@@ -306,9 +309,12 @@ func CalcDifficulty(config *params.ChainConfig, time uint64, parent *types.Heade
 	}
 }
 
+// ExpDiffPeriod is the number of blocks after which the difficulty bomb doubles.
+const ExpDiffPeriod = 100000
+
 // Some weird constants to avoid constant memory allocs for them.
 var (
-	expDiffPeriod = big.NewInt(100000)
+	expDiffPeriod = big.NewInt(ExpDiffPeriod)
 	big1          = big.NewInt(1)
 	big2          = big.NewInt(2)
 	big9          = big.NewInt(9)
@@ -321,10 +327,29 @@ var (
 // the difficulty that a new block should have when created at time given the
 // parent block's time and difficulty. The calculation uses the Byzantium rules.
 func calcDifficultyByzantium(time uint64, parent *types.Header) *big.Int {
+	return calcDifficultyBucketed(time, parent, big9)
+}
+
+// calcDifficultyTargeted is the difficulty adjustment algorithm for chains with a
+// custom target block time. It follows the Byzantium rules, scaling the duration
+// bucket so the chain converges to the requested block spacing. Byzantium uses a
+// 9 second bucket for its ~13.5 second spacing, so the bucket is two thirds of the
+// target, but at least a second.
+func calcDifficultyTargeted(timestamp uint64, parent *types.Header, target time.Duration) *big.Int {
+	bucket := int64(target * 2 / 3 / time.Second)
+	if bucket < 1 {
+		bucket = 1
+	}
+	return calcDifficultyBucketed(timestamp, parent, big.NewInt(bucket))
+}
+
+// calcDifficultyBucketed implements the Byzantium difficulty adjustment with the
+// block time bucket size as a parameter.
+func calcDifficultyBucketed(time uint64, parent *types.Header, bucket *big.Int) *big.Int {
 	// https://github.com/ethereumai/EIPs/issues/100.
 	// algorithm:
 	// diff = (parent_diff +
-	//         (parent_diff / 2048 * max((2 if len(parent.uncles) else 1) - ((timestamp - parent.timestamp) // 9), -99))
+	//         (parent_diff / 2048 * max((2 if len(parent.uncles) else 1) - ((timestamp - parent.timestamp) // bucket), -99))
 	//        ) + 2^(periodCount - 2)
 
 	bigTime := new(big.Int).SetUint64(time)
@@ -334,19 +359,19 @@ func calcDifficultyByzantium(time uint64, parent *types.Header) *big.Int {
 	x := new(big.Int)
 	y := new(big.Int)
 
-	// (2 if len(parent_uncles) else 1) - (block_timestamp - parent_timestamp) // 9
+	// (2 if len(parent_uncles) else 1) - (block_timestamp - parent_timestamp) // bucket
 	x.Sub(bigTime, bigParentTime)
-	x.Div(x, big9)
+	x.Div(x, bucket)
 	if parent.UncleHash == types.EmptyUncleHash {
 		x.Sub(big1, x)
 	} else {
 		x.Sub(big2, x)
 	}
-	// max((2 if len(parent_uncles) else 1) - (block_timestamp - parent_timestamp) // 9, -99)
+	// max((2 if len(parent_uncles) else 1) - (block_timestamp - parent_timestamp) // bucket, -99)
 	if x.Cmp(bigMinus99) < 0 {
 		x.Set(bigMinus99)
 	}
-	// parent_diff + (parent_diff / 2048 * max((2 if len(parent.uncles) else 1) - ((timestamp - parent.timestamp) // 9), -99))
+	// parent_diff + (parent_diff / 2048 * max((2 if len(parent.uncles) else 1) - ((timestamp - parent.timestamp) // bucket), -99))
 	y.Div(parent.Difficulty, params.DifficultyBoundDivisor)
 	x.Mul(y, x)
 	x.Add(parent.Difficulty, x)
@@ -376,6 +401,36 @@ func calcDifficultyByzantium(time uint64, parent *types.Header) *big.Int {
 	return x
 }
 
+// BombPeriod returns the number of difficulty bomb periods elapsed at the block
+// with the given number, taking the ice-age delay of the active fork into account.
+// The bomb adds 2^(period - 2) to the difficulty once period exceeds one.
+func BombPeriod(config *params.ChainConfig, number *big.Int) uint64 {
+	delay := new(big.Int).SetUint64(BombDelay(config, number))
+	if number.Cmp(delay) < 0 {
+		return 0
+	}
+	return delay.Sub(number, delay).Div(delay, expDiffPeriod).Uint64()
+}
+
+// BombDelay returns the number of blocks the difficulty bomb is delayed by under
+// the fork rules active at the block with the given number.
+func BombDelay(config *params.ChainConfig, number *big.Int) uint64 {
+	if config.IsByzantium(number) {
+		return big2999999.Uint64() + 1
+	}
+	return 0
+}
+
+// BombDifficulty returns the exponential difficulty component, commonly referred
+// to as "the bomb", of the block with the given number.
+func BombDifficulty(config *params.ChainConfig, number *big.Int) *big.Int {
+	period := BombPeriod(config, number)
+	if period < 2 {
+		return new(big.Int)
+	}
+	return new(big.Int).Lsh(big1, uint(period-2))
+}
+
 // calcDifficultyHomestead is the difficulty adjustment algorithm. It returns
 // the difficulty that a new block should have when created at time given the
 // parent block's time and difficulty. The calculation uses the Homestead rules.
@@ -528,27 +583,39 @@ var (
 	big32 = big.NewInt(32)
 )
 
+// BlockReward returns the static reward for successfully mining the block with
+// the given number, excluding any uncle inclusion rewards.
+func BlockReward(config *params.ChainConfig, number *big.Int) *big.Int {
+	if config.IsByzantium(number) {
+		return ByzantiumBlockReward
+	}
+	return FrontierBlockReward
+}
+
+// UncleReward returns the reward credited to the miner of an uncle included in
+// the given block.
+func UncleReward(config *params.ChainConfig, header *types.Header, uncle *types.Header) *big.Int {
+	r := new(big.Int).Add(uncle.Number, big8)
+	r.Sub(r, header.Number)
+	r.Mul(r, BlockReward(config, header.Number))
+	return r.Div(r, big8)
+}
+
+// UncleInclusionReward returns the reward credited to the miner of the given
+// block for each uncle it includes.
+func UncleInclusionReward(config *params.ChainConfig, header *types.Header) *big.Int {
+	return new(big.Int).Div(BlockReward(config, header.Number), big32)
+}
+
 // AccumulateRewards credits the coinbase of the given block with the mining
 // reward. The total reward consists of the static block reward and rewards for
 // included uncles. The coinbase of each uncle block is also rewarded.
 func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, uncles []*types.Header) {
-	// Select the correct block reward based on chain progression
-	blockReward := FrontierBlockReward
-	if config.IsByzantium(header.Number) {
-		blockReward = ByzantiumBlockReward
-	}
 	// Accumulate the rewards for the miner and any included uncles
-	reward := new(big.Int).Set(blockReward)
-	r := new(big.Int)
+	reward := new(big.Int).Set(BlockReward(config, header.Number))
 	for _, uncle := range uncles {
-		r.Add(uncle.Number, big8)
-		r.Sub(r, header.Number)
-		r.Mul(r, blockReward)
-		r.Div(r, big8)
-		state.AddBalance(uncle.Coinbase, r)
-
-		r.Div(blockReward, big32)
-		reward.Add(reward, r)
+		state.AddBalance(uncle.Coinbase, UncleReward(config, header, uncle))
+		reward.Add(reward, UncleInclusionReward(config, header))
 	}
 	state.AddBalance(header.Coinbase, reward)
 }
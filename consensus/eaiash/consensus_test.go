@@ -22,8 +22,10 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/ethereumai/go-ethereumai/common/math"
+	"github.com/ethereumai/go-ethereumai/consensus"
 	"github.com/ethereumai/go-ethereumai/core/types"
 	"github.com/ethereumai/go-ethereumai/params"
 )
@@ -84,3 +86,87 @@ func TestCalcDifficulty(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the reported difficulty bomb matches the exponential component of the
+// difficulty adjustment under both the Homestead and Byzantium rules.
+func TestBombDifficulty(t *testing.T) {
+	configs := []*params.ChainConfig{
+		{HomesteadBlock: big.NewInt(0)},
+		{HomesteadBlock: big.NewInt(0), ByzantiumBlock: big.NewInt(0)},
+	}
+	for i, config := range configs {
+		for _, number := range []int64{1, 199999, 200000, 2999999, 3000000, 3200000, 4500000} {
+			parent := &types.Header{
+				Number:     big.NewInt(number - 1),
+				Time:       big.NewInt(1000),
+				Difficulty: big.NewInt(1 << 40),
+				UncleHash:  types.EmptyUncleHash,
+			}
+			// A 10 second block time leaves the difficulty unadjusted apart from the bomb
+			want := new(big.Int).Sub(CalcDifficulty(config, 1010, parent), parent.Difficulty)
+			if have := BombDifficulty(config, big.NewInt(number)); have.Cmp(want) != 0 {
+				t.Errorf("config %d, block %d: bomb mismatch: have %v, want %v", i, number, have, want)
+			}
+		}
+	}
+}
+
+// Tests that a custom target block time scales the difficulty adjustment, raising
+// the difficulty for faster blocks and lowering it for slower ones.
+func TestCalcDifficultyTargeted(t *testing.T) {
+	parent := &types.Header{
+		Number:     big.NewInt(1000),
+		Time:       big.NewInt(1000),
+		Difficulty: big.NewInt(10000000),
+		UncleHash:  types.EmptyUncleHash,
+	}
+	tests := []struct {
+		target  time.Duration
+		elapsed uint64
+		cmp     int
+	}{
+		{5 * time.Second, 1, 1},
+		{5 * time.Second, 5, 0},
+		{5 * time.Second, 7, -1},
+		{60 * time.Second, 30, 1},
+		{60 * time.Second, 60, 0},
+		{60 * time.Second, 90, -1},
+	}
+	for i, tt := range tests {
+		diff := calcDifficultyTargeted(parent.Time.Uint64()+tt.elapsed, parent, tt.target)
+		if cmp := diff.Cmp(parent.Difficulty); cmp != tt.cmp {
+			t.Errorf("test %d: difficulty direction mismatch: have %d, want %d (difficulty %v)", i, cmp, tt.cmp, diff)
+		}
+	}
+	// A target matching the Byzantium spacing must reproduce its rules
+	for elapsed := uint64(1); elapsed < 100; elapsed++ {
+		want := calcDifficultyByzantium(parent.Time.Uint64()+elapsed, parent)
+		if have := calcDifficultyTargeted(parent.Time.Uint64()+elapsed, parent, 13500*time.Millisecond); have.Cmp(want) != 0 {
+			t.Errorf("elapsed %d: difficulty mismatch: have %v, want %v", elapsed, have, want)
+		}
+	}
+}
+
+// Tests that the future block tolerance decides whether a header ahead of the
+// local clock is reported as a future block.
+func TestFutureBlockTolerance(t *testing.T) {
+	eaiash := NewFaker()
+
+	ahead := time.Now().Add(time.Minute).Unix()
+	header := &types.Header{Number: big.NewInt(1), Time: big.NewInt(ahead)}
+	parent := &types.Header{Number: big.NewInt(0), Time: big.NewInt(ahead)}
+
+	if err := eaiash.verifyHeader(nil, header, parent, false, false); err != consensus.ErrFutureBlock {
+		t.Fatalf("default tolerance: error mismatch: have %v, want %v", err, consensus.ErrFutureBlock)
+	}
+	// Loosen the tolerance so the header passes the clock check (and fails later
+	// on the equal parent timestamp)
+	eaiash.SetFutureBlockTolerance(2 * time.Minute)
+	if err := eaiash.verifyHeader(nil, header, parent, false, false); err != errZeroBlockTime {
+		t.Fatalf("loose tolerance: error mismatch: have %v, want %v", err, errZeroBlockTime)
+	}
+	eaiash.SetFutureBlockTolerance(0)
+	if err := eaiash.verifyHeader(nil, header, parent, false, false); err != consensus.ErrFutureBlock {
+		t.Fatalf("reset tolerance: error mismatch: have %v, want %v", err, consensus.ErrFutureBlock)
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"runtime"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
@@ -47,7 +48,7 @@ var (
 	maxUint256 = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0))
 
 	// sharedEaiash is a full instance that can be shared between multiple users.
-	sharedEaiash = New(Config{"", 3, 0, "", 1, 0, ModeNormal})
+	sharedEaiash = New(Config{"", 3, 0, "", 1, 0, ModeNormal, 0, 0, "", 0, nil})
 
 	// algorithmRevision is the data structure version used for file naming.
 	algorithmRevision = 23
@@ -288,7 +289,7 @@ func newDataset(epoch uint64) interface{} {
 }
 
 // generate ensures that the dataset content is generated before use.
-func (d *dataset) generate(dir string, limit int, test bool) {
+func (d *dataset) generate(dir string, limit int, test bool, threads int) {
 	d.once.Do(func() {
 		csize := cacheSize(d.epoch*epochLength + 1)
 		dsize := datasetSize(d.epoch*epochLength + 1)
@@ -303,7 +304,7 @@ func (d *dataset) generate(dir string, limit int, test bool) {
 			generateCache(cache, d.epoch, seed)
 
 			d.dataset = make([]uint32, dsize/4)
-			generateDataset(d.dataset, d.epoch, cache)
+			generateDataset(d.dataset, d.epoch, cache, threads)
 		}
 		// Disk storage is needed, this will get fancy
 		var endian string
@@ -330,12 +331,12 @@ func (d *dataset) generate(dir string, limit int, test bool) {
 		cache := make([]uint32, csize/4)
 		generateCache(cache, d.epoch, seed)
 
-		d.dump, d.mmap, d.dataset, err = memoryMapAndGenerate(path, dsize, func(buffer []uint32) { generateDataset(buffer, d.epoch, cache) })
+		d.dump, d.mmap, d.dataset, err = memoryMapAndGenerate(path, dsize, func(buffer []uint32) { generateDataset(buffer, d.epoch, cache, threads) })
 		if err != nil {
 			logger.Error("Failed to generate mapped eaiash dataset", "err", err)
 
 			d.dataset = make([]uint32, dsize/2)
-			generateDataset(d.dataset, d.epoch, cache)
+			generateDataset(d.dataset, d.epoch, cache, threads)
 		}
 		// Iterate over all previous instances and delete old ones
 		for ep := int(d.epoch) - limit; ep >= 0; ep-- {
@@ -364,7 +365,7 @@ func MakeCache(block uint64, dir string) {
 // MakeDataset generates a new eaiash dataset and optionally stores it to disk.
 func MakeDataset(block uint64, dir string) {
 	d := dataset{epoch: block / epochLength}
-	d.generate(dir, math.MaxInt32, false)
+	d.generate(dir, math.MaxInt32, false, 0)
 }
 
 // Mode defines the type and amount of PoW verification an eaiash engine makes.
@@ -387,6 +388,30 @@ type Config struct {
 	DatasetsInMem  int
 	DatasetsOnDisk int
 	PowMode        Mode
+
+	// TargetBlockTime overrides the block spacing the difficulty adjustment aims
+	// for (0 = protocol rules). Only meant for private chains, as all nodes must
+	// agree on it to accept each other's blocks.
+	TargetBlockTime time.Duration `toml:",omitempty"`
+
+	// GenerationThreads limits the number of threads used to generate a mining
+	// dataset (0 = all CPU cores), leaving room for other work on busy nodes.
+	GenerationThreads int `toml:",omitempty"`
+
+	// RemoteSealerURL delegates the nonce search to an external sealer instead of
+	// mining locally. Every new work package is POSTed to it in the format of
+	// eai_getWork, and the sealer submits its result through eai_submitWork.
+	RemoteSealerURL string `toml:",omitempty"`
+
+	// SealDeadline aborts a sealing attempt that didn't find a nonce in time
+	// (0 = no deadline), surfacing misconfigured difficulties instead of silently
+	// searching forever.
+	SealDeadline time.Duration `toml:",omitempty"`
+
+	// NonceSeed makes every seal search the same nonces, seeding the random source
+	// with it instead of crypto/rand, so the sealed nonce of a header can be
+	// asserted. Only meant for testing, as it makes the nonce search predictable!
+	NonceSeed *uint64 `toml:"-"`
 }
 
 // Eaiash is a consensus engine based on proot-of-work implementing the eaiash
@@ -403,6 +428,11 @@ type Eaiash struct {
 	update   chan struct{} // Notification channel to update mining parameters
 	hashrate metrics.Meter // Meter tracking the average hashrate
 
+	threadRates []metrics.Meter // Meters tracking the hashrate of each search thread
+	sealing     *sealTask       // Block currently being sealed locally, if any
+
+	futureTolerance int64 // Max nanoseconds a header may be ahead of the local clock (0 = default, atomic)
+
 	// The fields below are hooks for testing
 	shared    *Eaiash       // Shared PoW verifier to avoid cache regeneration
 	fakeFail  uint64        // Block number which fails PoW check even in fake mode
@@ -423,12 +453,25 @@ func New(config Config) *Eaiash {
 	if config.DatasetDir != "" && config.DatasetsOnDisk > 0 {
 		log.Info("Disk storage enabled for eaiash DAGs", "dir", config.DatasetDir, "count", config.DatasetsOnDisk)
 	}
+	if config.TargetBlockTime < 0 {
+		log.Warn("Eaiash target block time must be positive, using protocol rules", "requested", config.TargetBlockTime)
+		config.TargetBlockTime = 0
+	}
+	if config.TargetBlockTime > 0 {
+		log.Info("Eaiash difficulty targeting custom block time", "target", config.TargetBlockTime)
+	}
+	threads := 0
+	if config.RemoteSealerURL != "" {
+		log.Info("Eaiash sealing delegated to remote sealer", "url", config.RemoteSealerURL)
+		threads = -1
+	}
 	return &Eaiash{
 		config:   config,
 		caches:   newlru("cache", config.CachesInMem, newCache),
 		datasets: newlru("dataset", config.DatasetsInMem, newDataset),
 		update:   make(chan struct{}),
 		hashrate: metrics.NewMeter(),
+		threads:  threads,
 	}
 }
 
@@ -517,12 +560,12 @@ func (eaiash *Eaiash) dataset(block uint64) *dataset {
 	current := currentI.(*dataset)
 
 	// Wait for generation finish.
-	current.generate(eaiash.config.DatasetDir, eaiash.config.DatasetsOnDisk, eaiash.config.PowMode == ModeTest)
+	current.generate(eaiash.config.DatasetDir, eaiash.config.DatasetsOnDisk, eaiash.config.PowMode == ModeTest, eaiash.config.GenerationThreads)
 
 	// If we need a new future dataset, now's a good time to regenerate it.
 	if futureI != nil {
 		future := futureI.(*dataset)
-		go future.generate(eaiash.config.DatasetDir, eaiash.config.DatasetsOnDisk, eaiash.config.PowMode == ModeTest)
+		go future.generate(eaiash.config.DatasetDir, eaiash.config.DatasetsOnDisk, eaiash.config.PowMode == ModeTest, eaiash.config.GenerationThreads)
 	}
 
 	return current
@@ -537,11 +580,18 @@ func (eaiash *Eaiash) Threads() int {
 	return eaiash.threads
 }
 
+// RemoteSealing reports whether the nonce search is delegated to a remote sealer,
+// in which case no local mining threads can be enabled.
+func (eaiash *Eaiash) RemoteSealing() bool {
+	return eaiash.config.RemoteSealerURL != ""
+}
+
 // SetThreads updates the number of mining threads currently enabled. Calling
 // this method does not start mining, only sets the thread count. If zero is
 // specified, the miner will use all cores of the machine. Setting a thread
 // count below zero is allowed and will cause the miner to idle, without any
-// work being done.
+// work being done. Sealing delegated to a remote sealer keeps local mining
+// disabled, ignoring any thread count.
 func (eaiash *Eaiash) SetThreads(threads int) {
 	eaiash.lock.Lock()
 	defer eaiash.lock.Unlock()
@@ -551,6 +601,13 @@ func (eaiash *Eaiash) SetThreads(threads int) {
 		eaiash.shared.SetThreads(threads)
 		return
 	}
+	// Remote sealing never mines locally, ignore the thread count
+	if eaiash.config.RemoteSealerURL != "" {
+		if threads >= 0 {
+			log.Warn("Ignoring local mining threads, sealing is delegated", "threads", threads, "sealer", eaiash.config.RemoteSealerURL)
+		}
+		return
+	}
 	// Update the threads and ping any running seal to pull in any changes
 	eaiash.threads = threads
 	select {
@@ -559,12 +616,66 @@ func (eaiash *Eaiash) SetThreads(threads int) {
 	}
 }
 
+// SetFutureBlockTolerance sets how far ahead of the local clock a block's
+// timestamp may be before the block is reported as a future block instead of
+// being verified. A non-positive tolerance restores the protocol default. Note,
+// the block chain rejects blocks beyond core.MaxFutureBlockTime instead of
+// queueing them, so larger tolerances should not be set.
+func (eaiash *Eaiash) SetFutureBlockTolerance(tolerance time.Duration) {
+	if tolerance < 0 {
+		tolerance = 0
+	}
+	atomic.StoreInt64(&eaiash.futureTolerance, int64(tolerance))
+}
+
+// FutureBlockTolerance returns the time a block may be ahead of the local clock.
+func (eaiash *Eaiash) FutureBlockTolerance() time.Duration {
+	if tolerance := atomic.LoadInt64(&eaiash.futureTolerance); tolerance > 0 {
+		return time.Duration(tolerance)
+	}
+	return allowedFutureBlockTime
+}
+
 // Hashrate implements PoW, returning the measured rate of the search invocations
 // per second over the last minute.
 func (eaiash *Eaiash) Hashrate() float64 {
 	return eaiash.hashrate.Rate1()
 }
 
+// HashratePerThread returns the measured rate of the search invocations per
+// second over the last minute of each search thread, indexed by thread id.
+func (eaiash *Eaiash) HashratePerThread() []float64 {
+	eaiash.lock.Lock()
+	defer eaiash.lock.Unlock()
+
+	// If we're running a shared PoW, report its threads instead
+	if eaiash.shared != nil {
+		return eaiash.shared.HashratePerThread()
+	}
+	rates := make([]float64, len(eaiash.threadRates))
+	for id, meter := range eaiash.threadRates {
+		rates[id] = meter.Rate1()
+	}
+	return rates
+}
+
+// threadMeters returns the hashrate meters of the given number of search threads,
+// reusing the meters of earlier seals and stopping the ones no longer needed.
+func (eaiash *Eaiash) threadMeters(threads int) []metrics.Meter {
+	eaiash.lock.Lock()
+	defer eaiash.lock.Unlock()
+
+	for len(eaiash.threadRates) > threads {
+		last := len(eaiash.threadRates) - 1
+		eaiash.threadRates[last].Stop()
+		eaiash.threadRates = eaiash.threadRates[:last]
+	}
+	for len(eaiash.threadRates) < threads {
+		eaiash.threadRates = append(eaiash.threadRates, metrics.NewMeter())
+	}
+	return append([]metrics.Meter{}, eaiash.threadRates...)
+}
+
 // APIs implements consensus.Engine, returning the user facing RPC APIs. Currently
 // that is empty.
 func (eaiash *Eaiash) APIs(chain consensus.ChainReader) []rpc.API {
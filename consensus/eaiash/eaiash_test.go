@@ -17,14 +17,20 @@
 package eaiash
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"math/big"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/ethereumai/go-ethereumai/common"
 	"github.com/ethereumai/go-ethereumai/core/types"
+	"github.com/ethereumai/go-ethereumai/metrics"
 )
 
 // Tests that eaiash works correctly in test mode.
@@ -43,6 +49,178 @@ func TestTestMode(t *testing.T) {
 	}
 }
 
+// Tests that the per-thread hashrate meters follow the thread count of the
+// seals, reusing the existing meters across restarts.
+func TestHashratePerThread(t *testing.T) {
+	// Nil meters are indistinguishable, track real ones
+	defer func(enabled bool) { metrics.Enabled = enabled }(metrics.Enabled)
+	metrics.Enabled = true
+
+	head := &types.Header{Number: big.NewInt(1), Difficulty: new(big.Int).Lsh(big.NewInt(1), 200)}
+
+	eaiash := New(Config{CachesInMem: 1, PowMode: ModeTest, SealDeadline: 50 * time.Millisecond})
+	eaiash.SetThreads(3)
+	eaiash.Seal(nil, types.NewBlockWithHeader(head), nil)
+
+	first := eaiash.threadRates[0]
+	first.Mark(1000)
+
+	for _, threads := range []int{1, 2} {
+		eaiash.SetThreads(threads)
+		eaiash.Seal(nil, types.NewBlockWithHeader(head), nil)
+
+		if rates := eaiash.HashratePerThread(); len(rates) != threads {
+			t.Errorf("%d threads: meter count mismatch: have %d", threads, len(rates))
+		}
+		if eaiash.threadRates[0] != first {
+			t.Errorf("%d threads: thread meter recreated on restart", threads)
+		}
+		if count := eaiash.threadRates[0].Count(); count < 1000 {
+			t.Errorf("%d threads: thread meter count lost: have %d, want at least %d", threads, count, 1000)
+		}
+	}
+}
+
+// Tests that sealing with a fixed nonce seed deterministically searches from the
+// same starting nonces.
+func TestNonceSeed(t *testing.T) {
+	// Any nonce satisfies a difficulty of one, so the first one tried is sealed
+	head := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1)}
+	seed := uint64(1337)
+
+	eaiash := New(Config{CachesInMem: 1, PowMode: ModeTest, NonceSeed: &seed})
+	eaiash.SetThreads(1)
+
+	want := uint64(rand.New(rand.NewSource(int64(seed))).Int63())
+	for i := 0; i < 2; i++ {
+		block, err := eaiash.Seal(nil, types.NewBlockWithHeader(head), nil)
+		if err != nil {
+			t.Fatalf("seal %d: failed to seal block: %v", i, err)
+		}
+		if block.Nonce() != want {
+			t.Errorf("seal %d: nonce mismatch: have %d, want %d", i, block.Nonce(), want)
+		}
+	}
+}
+
+// Tests that nonces found outside of the local search threads are accepted for
+// the block being sealed only if valid.
+func TestSubmitNonce(t *testing.T) {
+	head := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1 << 16)}
+	block := types.NewBlockWithHeader(head)
+
+	seed := uint64(1)
+	sealed, err := New(Config{CachesInMem: 1, PowMode: ModeTest, NonceSeed: &seed}).Seal(nil, block, nil)
+	if err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	// Seal without any local threads, relying on the submitted nonce
+	eaiash := NewTester()
+	eaiash.SetThreads(-1)
+
+	if eaiash.SubmitNonce(head.HashNoNonce(), sealed.Nonce()) {
+		t.Fatalf("nonce accepted without active work")
+	}
+	result := make(chan *types.Block)
+	go func() {
+		block, _ := eaiash.Seal(nil, block, nil)
+		result <- block
+	}()
+	for {
+		eaiash.lock.Lock()
+		active := eaiash.sealing != nil
+		eaiash.lock.Unlock()
+		if active {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if eaiash.SubmitNonce(common.Hash{0x01}, sealed.Nonce()) {
+		t.Errorf("nonce accepted for different work")
+	}
+	if eaiash.SubmitNonce(head.HashNoNonce(), sealed.Nonce()+1) {
+		t.Errorf("invalid nonce accepted")
+	}
+	if !eaiash.SubmitNonce(head.HashNoNonce(), sealed.Nonce()) {
+		t.Fatalf("valid nonce rejected")
+	}
+	if block := <-result; block.Nonce() != sealed.Nonce() || block.MixDigest() != sealed.MixDigest() {
+		t.Errorf("seal mismatch: have %x/%x, want %x/%x", block.Nonce(), block.MixDigest(), sealed.Nonce(), sealed.MixDigest())
+	}
+}
+
+// Tests that a sealing attempt is aborted once its deadline passes.
+func TestSealDeadline(t *testing.T) {
+	head := &types.Header{Number: big.NewInt(1), Difficulty: new(big.Int).Lsh(big.NewInt(1), 200)}
+
+	eaiash := New(Config{CachesInMem: 1, PowMode: ModeTest, SealDeadline: 100 * time.Millisecond})
+	eaiash.SetThreads(1)
+
+	start := time.Now()
+	block, err := eaiash.Seal(nil, types.NewBlockWithHeader(head), nil)
+	if err != ErrSealTimeout {
+		t.Fatalf("sealing error mismatch: have %v, want %v", err, ErrSealTimeout)
+	}
+	if block != nil {
+		t.Errorf("sealed block returned after timeout")
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("sealing aborted too early: %v", elapsed)
+	}
+}
+
+// Tests that sealing can be delegated to a remote sealer, with the work package
+// pushed to it in the format of eai_getWork and the nonce submitted back.
+func TestRemoteSealer(t *testing.T) {
+	head := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	block := types.NewBlockWithHeader(head)
+
+	sealed, err := NewTester().Seal(nil, block, nil)
+	if err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	works := make(chan [3]string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var work [3]string
+		json.NewDecoder(r.Body).Decode(&work)
+		works <- work
+	}))
+	defer server.Close()
+
+	eaiash := New(Config{CachesInMem: 1, PowMode: ModeTest, RemoteSealerURL: server.URL})
+	if !eaiash.RemoteSealing() {
+		t.Errorf("remote sealing not reported")
+	}
+	eaiash.SetThreads(1)
+	if threads := eaiash.Threads(); threads != -1 {
+		t.Errorf("local threads mismatch: have %d, want -1", threads)
+	}
+	results := make(chan *types.Block)
+	go func() {
+		result, err := eaiash.Seal(nil, block, nil)
+		if err != nil {
+			t.Errorf("failed to seal block remotely: %v", err)
+		}
+		results <- result
+	}()
+	select {
+	case work := <-works:
+		if want := sealWork(head); work != want {
+			t.Errorf("work package mismatch: have %v, want %v", work, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("work package not pushed to remote sealer")
+	}
+	// Deliver the seal the way eai_submitWork would
+	for !eaiash.SubmitNonce(head.HashNoNonce(), sealed.Nonce()) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	result := <-results
+	if result.Nonce() != sealed.Nonce() || result.MixDigest() != sealed.MixDigest() {
+		t.Errorf("seal mismatch: have %x/%x, want %x/%x", result.Nonce(), result.MixDigest(), sealed.Nonce(), sealed.MixDigest())
+	}
+}
+
 // This test checks that cache lru logic doesn't crash under load.
 // It reproduces https://github.com/ethereumai/go-ethereumai/issues/14943
 func TestCacheFileEvict(t *testing.T) {
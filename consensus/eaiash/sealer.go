@@ -17,19 +17,49 @@
 package eaiash
 
 import (
+	"bytes"
 	crand "crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"math/rand"
+	"net/http"
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/ethereumai/go-ethereumai/common"
 	"github.com/ethereumai/go-ethereumai/consensus"
 	"github.com/ethereumai/go-ethereumai/core/types"
 	"github.com/ethereumai/go-ethereumai/log"
+	"github.com/ethereumai/go-ethereumai/metrics"
 )
 
+const (
+	// remoteSealerTimeout is the maximum time a work notification to a remote
+	// sealer may take before it's abandoned.
+	remoteSealerTimeout = 5 * time.Second
+)
+
+var (
+	// ErrSealTimeout is returned by Seal if no nonce was found within the
+	// configured seal deadline.
+	ErrSealTimeout = errors.New("seal deadline exceeded")
+
+	// remoteSealerClient is the HTTP client used to talk to remote sealers.
+	remoteSealerClient = &http.Client{Timeout: remoteSealerTimeout}
+)
+
+// sealTask is a block being sealed by the local search threads, along with the
+// channels to deliver a seal found by someone else.
+type sealTask struct {
+	block *types.Block
+	found chan *types.Block
+	abort chan struct{}
+}
+
 // Seal implements consensus.Engine, attempting to find a nonce that satisfies
 // the block's difficulty requirements.
 func (eaiash *Eaiash) Seal(chain consensus.ChainReader, block *types.Block, stop <-chan struct{}) (*types.Block, error) {
@@ -43,13 +73,33 @@ func (eaiash *Eaiash) Seal(chain consensus.ChainReader, block *types.Block, stop
 	if eaiash.shared != nil {
 		return eaiash.shared.Seal(chain, block, stop)
 	}
+	// Give up on the attempt if it runs past the configured deadline
+	var deadline <-chan time.Time
+	if eaiash.config.SealDeadline > 0 {
+		timer := time.NewTimer(eaiash.config.SealDeadline)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	// If the nonce search is delegated to an external sealer, notify it of the new
+	// work. Its seal is submitted back through eai_submitWork, while the local
+	// search below runs without threads (remote sealing keeps them disabled).
+	if eaiash.config.RemoteSealerURL != "" {
+		go func(sealer string, work [3]string) {
+			if err := notifyRemoteSealer(sealer, work); err != nil {
+				log.Warn("Failed to notify remote sealer", "number", block.Number(), "err", err)
+			}
+		}(eaiash.config.RemoteSealerURL, sealWork(block.Header()))
+	}
 	// Create a runner and the multiple search threads it directs
 	abort := make(chan struct{})
 	found := make(chan *types.Block)
 
 	eaiash.lock.Lock()
 	threads := eaiash.threads
-	if eaiash.rand == nil {
+	if eaiash.config.NonceSeed != nil {
+		// Deterministic nonce search for tests, restarting the sequence every seal
+		eaiash.rand = rand.New(rand.NewSource(int64(*eaiash.config.NonceSeed)))
+	} else if eaiash.rand == nil {
 		seed, err := crand.Int(crand.Reader, big.NewInt(math.MaxInt64))
 		if err != nil {
 			eaiash.lock.Unlock()
@@ -64,12 +114,27 @@ func (eaiash *Eaiash) Seal(chain consensus.ChainReader, block *types.Block, stop
 	if threads < 0 {
 		threads = 0 // Allows disabling local mining without extra logic around local/remote
 	}
+	// Publish the work so externally found nonces can short-circuit the search
+	task := &sealTask{block: block, found: found, abort: abort}
+	eaiash.lock.Lock()
+	eaiash.sealing = task
+	eaiash.lock.Unlock()
+
+	defer func() {
+		eaiash.lock.Lock()
+		if eaiash.sealing == task {
+			eaiash.sealing = nil
+		}
+		eaiash.lock.Unlock()
+	}()
+	meters := eaiash.threadMeters(threads)
+
 	var pend sync.WaitGroup
 	for i := 0; i < threads; i++ {
 		pend.Add(1)
 		go func(id int, nonce uint64) {
 			defer pend.Done()
-			eaiash.mine(block, id, nonce, abort, found)
+			eaiash.mine(block, id, nonce, meters[id], abort, found)
 		}(i, uint64(eaiash.rand.Int63()))
 	}
 	// Wait until sealing is terminated or a nonce is found
@@ -81,8 +146,13 @@ func (eaiash *Eaiash) Seal(chain consensus.ChainReader, block *types.Block, stop
 	case result = <-found:
 		// One of the threads found a block, abort all others
 		close(abort)
+	case <-deadline:
+		// No nonce found in time, stop all miner threads
+		close(abort)
+		pend.Wait()
+		return nil, ErrSealTimeout
 	case <-eaiash.update:
-		// Thread count was changed on user request, restart
+		// Thread count was changed on user request, restart with a fresh deadline
 		close(abort)
 		pend.Wait()
 		return eaiash.Seal(chain, block, stop)
@@ -92,9 +162,61 @@ func (eaiash *Eaiash) Seal(chain consensus.ChainReader, block *types.Block, stop
 	return result, nil
 }
 
+// sealWork returns the work package of a header in the format of eai_getWork:
+// the pow hash, the seed hash and the boundary condition to meet.
+func sealWork(header *types.Header) [3]string {
+	return [3]string{
+		header.HashNoNonce().Hex(),
+		common.BytesToHash(SeedHash(header.Number.Uint64())).Hex(),
+		common.BytesToHash(new(big.Int).Div(maxUint256, header.Difficulty).Bytes()).Hex(),
+	}
+}
+
+// SubmitNonce delivers a nonce found outside of the local search threads, e.g.
+// by a remote miner, for the block currently being sealed. If the nonce is valid
+// for the block with the given seal hash, the local search is short-circuited.
+func (eaiash *Eaiash) SubmitNonce(hash common.Hash, nonce uint64) bool {
+	// If we're running a shared PoW, submit the nonce to it
+	if eaiash.shared != nil {
+		return eaiash.shared.SubmitNonce(hash, nonce)
+	}
+	eaiash.lock.Lock()
+	task := eaiash.sealing
+	eaiash.lock.Unlock()
+
+	if task == nil || task.block.HashNoNonce() != hash {
+		return false
+	}
+	// Verify the nonce against the block's difficulty before accepting it
+	header := task.block.Header()
+	number := header.Number.Uint64()
+
+	cache := eaiash.cache(number)
+	size := datasetSize(number)
+	if eaiash.config.PowMode == ModeTest {
+		size = 32 * 1024
+	}
+	digest, result := hashimotoLight(size, cache.cache, hash.Bytes(), nonce)
+	runtime.KeepAlive(cache)
+
+	if new(big.Int).SetBytes(result).Cmp(new(big.Int).Div(maxUint256, header.Difficulty)) > 0 {
+		return false
+	}
+	header.Nonce = types.EncodeNonce(nonce)
+	header.MixDigest = common.BytesToHash(digest)
+
+	select {
+	case task.found <- task.block.WithSeal(header):
+		return true
+	case <-task.abort:
+		return false
+	}
+}
+
 // mine is the actual proof-of-work miner that searches for a nonce starting from
-// seed that results in correct final block difficulty.
-func (eaiash *Eaiash) mine(block *types.Block, id int, seed uint64, abort chan struct{}, found chan *types.Block) {
+// seed that results in correct final block difficulty. The attempts are marked
+// both on the aggregate hashrate and on the thread's own meter.
+func (eaiash *Eaiash) mine(block *types.Block, id int, seed uint64, meter metrics.Meter, abort chan struct{}, found chan *types.Block) {
 	// Extract some data from the header
 	var (
 		header  = block.Header()
@@ -117,6 +239,7 @@ search:
 			// Mining terminated, update stats and abort
 			logger.Trace("Eaiash nonce search aborted", "attempts", nonce-seed)
 			eaiash.hashrate.Mark(attempts)
+			meter.Mark(attempts)
 			break search
 
 		default:
@@ -124,6 +247,7 @@ search:
 			attempts++
 			if (attempts % (1 << 15)) == 0 {
 				eaiash.hashrate.Mark(attempts)
+				meter.Mark(attempts)
 				attempts = 0
 			}
 			// Compute the PoW value of this nonce
@@ -150,3 +274,22 @@ search:
 	// during sealing so it's not unmapped while being read.
 	runtime.KeepAlive(dataset)
 }
+
+// notifyRemoteSealer POSTs a work package to a remote sealer, in the same format
+// as returned by eai_getWork, so it can start searching for a nonce right away.
+func notifyRemoteSealer(sealer string, work [3]string) error {
+	blob, err := json.Marshal(work)
+	if err != nil {
+		return err
+	}
+	res, err := remoteSealerClient.Post(sealer, "application/json", bytes.NewReader(blob))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("remote sealer rejected work: %s", res.Status)
+	}
+	return nil
+}
@@ -60,8 +60,15 @@ const (
 	badBlockLimit       = 10
 	triesInMemory       = 128
 
+	txCountRetryInterval = 10 * time.Second // Delay before an interrupted tx count backfill is resumed
+
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	BlockChainVersion = 3
+
+	// MaxFutureBlockTime is the furthest a block's timestamp may be ahead of the
+	// local clock for the block to be queued for a later import instead of being
+	// rejected outright.
+	MaxFutureBlockTime = maxTimeFutureBlocks * time.Second
 )
 
 // CacheConfig contains the configuration values for the trie caching/pruning
@@ -100,6 +107,7 @@ type BlockChain struct {
 	chainSideFeed event.Feed
 	chainHeadFeed event.Feed
 	logsFeed      event.Feed
+	reorgFeed     event.Feed
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
 
@@ -192,6 +200,13 @@ func NewBlockChain(db eaidb.Database, cacheConfig *CacheConfig, chainConfig *par
 	}
 	// Take ownership of this particular state
 	go bc.update()
+
+	// Backfill transaction counts of blocks imported before they were tracked
+	bc.wg.Add(1)
+	go func() {
+		defer bc.wg.Done()
+		bc.indexTxCounts()
+	}()
 	return bc, nil
 }
 
@@ -331,6 +346,35 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	return nil
 }
 
+// CommitSnapshotHead sets the head header, fast block and full block to the one
+// defined by the hash, irrelevant of any missing ancestors. The block, its total
+// difficulty and its state must have been written to the database beforehand,
+// as when bootstrapping a node from a trusted chain snapshot.
+func (bc *BlockChain) CommitSnapshotHead(hash common.Hash) error {
+	block := bc.GetBlockByHash(hash)
+	if block == nil {
+		return fmt.Errorf("non existent block [%x…]", hash[:4])
+	}
+	if bc.GetTd(hash, block.NumberU64()) == nil {
+		return fmt.Errorf("unknown total difficulty of block [%x…]", hash[:4])
+	}
+	if _, err := trie.NewSecure(block.Root(), bc.stateCache.TrieDB(), 0); err != nil {
+		return err
+	}
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	rawdb.WriteHeadBlockHash(bc.db, hash)
+	rawdb.WriteHeadFastBlockHash(bc.db, hash)
+	bc.hc.SetCurrentHeader(block.Header())
+
+	bc.currentBlock.Store(block)
+	bc.currentFastBlock.Store(block)
+
+	log.Info("Committed snapshot head block", "number", block.Number(), "hash", hash)
+	return nil
+}
+
 // GasLimit returns the gas limit of the current HEAD block.
 func (bc *BlockChain) GasLimit() uint64 {
 	return bc.CurrentBlock().GasLimit()
@@ -386,6 +430,34 @@ func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
 	return state.New(root, bc.stateCache)
 }
 
+// PinState keeps the state trie of the given root from being garbage collected
+// until the returned release function is called, allowing long running readers
+// (e.g. state exports) to iterate it without blocking block imports.
+func (bc *BlockChain) PinState(root common.Hash) (func(), error) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if _, err := bc.stateCache.OpenTrie(root); err != nil {
+		return nil, err
+	}
+	// Tries already flushed to disk are never collected, only pin in-memory ones
+	if ok, _ := bc.db.Has(root[:]); ok {
+		return func() {}, nil
+	}
+	triedb := bc.stateCache.TrieDB()
+	triedb.Reference(root, common.Hash{})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			bc.mu.Lock()
+			defer bc.mu.Unlock()
+
+			triedb.Dereference(root, common.Hash{})
+		})
+	}, nil
+}
+
 // Reset purges the entire blockchain, restoring it to its genesis state.
 func (bc *BlockChain) Reset() error {
 	return bc.ResetWithGenesisBlock(bc.genesisBlock)
@@ -406,6 +478,7 @@ func (bc *BlockChain) ResetWithGenesisBlock(genesis *types.Block) error {
 		log.Crit("Failed to write genesis block TD", "err", err)
 	}
 	rawdb.WriteBlock(bc.db, genesis)
+	rawdb.WriteTxCount(bc.db, genesis.Hash(), genesis.NumberU64(), uint64(len(genesis.Transactions())))
 
 	bc.genesisBlock = genesis
 	bc.insert(bc.genesisBlock)
@@ -682,15 +755,7 @@ func (bc *BlockChain) Stop() {
 }
 
 func (bc *BlockChain) procFutureBlocks() {
-	blocks := make([]*types.Block, 0, bc.futureBlocks.Len())
-	for _, hash := range bc.futureBlocks.Keys() {
-		if block, exist := bc.futureBlocks.Peek(hash); exist {
-			blocks = append(blocks, block.(*types.Block))
-		}
-	}
-	if len(blocks) > 0 {
-		types.BlockBy(types.Number).Sort(blocks)
-
+	if blocks := bc.FutureBlocks(); len(blocks) > 0 {
 		// Insert one by one as chain insertion needs contiguous ancestry between blocks
 		for i := range blocks {
 			bc.InsertChain(blocks[i : i+1])
@@ -792,7 +857,13 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		start = time.Now()
 		bytes = 0
 		batch = bc.db.NewBatch()
+
+		txCount   uint64
+		txCounted bool
 	)
+	if len(blockChain) > 0 && blockChain[0].NumberU64() > 0 {
+		txCount, txCounted = rawdb.ReadTxCount(bc.db, blockChain[0].ParentHash(), blockChain[0].NumberU64()-1)
+	}
 	for i, block := range blockChain {
 		receipts := receiptChain[i]
 		// Short circuit insertion if shutting down or processing failed
@@ -805,6 +876,7 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		}
 		// Skip if the entire data is already known
 		if bc.HasBlock(block.Hash(), block.NumberU64()) {
+			txCount, txCounted = rawdb.ReadTxCount(bc.db, block.Hash(), block.NumberU64())
 			stats.ignored++
 			continue
 		}
@@ -816,7 +888,10 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 		rawdb.WriteBody(batch, block.Hash(), block.NumberU64(), block.Body())
 		rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
 		rawdb.WriteTxLookupEntries(batch, block)
-
+		if txCounted {
+			txCount += uint64(len(block.Transactions()))
+			rawdb.WriteTxCount(batch, block.Hash(), block.NumberU64(), txCount)
+		}
 		stats.processed++
 
 		if batch.ValueSize() >= eaidb.IdealBatchSize {
@@ -869,6 +944,7 @@ func (bc *BlockChain) WriteBlockWithoutState(block *types.Block, td *big.Int) (e
 		return err
 	}
 	rawdb.WriteBlock(bc.db, block)
+	bc.writeTxCount(bc.db, block)
 
 	return nil
 }
@@ -898,6 +974,7 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	// Write other block data using a batch.
 	batch := bc.db.NewBatch()
 	rawdb.WriteBlock(batch, block)
+	bc.writeTxCount(batch, block)
 
 	root, err := state.Commit(bc.chainConfig.IsEIP158(block.Number()))
 	if err != nil {
@@ -1347,6 +1424,14 @@ func (bc *BlockChain) reorg(oldBlock, newBlock *types.Block) error {
 				bc.chainSideFeed.Send(ChainSideEvent{Block: block})
 			}
 		}()
+		ev := ReorgEvent{Ancestor: commonBlock.Hash()}
+		for i := len(oldChain) - 1; i >= 0; i-- {
+			ev.Old = append(ev.Old, oldChain[i])
+		}
+		for i := len(newChain) - 1; i >= 0; i-- {
+			ev.New = append(ev.New, newChain[i])
+		}
+		go bc.reorgFeed.Send(ev)
 	}
 
 	return nil
@@ -1489,6 +1574,156 @@ func (bc *BlockChain) CurrentHeader() *types.Header {
 	return bc.hc.CurrentHeader()
 }
 
+// FutureBlockCount returns the number of blocks currently queued for import
+// because their timestamps are ahead of the local clock.
+func (bc *BlockChain) FutureBlockCount() int {
+	return bc.futureBlocks.Len()
+}
+
+// FutureBlocks returns the blocks currently queued for import because their
+// timestamps are ahead of the local clock, ordered by number.
+func (bc *BlockChain) FutureBlocks() []*types.Block {
+	blocks := make([]*types.Block, 0, bc.futureBlocks.Len())
+	for _, hash := range bc.futureBlocks.Keys() {
+		if block, exist := bc.futureBlocks.Peek(hash); exist {
+			blocks = append(blocks, block.(*types.Block))
+		}
+	}
+	types.BlockBy(types.Number).Sort(blocks)
+	return blocks
+}
+
+// writeTxCount stores the cumulative transaction count of a block, provided the
+// count of its parent is known. Counts are keyed by block hash, so side forks and
+// reorgs are accounted for without any rewinding.
+func (bc *BlockChain) writeTxCount(db rawdb.DatabaseWriter, block *types.Block) {
+	if count, ok := rawdb.ReadTxCount(bc.db, block.ParentHash(), block.NumberU64()-1); ok {
+		rawdb.WriteTxCount(db, block.Hash(), block.NumberU64(), count+uint64(len(block.Transactions())))
+	}
+}
+
+// GetTxCount retrieves the cumulative number of transactions in the chain up to
+// and including the given block. Blocks imported before the count was tracked
+// are backfilled by a background indexer and report an error until then.
+func (bc *BlockChain) GetTxCount(hash common.Hash, number uint64) (uint64, error) {
+	if count, ok := rawdb.ReadTxCount(bc.db, hash, number); ok {
+		return count, nil
+	}
+	return 0, fmt.Errorf("transaction count of block #%d [%x…] not yet indexed", number, hash[:4])
+}
+
+// indexTxCounts backfills the cumulative transaction counts of canonical blocks
+// imported before the counts were tracked. Passes interrupted by a reorg or a
+// missing block body are resumed periodically until the indexer catches up with
+// the chain head or the chain is stopped.
+func (bc *BlockChain) indexTxCounts() {
+	head := bc.CurrentBlock()
+	if _, ok := rawdb.ReadTxCount(bc.db, head.Hash(), head.NumberU64()); ok {
+		return
+	}
+	var (
+		next uint64
+		done bool
+	)
+	for {
+		if next, done = bc.backfillTxCounts(next); done {
+			return
+		}
+		select {
+		case <-bc.quit:
+			return
+		case <-time.After(txCountRetryInterval):
+		}
+	}
+}
+
+// backfillTxCounts indexes the transaction counts of the canonical blocks from
+// the given number upwards, first stepping back over any blocks a reorg replaced
+// since the last pass. It returns the number to resume from and whether the
+// chain head has been reached.
+func (bc *BlockChain) backfillTxCounts(from uint64) (uint64, bool) {
+	for from > 0 {
+		if _, ok := rawdb.ReadTxCount(bc.db, rawdb.ReadCanonicalHash(bc.db, from-1), from-1); ok {
+			break
+		}
+		from--
+	}
+	var (
+		batch   = bc.db.NewBatch()
+		parent  common.Hash
+		count   uint64
+		indexed int
+		start   = time.Now()
+	)
+	flush := func() bool {
+		if err := batch.Write(); err != nil {
+			log.Error("Failed to write transaction counts", "err", err)
+			return false
+		}
+		batch.Reset()
+		return true
+	}
+	defer func() {
+		if indexed > 0 {
+			log.Info("Indexed transaction counts", "blocks", indexed, "elapsed", common.PrettyDuration(time.Since(start)))
+		}
+	}()
+	number := from
+	for ; number <= bc.CurrentBlock().NumberU64(); number++ {
+		select {
+		case <-bc.quit:
+			flush()
+			return number, false
+		default:
+		}
+		hash := rawdb.ReadCanonicalHash(bc.db, number)
+		if hash == (common.Hash{}) {
+			break
+		}
+		if known, ok := rawdb.ReadTxCount(bc.db, hash, number); ok {
+			parent, count = hash, known
+			continue
+		}
+		if number > 0 {
+			header := bc.GetHeader(hash, number)
+			if header == nil {
+				break
+			}
+			// The canonical chain was reorged beneath the indexer, resume from the new parent
+			if header.ParentHash != parent {
+				if !flush() {
+					return number, false
+				}
+				known, ok := rawdb.ReadTxCount(bc.db, header.ParentHash, number-1)
+				if !ok {
+					log.Debug("Transaction count indexing interrupted by reorg", "number", number)
+					return number, false
+				}
+				count = known
+			}
+		}
+		body := rawdb.ReadBody(bc.db, hash, number)
+		if body == nil {
+			log.Debug("Block body unavailable for transaction count indexing", "number", number, "hash", hash)
+			break
+		}
+		count += uint64(len(body.Transactions))
+		rawdb.WriteTxCount(batch, hash, number, count)
+		parent = hash
+		indexed++
+
+		if batch.ValueSize() >= eaidb.IdealBatchSize && !flush() {
+			return number, false
+		}
+	}
+	if !flush() {
+		return from, false
+	}
+	head := bc.CurrentBlock()
+	_, ok := rawdb.ReadTxCount(bc.db, head.Hash(), head.NumberU64())
+	return number, ok
+}
+
 // GetTd retrieves a block's total difficulty in the canonical chain from the
 // database by hash and number, caching it if found.
 func (bc *BlockChain) GetTd(hash common.Hash, number uint64) *big.Int {
@@ -1557,6 +1792,11 @@ func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Su
 	return bc.scope.Track(bc.chainSideFeed.Subscribe(ch))
 }
 
+// SubscribeReorgEvent registers a subscription of ReorgEvent.
+func (bc *BlockChain) SubscribeReorgEvent(ch chan<- ReorgEvent) event.Subscription {
+	return bc.scope.Track(bc.reorgFeed.Subscribe(ch))
+}
+
 // SubscribeLogsEvent registers a subscription of []*types.Log.
 func (bc *BlockChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return bc.scope.Track(bc.logsFeed.Subscribe(ch))
@@ -979,6 +979,51 @@ done:
 
 }
 
+// Tests that a reorg is reported as a single event containing both the dropped
+// and the added blocks, along with their common ancestor.
+func TestReorgEvent(t *testing.T) {
+	var (
+		db      = eaidb.NewMemDatabase()
+		gspec   = &Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+	)
+	blockchain, _ := NewBlockChain(db, nil, gspec.Config, eaiash.NewFaker(), vm.Config{})
+	defer blockchain.Stop()
+
+	chain, _ := GenerateChain(gspec.Config, genesis, eaiash.NewFaker(), db, 3, func(i int, gen *BlockGen) {})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Create a fork of the same length, made heavier by a faster third block
+	fork, _ := GenerateChain(gspec.Config, chain[0], eaiash.NewFaker(), db, 2, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(common.Address{0x01})
+		if i == 1 {
+			gen.OffsetTime(-9)
+		}
+	})
+	reorgCh := make(chan ReorgEvent, 1)
+	sub := blockchain.SubscribeReorgEvent(reorgCh)
+	defer sub.Unsubscribe()
+
+	if _, err := blockchain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	select {
+	case ev := <-reorgCh:
+		if ev.Ancestor != chain[0].Hash() {
+			t.Errorf("ancestor mismatch: have %x, want %x", ev.Ancestor, chain[0].Hash())
+		}
+		if len(ev.Old) != 2 || ev.Old[0].Hash() != chain[1].Hash() || ev.Old[1].Hash() != chain[2].Hash() {
+			t.Errorf("dropped blocks mismatch: %v", ev.Old)
+		}
+		if len(ev.New) != 2 || ev.New[0].Hash() != fork[0].Hash() || ev.New[1].Hash() != fork[1].Hash() {
+			t.Errorf("added blocks mismatch: %v", ev.New)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("reorg event not fired")
+	}
+}
+
 // Tests if the canonical block can be fetched from the database during chain insertion.
 func TestCanonicalBlockRetrieval(t *testing.T) {
 	_, blockchain, err := newCanonical(eaiash.NewFaker(), 0, true)
@@ -1450,3 +1495,137 @@ func BenchmarkBlockChain_1x1000Executions(b *testing.B) {
 
 	benchmarkLargeNumberOfValueToNonexisting(b, numTxs, numBlocks, recipientFn, dataFn)
 }
+
+// Tests that cumulative transaction counts follow the block they were computed
+// for across reorgs, and that missing counts are backfilled by the indexer.
+func TestTxCountTracking(t *testing.T) {
+	var (
+		db      = eaidb.NewMemDatabase()
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(1000000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainId)
+	)
+	generate := func(n, txs int, seed byte) []*types.Block {
+		blocks, _ := GenerateChain(gspec.Config, genesis, eaiash.NewFaker(), db, n, func(i int, block *BlockGen) {
+			block.SetCoinbase(common.Address{seed})
+			for j := 0; j < txs; j++ {
+				tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{seed}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+				if err != nil {
+					panic(err)
+				}
+				block.AddTx(tx)
+			}
+		})
+		return blocks
+	}
+	chain, _ := NewBlockChain(db, nil, gspec.Config, eaiash.NewFaker(), vm.Config{})
+	defer chain.Stop()
+
+	first, second := generate(3, 1, 0x01), generate(4, 2, 0x02)
+	if _, err := chain.InsertChain(first); err != nil {
+		t.Fatalf("failed to insert first chain: %v", err)
+	}
+	if _, err := chain.InsertChain(second); err != nil {
+		t.Fatalf("failed to insert second chain: %v", err)
+	}
+	if head := chain.CurrentBlock().Hash(); head != second[len(second)-1].Hash() {
+		t.Fatalf("reorg not performed: head %x", head)
+	}
+	check := func(block *types.Block, want uint64) {
+		if have, err := chain.GetTxCount(block.Hash(), block.NumberU64()); err != nil {
+			t.Fatalf("block #%d: failed to retrieve tx count: %v", block.NumberU64(), err)
+		} else if have != want {
+			t.Errorf("block #%d: tx count mismatch: have %d, want %d", block.NumberU64(), have, want)
+		}
+	}
+	check(first[2], 3)
+	check(second[3], 8)
+
+	// Drop the counts of an imported stretch and ensure lookups fail until indexed
+	for _, block := range second {
+		rawdb.DeleteTxCount(db, block.Hash(), block.NumberU64())
+	}
+	if _, err := chain.GetTxCount(second[3].Hash(), second[3].NumberU64()); err == nil {
+		t.Fatalf("missing tx count reported without error")
+	}
+	chain.indexTxCounts()
+
+	check(second[1], 4)
+	check(second[3], 8)
+}
+
+// Tests that an interrupted transaction count backfill resumes where it stopped,
+// stepping back over blocks replaced by a reorg in the meantime.
+func TestTxCountBackfillResume(t *testing.T) {
+	var (
+		db      = eaidb.NewMemDatabase()
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(1000000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainId)
+	)
+	generate := func(n, txs int, seed byte) []*types.Block {
+		blocks, _ := GenerateChain(gspec.Config, genesis, eaiash.NewFaker(), db, n, func(i int, block *BlockGen) {
+			block.SetCoinbase(common.Address{seed})
+			for j := 0; j < txs; j++ {
+				tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{seed}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+				if err != nil {
+					panic(err)
+				}
+				block.AddTx(tx)
+			}
+		})
+		return blocks
+	}
+	chain, err := NewBlockChain(db, nil, gspec.Config, eaiash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer chain.Stop()
+
+	first, second := generate(6, 1, 0x01), generate(8, 2, 0x02)
+	if _, err := chain.InsertChain(first); err != nil {
+		t.Fatalf("failed to insert first chain: %v", err)
+	}
+	// Drop the counts as if imported before tracking, and hide a body midway
+	for _, block := range first {
+		rawdb.DeleteTxCount(db, block.Hash(), block.NumberU64())
+	}
+	rawdb.DeleteBody(db, first[4].Hash(), first[4].NumberU64())
+
+	next, done := chain.backfillTxCounts(0)
+	if done || next != first[4].NumberU64() {
+		t.Fatalf("interrupted backfill mismatch: have %d, %v, want %d, false", next, done, first[4].NumberU64())
+	}
+	if have, err := chain.GetTxCount(first[3].Hash(), first[3].NumberU64()); err != nil || have != 4 {
+		t.Errorf("count before interruption mismatch: have %d, %v, want 4", have, err)
+	}
+	if _, err := chain.GetTxCount(first[4].Hash(), first[4].NumberU64()); err == nil {
+		t.Errorf("block with missing body indexed")
+	}
+	// Reorg the indexed stretch away, again without counts, and resume
+	rawdb.WriteBody(db, first[4].Hash(), first[4].NumberU64(), first[4].Body())
+	if _, err := chain.InsertChain(second); err != nil {
+		t.Fatalf("failed to insert second chain: %v", err)
+	}
+	for _, block := range second {
+		rawdb.DeleteTxCount(db, block.Hash(), block.NumberU64())
+	}
+	if next, done = chain.backfillTxCounts(next); !done {
+		t.Fatalf("resumed backfill incomplete at block %d", next)
+	}
+	for i, block := range second {
+		if have, err := chain.GetTxCount(block.Hash(), block.NumberU64()); err != nil || have != uint64(2*(i+1)) {
+			t.Errorf("block #%d: count mismatch: have %d, %v, want %d", block.NumberU64(), have, err, 2*(i+1))
+		}
+	}
+}
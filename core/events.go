@@ -52,3 +52,11 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// ReorgEvent is posted once a reorg completed, reporting both the dropped and the
+// newly canonical blocks above the common ancestor, each ordered by number.
+type ReorgEvent struct {
+	Ancestor common.Hash    // Hash of the common ancestor of the two chains
+	Old      []*types.Block // Blocks removed from the canonical chain
+	New      []*types.Block // Blocks added to the canonical chain
+}
@@ -268,6 +268,7 @@ func (g *Genesis) Commit(db eaidb.Database) (*types.Block, error) {
 	}
 	rawdb.WriteTd(db, block.Hash(), block.NumberU64(), g.Difficulty)
 	rawdb.WriteBlock(db, block)
+	rawdb.WriteTxCount(db, block.Hash(), block.NumberU64(), uint64(len(block.Transactions())))
 	rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), nil)
 	rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
 	rawdb.WriteHeadBlockHash(db, block.Hash())
@@ -0,0 +1,173 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/core/state"
+	"github.com/ethereumai/go-ethereumai/crypto"
+	"github.com/ethereumai/go-ethereumai/log"
+	"github.com/ethereumai/go-ethereumai/rlp"
+	"github.com/ethereumai/go-ethereumai/trie"
+)
+
+// errPruneNotArchive is returned if storage history pruning is requested on a
+// node that garbage collects its state tries.
+var errPruneNotArchive = errors.New("storage history pruning requires an archive node")
+
+// PruneStorageHistory deletes the storage trie nodes of an account that are only
+// reachable from canonical states before the given block, returning the number
+// of nodes deleted.
+//
+// Trie nodes are content addressed and shared freely between accounts and blocks,
+// so a node is only deleted if no retained state references it: every canonical
+// state is walked and every node reachable from anything other than the account's
+// own pruned storage is kept. Side chain states are not inspected, nodes only they
+// reference may be deleted. Block imports are blocked until the sweep finishes,
+// which may take a long time on a long chain; the set of reachable nodes is also
+// held in memory meanwhile.
+func (bc *BlockChain) PruneStorageHistory(addr common.Address, before uint64) (int, error) {
+	if !bc.cacheConfig.Disabled {
+		return 0, errPruneNotArchive
+	}
+	// New states may reference the very nodes being swept, hold off imports
+	bc.chainmu.Lock()
+	defer bc.chainmu.Unlock()
+
+	head := bc.CurrentBlock().NumberU64()
+	if before > head {
+		return 0, fmt.Errorf("block %d beyond current head %d", before, head)
+	}
+	var (
+		key    = crypto.Keccak256(addr[:])
+		triedb = bc.stateCache.TrieDB()
+		marked = make(map[common.Hash]struct{})
+		stale  = make(map[common.Hash]struct{})
+	)
+	// Mark the retained states first: subtrees are only walked once, so the ones
+	// shared with pruned states must not be reached with the account skipped.
+	for number := before; number <= head; number++ {
+		if err := bc.markState(triedb, number, key, marked, nil); err != nil {
+			return 0, err
+		}
+	}
+	// Mark the pruned states too, collecting the account's storage roots instead
+	for number := uint64(0); number < before; number++ {
+		if err := bc.markState(triedb, number, key, marked, stale); err != nil {
+			return 0, err
+		}
+	}
+	// Sweep the unmarked nodes of the account's pruned storage tries
+	sweep := make(map[common.Hash]struct{})
+	for root := range stale {
+		storage, err := trie.New(root, triedb)
+		if err != nil {
+			continue // Removed by an earlier run
+		}
+		it := storage.NodeIterator(nil)
+		for descend := true; it.Next(descend); {
+			descend = true
+
+			hash := it.Hash()
+			if hash == (common.Hash{}) {
+				continue
+			}
+			if _, ok := marked[hash]; ok {
+				descend = false
+				continue
+			}
+			sweep[hash] = struct{}{}
+		}
+		if err := it.Error(); err != nil {
+			if _, ok := err.(*trie.MissingNodeError); !ok {
+				return 0, err
+			}
+		}
+	}
+	for hash := range sweep {
+		if err := bc.db.Delete(hash[:]); err != nil {
+			return 0, err
+		}
+	}
+	log.Info("Pruned account storage history", "address", addr, "before", before, "nodes", len(sweep))
+	return len(sweep), nil
+}
+
+// markState marks all the nodes reachable from the canonical state at the given
+// block, including storage tries and contract code. If stale is non-nil, the
+// storage trie of the account with the given hashed key is not walked, its root
+// is added to stale instead. States missing from the database are skipped.
+func (bc *BlockChain) markState(triedb *trie.Database, number uint64, key []byte, marked, stale map[common.Hash]struct{}) error {
+	header := bc.GetHeaderByNumber(number)
+	if header == nil {
+		return fmt.Errorf("block #%d not found", number)
+	}
+	accounts, err := trie.New(header.Root, triedb)
+	if err != nil {
+		return nil
+	}
+	return markNodes(accounts.NodeIterator(nil), marked, func(it trie.NodeIterator) error {
+		var account state.Account
+		if err := rlp.DecodeBytes(it.LeafBlob(), &account); err != nil {
+			return err
+		}
+		// Contract code lives in the same key space as trie nodes
+		marked[common.BytesToHash(account.CodeHash)] = struct{}{}
+
+		if stale != nil && bytes.Equal(it.LeafKey(), key) {
+			stale[account.Root] = struct{}{}
+			return nil
+		}
+		storage, err := trie.New(account.Root, triedb)
+		if err != nil {
+			return nil
+		}
+		return markNodes(storage.NodeIterator(nil), marked, nil)
+	})
+}
+
+// markNodes marks the hashes of all the nodes reachable from an iterator, calling
+// onLeaf for every leaf. Subtrees already marked are not descended into again.
+// Missing nodes are tolerated, they can only belong to storage deleted earlier.
+func markNodes(it trie.NodeIterator, marked map[common.Hash]struct{}, onLeaf func(trie.NodeIterator) error) error {
+	for descend := true; it.Next(descend); {
+		descend = true
+
+		if hash := it.Hash(); hash != (common.Hash{}) {
+			if _, ok := marked[hash]; ok {
+				descend = false
+				continue
+			}
+			marked[hash] = struct{}{}
+		}
+		if it.Leaf() && onLeaf != nil {
+			if err := onLeaf(it); err != nil {
+				return err
+			}
+		}
+	}
+	if err := it.Error(); err != nil {
+		if _, ok := err.(*trie.MissingNodeError); !ok {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/consensus/eaiash"
+	"github.com/ethereumai/go-ethereumai/core/state"
+	"github.com/ethereumai/go-ethereumai/core/types"
+	"github.com/ethereumai/go-ethereumai/core/vm"
+	"github.com/ethereumai/go-ethereumai/crypto"
+	"github.com/ethereumai/go-ethereumai/eaidb"
+	"github.com/ethereumai/go-ethereumai/params"
+	"github.com/ethereumai/go-ethereumai/rlp"
+	"github.com/ethereumai/go-ethereumai/trie"
+)
+
+// Tests that pruning the storage history of an account drops the storage nodes
+// only its older states reference, while keeping retained states, other accounts
+// and nodes shared with identical storage of other accounts intact.
+func TestPruneStorageHistory(t *testing.T) {
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		pruned  = common.HexToAddress("0xc0de")
+		clone   = common.HexToAddress("0xc1de")
+		storage = map[common.Hash]common.Hash{common.HexToHash("0x01"): common.HexToHash("0xff")}
+		db      = eaidb.NewMemDatabase()
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				address: {Balance: big.NewInt(1000000)},
+				pruned:  {Balance: new(big.Int), Code: common.FromHex("0x43600055"), Storage: storage}, // NUMBER PUSH1 0 SSTORE
+				clone:   {Balance: new(big.Int), Storage: storage},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.HomesteadSigner{}
+	)
+	blockchain, err := NewBlockChain(db, &CacheConfig{Disabled: true}, gspec.Config, eaiash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	chain, _ := GenerateChain(gspec.Config, genesis, eaiash.NewFaker(), db, 5, func(i int, block *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(address), pruned, new(big.Int), 50000, big.NewInt(1), nil), signer, key)
+		block.AddTx(tx)
+	})
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if _, err := blockchain.PruneStorageHistory(pruned, 6); err == nil {
+		t.Errorf("pruning beyond the head succeeded")
+	}
+	deleted, err := blockchain.PruneStorageHistory(pruned, 3)
+	if err != nil {
+		t.Fatalf("failed to prune storage history: %v", err)
+	}
+	if deleted == 0 {
+		t.Errorf("no storage nodes pruned")
+	}
+	// Storage of pruned states must be gone, unless shared with the clone
+	for number := uint64(0); number <= 5; number++ {
+		root := blockchain.GetHeaderByNumber(number).Root
+
+		statedb, err := state.New(root, state.NewDatabase(db))
+		if err != nil {
+			t.Fatalf("block %d: failed to open state: %v", number, err)
+		}
+		if balance := statedb.GetBalance(address); balance.Sign() == 0 {
+			t.Errorf("block %d: sender balance lost", number)
+		}
+		if len(statedb.GetCode(pruned)) == 0 {
+			t.Errorf("block %d: contract code lost", number)
+		}
+		if err := iterateStorage(db, root, clone); err != nil {
+			t.Errorf("block %d: clone storage lost: %v", number, err)
+		}
+		err = iterateStorage(db, root, pruned)
+		switch {
+		case number == 0 && err != nil:
+			t.Errorf("block %d: storage shared with clone lost: %v", number, err)
+		case number > 0 && number < 3 && err == nil:
+			t.Errorf("block %d: pruned storage still accessible", number)
+		case number >= 3 && err != nil:
+			t.Errorf("block %d: retained storage lost: %v", number, err)
+		}
+	}
+	// Pruning again must be a no-op, while non archive nodes must refuse
+	if deleted, err := blockchain.PruneStorageHistory(pruned, 3); err != nil || deleted != 0 {
+		t.Errorf("repeated pruning mismatch: have %d, %v, want 0, nil", deleted, err)
+	}
+	blockchain.cacheConfig.Disabled = false
+	if _, err := blockchain.PruneStorageHistory(pruned, 3); err != errPruneNotArchive {
+		t.Errorf("error mismatch on non archive node: have %v, want %v", err, errPruneNotArchive)
+	}
+}
+
+// iterateStorage walks the entire storage trie of an account within the state
+// with the given root, failing if any of its nodes are missing.
+func iterateStorage(db eaidb.Database, root common.Hash, addr common.Address) error {
+	triedb := trie.NewDatabase(db)
+	accounts, err := trie.NewSecure(root, triedb, 0)
+	if err != nil {
+		return err
+	}
+	enc, err := accounts.TryGet(addr[:])
+	if err != nil {
+		return err
+	}
+	var account state.Account
+	if err := rlp.DecodeBytes(enc, &account); err != nil {
+		return err
+	}
+	storage, err := trie.New(account.Root, triedb)
+	if err != nil {
+		return err
+	}
+	it := storage.NodeIterator(nil)
+	for it.Next(true) {
+	}
+	return it.Error()
+}
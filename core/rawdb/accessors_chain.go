@@ -276,6 +276,31 @@ func DeleteTd(db DatabaseDeleter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadTxCount retrieves the cumulative number of transactions in the chain up to
+// and including the block corresponding to the hash.
+func ReadTxCount(db DatabaseReader, hash common.Hash, number uint64) (uint64, bool) {
+	data, _ := db.Get(append(append(append(headerPrefix, encodeBlockNumber(number)...), hash[:]...), headerTxCountSuffix...))
+	if len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+// WriteTxCount stores the cumulative transaction count of a block into the database.
+func WriteTxCount(db DatabaseWriter, hash common.Hash, number uint64, count uint64) {
+	key := append(append(append(headerPrefix, encodeBlockNumber(number)...), hash.Bytes()...), headerTxCountSuffix...)
+	if err := db.Put(key, encodeBlockNumber(count)); err != nil {
+		log.Crit("Failed to store block transaction count", "err", err)
+	}
+}
+
+// DeleteTxCount removes the cumulative transaction count associated with a hash.
+func DeleteTxCount(db DatabaseDeleter, hash common.Hash, number uint64) {
+	if err := db.Delete(append(append(append(headerPrefix, encodeBlockNumber(number)...), hash.Bytes()...), headerTxCountSuffix...)); err != nil {
+		log.Crit("Failed to delete block transaction count", "err", err)
+	}
+}
+
 // ReadReceipts retrieves all the transaction receipts belonging to a block.
 func ReadReceipts(db DatabaseReader, hash common.Hash, number uint64) types.Receipts {
 	// Retrieve the flattened receipt slice
@@ -351,6 +376,7 @@ func DeleteBlock(db DatabaseDeleter, hash common.Hash, number uint64) {
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
+	DeleteTxCount(db, hash, number)
 }
 
 // FindCommonAncestor returns the last common ancestor of two block headers
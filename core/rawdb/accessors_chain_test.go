@@ -206,6 +206,26 @@ func TestTdStorage(t *testing.T) {
 	}
 }
 
+// Tests cumulative transaction count storage and retrieval operations.
+func TestTxCountStorage(t *testing.T) {
+	db := eaidb.NewMemDatabase()
+
+	hash, count := common.Hash{0: 0x01}, uint64(314)
+	if _, ok := ReadTxCount(db, hash, 7); ok {
+		t.Fatalf("Non existent tx count returned")
+	}
+	WriteTxCount(db, hash, 7, count)
+	if entry, ok := ReadTxCount(db, hash, 7); !ok {
+		t.Fatalf("Stored tx count not found")
+	} else if entry != count {
+		t.Fatalf("Retrieved tx count mismatch: have %v, want %v", entry, count)
+	}
+	DeleteTxCount(db, hash, 7)
+	if _, ok := ReadTxCount(db, hash, 7); ok {
+		t.Fatalf("Deleted tx count returned")
+	}
+}
+
 // Tests that canonical numbers can be mapped to hashes and retrieved.
 func TestCanonicalMappingStorage(t *testing.T) {
 	db := eaidb.NewMemDatabase()
@@ -18,13 +18,19 @@ package state
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 
 	"github.com/ethereumai/go-ethereumai/common"
 	"github.com/ethereumai/go-ethereumai/rlp"
 	"github.com/ethereumai/go-ethereumai/trie"
 )
 
+// ErrDumpAborted is returned by WriteDump if the dump was aborted before all the
+// accounts were written.
+var ErrDumpAborted = errors.New("state dump aborted")
+
 type DumpAccount struct {
 	Balance  string            `json:"balance"`
 	Nonce    uint64            `json:"nonce"`
@@ -79,3 +85,60 @@ func (self *StateDB) Dump() []byte {
 
 	return json
 }
+
+// WriteDump streams the same JSON document as Dump into w without indentation.
+// Accounts and storage slots are written as the tries are iterated, so only a
+// single account is held in memory at a time, regardless of the state size.
+// Closing abort stops the dump before the next account, leaving w incomplete.
+func (self *StateDB) WriteDump(w io.Writer, abort <-chan struct{}) error {
+	if _, err := fmt.Fprintf(w, `{"root":"%x","accounts":{`, self.trie.Hash()); err != nil {
+		return err
+	}
+	it := trie.NewIterator(self.trie.NodeIterator(nil))
+	for accounts := 0; it.Next(); accounts++ {
+		select {
+		case <-abort:
+			return ErrDumpAborted
+		default:
+		}
+		addr := self.trie.GetKey(it.Key)
+		var data Account
+		if err := rlp.DecodeBytes(it.Value, &data); err != nil {
+			return err
+		}
+		obj := newObject(self, common.BytesToAddress(addr), data)
+
+		sep := ","
+		if accounts == 0 {
+			sep = ""
+		}
+		if _, err := fmt.Fprintf(w, `%s"%x":{"balance":"%s","nonce":%d,"root":"%x","codeHash":"%x","code":"%x","storage":{`,
+			sep, addr, data.Balance, data.Nonce, data.Root, data.CodeHash, obj.Code(self.db)); err != nil {
+			return err
+		}
+		storageIt := trie.NewIterator(obj.getTrie(self.db).NodeIterator(nil))
+		for slots := 0; storageIt.Next(); slots++ {
+			sep := ","
+			if slots == 0 {
+				sep = ""
+			}
+			if _, err := fmt.Fprintf(w, `%s"%x":"%x"`, sep, self.trie.GetKey(storageIt.Key), storageIt.Value); err != nil {
+				return err
+			}
+		}
+		if storageIt.Err != nil {
+			return storageIt.Err
+		}
+		if _, err := io.WriteString(w, "}}"); err != nil {
+			return err
+		}
+		if err := self.Error(); err != nil {
+			return err
+		}
+	}
+	if it.Err != nil {
+		return it.Err
+	}
+	_, err := io.WriteString(w, "}}")
+	return err
+}
@@ -18,7 +18,9 @@ package state
 
 import (
 	"bytes"
+	"encoding/json"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/ethereumai/go-ethereumai/common"
@@ -84,6 +86,25 @@ func (s *StateSuite) TestDump(c *checker.C) {
 	if got != want {
 		c.Errorf("dump mismatch:\ngot: %s\nwant: %s\n", got, want)
 	}
+	// check that the streamed dump carries the same content, storage included
+	obj1.SetState(s.state.db, common.Hash{1}, common.Hash{2})
+	s.state.updateStateObject(obj1)
+	s.state.Commit(false)
+
+	var streamed bytes.Buffer
+	if err := s.state.WriteDump(&streamed, nil); err != nil {
+		c.Fatalf("failed to stream dump: %v", err)
+	}
+	var dump Dump
+	if err := json.Unmarshal(streamed.Bytes(), &dump); err != nil {
+		c.Fatalf("failed to decode streamed dump: %v", err)
+	}
+	if slots := len(dump.Accounts["0000000000000000000000000000000000000001"].Storage); slots != 1 {
+		c.Errorf("streamed storage slots mismatch: have %d, want 1", slots)
+	}
+	if raw := s.state.RawDump(); !reflect.DeepEqual(dump, raw) {
+		c.Errorf("streamed dump mismatch:\ngot: %v\nwant: %v\n", dump, raw)
+	}
 }
 
 func (s *StateSuite) SetUpTest(c *checker.C) {
@@ -32,12 +32,17 @@ import (
 	"github.com/ethereumai/go-ethereumai/log"
 	"github.com/ethereumai/go-ethereumai/metrics"
 	"github.com/ethereumai/go-ethereumai/params"
+	"github.com/hashicorp/golang-lru"
 	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
 )
 
 const (
 	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
 	chainHeadChanSize = 10
+
+	// replacementHistoryLimit is the number of transaction replacements remembered
+	// by the pool to allow tracking sped up or cancelled transactions.
+	replacementHistoryLimit = 4096
 )
 
 var (
@@ -200,12 +205,19 @@ type TxPool struct {
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
 
+	journalPaused bool // Whether journal writes are temporarily suspended
+
 	pending map[common.Address]*txList         // All currently processable transactions
 	queue   map[common.Address]*txList         // Queued but non-processable transactions
 	beats   map[common.Address]time.Time       // Last heartbeat from each known account
 	all     map[common.Hash]*types.Transaction // All transactions to allow lookups
 	priced  *txPricedList                      // All transactions sorted by price
 
+	replacedBy *lru.Cache // Recent replacements, mapping the replaced hash to its replacement
+	replaces   *lru.Cache // Recent replacements, mapping the replacement hash to the replaced one
+
+	expiryWarners map[*expiryWarner]struct{} // Subscriptions to warnings about transactions nearing eviction
+
 	wg sync.WaitGroup // for shutdown sync
 
 	homestead bool
@@ -229,9 +241,13 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		all:         make(map[common.Hash]*types.Transaction),
 		chainHeadCh: make(chan ChainHeadEvent, chainHeadChanSize),
 		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
+
+		expiryWarners: make(map[*expiryWarner]struct{}),
 	}
 	pool.locals = newAccountSet(pool.signer)
 	pool.priced = newTxPricedList(&pool.all)
+	pool.replacedBy, _ = lru.New(replacementHistoryLimit)
+	pool.replaces, _ = lru.New(replacementHistoryLimit)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
 	// If local transactions and journaling is enabled, load from disk
@@ -322,14 +338,17 @@ func (pool *TxPool) loop() {
 					}
 				}
 			}
+			pool.warnExpiring()
 			pool.mu.Unlock()
 
 		// Handle local transaction journal rotation
 		case <-journal.C:
 			if pool.journal != nil {
 				pool.mu.Lock()
-				if err := pool.journal.rotate(pool.local()); err != nil {
-					log.Warn("Failed to rotate local tx journal", "err", err)
+				if !pool.journalPaused {
+					if err := pool.journal.rotate(pool.local()); err != nil {
+						log.Warn("Failed to rotate local tx journal", "err", err)
+					}
 				}
 				pool.mu.Unlock()
 			}
@@ -450,6 +469,82 @@ func (pool *TxPool) SubscribeTxPreEvent(ch chan<- TxPreEvent) event.Subscription
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// expiryWarner is a subscription to warnings about queued transactions nearing
+// their eviction due to the pool's lifetime limit.
+type expiryWarner struct {
+	feed   event.Feed
+	before time.Duration            // Time before eviction to raise the warning
+	warned map[common.Hash]struct{} // Transactions already reported to the subscriber
+}
+
+// SubscribeTxExpiryWarning registers a subscription delivering the hash of each
+// non-local queued transaction once it is within beforeExpiry of being evicted
+// for exceeding the pool's lifetime. Warnings are raised on the pool's eviction
+// schedule, so beforeExpiry should be comfortably above the eviction interval.
+func (pool *TxPool) SubscribeTxExpiryWarning(ch chan<- common.Hash, beforeExpiry time.Duration) event.Subscription {
+	warner := &expiryWarner{before: beforeExpiry, warned: make(map[common.Hash]struct{})}
+	sub := warner.feed.Subscribe(ch)
+
+	pool.mu.Lock()
+	pool.expiryWarners[warner] = struct{}{}
+	pool.mu.Unlock()
+
+	return pool.scope.Track(event.NewSubscription(func(quit <-chan struct{}) error {
+		defer func() {
+			pool.mu.Lock()
+			delete(pool.expiryWarners, warner)
+			pool.mu.Unlock()
+		}()
+		defer sub.Unsubscribe()
+
+		select {
+		case err := <-sub.Err():
+			return err
+		case <-quit:
+			return nil
+		}
+	}))
+}
+
+// warnExpiring notifies the expiry warning subscribers of the non-local queued
+// transactions due for eviction within their requested warning period. Each
+// transaction is reported at most once per subscriber while its account stays
+// inactive.
+//
+// The method assumes the pool lock is held.
+func (pool *TxPool) warnExpiring() {
+	for warner := range pool.expiryWarners {
+		var (
+			warned = make(map[common.Hash]struct{})
+			hashes []common.Hash
+		)
+		for addr, list := range pool.queue {
+			if pool.locals.contains(addr) {
+				continue
+			}
+			if time.Since(pool.beats[addr]) <= pool.config.Lifetime-warner.before {
+				continue
+			}
+			for _, tx := range list.Flatten() {
+				hash := tx.Hash()
+				if _, ok := warner.warned[hash]; !ok {
+					hashes = append(hashes, hash)
+				}
+				warned[hash] = struct{}{}
+			}
+		}
+		warner.warned = warned
+
+		if len(hashes) > 0 {
+			go func(feed *event.Feed) {
+				for _, hash := range hashes {
+					feed.Send(hash)
+				}
+			}(&warner.feed)
+		}
+	}
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -471,6 +566,27 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
 
+// SetJournalEnabled suspends or resumes writing local transactions to the disk
+// journal. Re-enabling the journal regenerates it from the current pool content.
+//
+// Note, any local transactions added while the journal is suspended are lost if
+// the node crashes before it is re-enabled.
+func (pool *TxPool) SetJournalEnabled(enabled bool) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.journal == nil || pool.journalPaused != enabled {
+		return
+	}
+	pool.journalPaused = !enabled
+	if enabled {
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.Warn("Failed to rotate local tx journal", "err", err)
+		}
+	}
+	log.Info("Transaction pool journaling updated", "enabled", enabled)
+}
+
 // State returns the virtual managed state of the transaction pool.
 func (pool *TxPool) State() *state.ManagedState {
 	pool.mu.RLock()
@@ -644,6 +760,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		if old != nil {
 			delete(pool.all, old.Hash())
 			pool.priced.Removed()
+			pool.recordReplacement(old, tx)
 			pendingReplaceCounter.Inc(1)
 		}
 		pool.all[tx.Hash()] = tx
@@ -691,6 +808,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 	if old != nil {
 		delete(pool.all, old.Hash())
 		pool.priced.Removed()
+		pool.recordReplacement(old, tx)
 		queuedReplaceCounter.Inc(1)
 	}
 	if pool.all[hash] == nil {
@@ -700,11 +818,54 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 	return old != nil, nil
 }
 
+// recordReplacement remembers that old was replaced by tx within the pool.
+func (pool *TxPool) recordReplacement(old, tx *types.Transaction) {
+	pool.replacedBy.Add(old.Hash(), tx.Hash())
+	pool.replaces.Add(tx.Hash(), old.Hash())
+}
+
+// ReplacementChain returns the hashes of all transactions with the same sender
+// and nonce as the given one that replaced or were replaced by it, ordered from
+// the oldest to the most recent and including the requested hash. Only a short
+// history is retained, so an error is returned if the transaction was neither
+// replaced nor is known to the pool.
+func (pool *TxPool) ReplacementChain(hash common.Hash) ([]common.Hash, error) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	// Walk back to the oldest known transaction of the chain
+	seen := map[common.Hash]bool{hash: true}
+	first := hash
+	for {
+		prev, ok := pool.replaces.Peek(first)
+		if !ok || seen[prev.(common.Hash)] {
+			break
+		}
+		first = prev.(common.Hash)
+		seen[first] = true
+	}
+	// Walk forward to the latest replacement, collecting the chain
+	chain := []common.Hash{first}
+	seen = map[common.Hash]bool{first: true}
+	for {
+		next, ok := pool.replacedBy.Peek(chain[len(chain)-1])
+		if !ok || seen[next.(common.Hash)] {
+			break
+		}
+		chain = append(chain, next.(common.Hash))
+		seen[next.(common.Hash)] = true
+	}
+	if len(chain) == 1 && pool.all[hash] == nil {
+		return nil, fmt.Errorf("no replacement history for transaction %x", hash)
+	}
+	return chain, nil
+}
+
 // journalTx adds the specified transaction to the local disk journal if it is
 // deemed to have been sent from a local account.
 func (pool *TxPool) journalTx(from common.Address, tx *types.Transaction) {
 	// Only journal if it's enabled and the transaction is local
-	if pool.journal == nil || !pool.locals.contains(from) {
+	if pool.journal == nil || pool.journalPaused || !pool.locals.contains(from) {
 		return
 	}
 	if err := pool.journal.insert(tx); err != nil {
@@ -735,6 +896,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 	if old != nil {
 		delete(pool.all, old.Hash())
 		pool.priced.Removed()
+		pool.recordReplacement(old, tx)
 
 		pendingReplaceCounter.Inc(1)
 	}
@@ -757,6 +919,18 @@ func (pool *TxPool) AddLocal(tx *types.Transaction) error {
 	return pool.addTx(tx, !pool.config.NoLocals)
 }
 
+// ValidateLocal checks whether a transaction would pass the validation AddLocal
+// subjects it to, without adding it to the pool.
+func (pool *TxPool) ValidateLocal(tx *types.Transaction) error {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	if hash := tx.Hash(); pool.all[hash] != nil {
+		return fmt.Errorf("known transaction: %x", hash)
+	}
+	return pool.validateTx(tx, !pool.config.NoLocals)
+}
+
 // AddRemote enqueues a single transaction into the pool if it is valid. If the
 // sender is not among the locally tracked ones, full pricing constraints will
 // apply.
@@ -778,6 +952,35 @@ func (pool *TxPool) AddRemotes(txs []*types.Transaction) []error {
 	return pool.addTxs(txs, false)
 }
 
+// AddLocalAccounts marks the given accounts as local ones, exempting all their
+// pooled and future transactions from the pricing and eviction constraints. Any
+// transactions already pooled from these accounts are journaled too.
+func (pool *TxPool) AddLocalAccounts(addrs []common.Address) {
+	if pool.config.NoLocals {
+		log.Warn("Local transaction handling disabled, ignoring local accounts", "count", len(addrs))
+		return
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for _, addr := range addrs {
+		if pool.locals.contains(addr) {
+			continue
+		}
+		pool.locals.add(addr)
+
+		for _, list := range []*txList{pool.pending[addr], pool.queue[addr]} {
+			if list == nil {
+				continue
+			}
+			for _, tx := range list.Flatten() {
+				pool.journalTx(addr, tx)
+			}
+		}
+	}
+	log.Info("Transaction pool local accounts updated", "added", len(addrs))
+}
+
 // addTx enqueues a single transaction into the pool if it is valid.
 func (pool *TxPool) addTx(tx *types.Transaction, local bool) error {
 	pool.mu.Lock()
@@ -23,6 +23,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
@@ -253,6 +254,35 @@ func TestInvalidTransactions(t *testing.T) {
 	}
 }
 
+// Tests that validating a transaction reports the rejection AddLocal would give,
+// without adding the transaction to the pool.
+func TestValidateLocal(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	tx := transaction(0, 100000, key)
+	from, _ := deriveSender(tx)
+
+	if err := pool.ValidateLocal(tx); err != ErrInsufficientFunds {
+		t.Errorf("unfunded validation error mismatch: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	pool.currentState.AddBalance(from, big.NewInt(1000000))
+	if err := pool.ValidateLocal(tx); err != nil {
+		t.Errorf("funded validation failed: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending+queued != 0 {
+		t.Errorf("validated transaction pooled: %d pending, %d queued", pending, queued)
+	}
+	if err := pool.AddLocal(tx); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if err := pool.ValidateLocal(tx); err == nil {
+		t.Error("known transaction validated")
+	}
+}
+
 func TestTransactionQueue(t *testing.T) {
 	t.Parallel()
 
@@ -906,6 +936,60 @@ func testTransactionQueueTimeLimiting(t *testing.T, nolocals bool) {
 	}
 }
 
+// Tests that queued remote transactions nearing their eviction are reported to
+// expiry warning subscribers exactly once, while locals are never reported.
+func TestTransactionQueueExpiryWarning(t *testing.T) {
+	// Reduce the eviction interval to a testable amount
+	defer func(old time.Duration) { evictionInterval = old }(evictionInterval)
+	evictionInterval = 50 * time.Millisecond
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(eaidb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.Lifetime = 2 * time.Second
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	warnings := make(chan common.Hash, 4)
+	sub := pool.SubscribeTxExpiryWarning(warnings, config.Lifetime-300*time.Millisecond)
+	defer sub.Unsubscribe()
+
+	local, _ := crypto.GenerateKey()
+	remote, _ := crypto.GenerateKey()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1000000000))
+
+	if err := pool.AddLocal(pricedTransaction(1, 100000, big.NewInt(1), local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	// Accounts only gain a heartbeat once a transaction is promoted, so queue up a
+	// gapped transaction behind an executable one
+	if err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(1), remote)); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	tx := pricedTransaction(2, 100000, big.NewInt(1), remote)
+	if err := pool.AddRemote(tx); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	select {
+	case hash := <-warnings:
+		if hash != tx.Hash() {
+			t.Fatalf("warned transaction mismatch: have %x, want %x", hash, tx.Hash())
+		}
+	case <-time.After(config.Lifetime):
+		t.Fatalf("expiry warning timeout")
+	}
+	// Wait a few more eviction rounds and ensure nothing else is reported
+	select {
+	case hash := <-warnings:
+		t.Fatalf("unexpected expiry warning: %x", hash)
+	case <-time.After(5 * evictionInterval):
+	}
+}
+
 // Tests that even if the transaction count belonging to a single account goes
 // above some threshold, as long as the transactions are executable, they are
 // accepted.
@@ -1307,6 +1391,93 @@ func TestTransactionPoolRepricingKeepsLocals(t *testing.T) {
 	validate()
 }
 
+// Tests that accounts marked local at runtime have their already pooled remote
+// transactions exempted from repricing.
+func TestTransactionPoolAddLocalAccounts(t *testing.T) {
+	t.Parallel()
+
+	// Create the pool to test the local account handling with
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(eaidb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	// Create two remote accounts with a pending and a queued transaction each
+	keys := make([]*ecdsa.PrivateKey, 2)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000))
+
+		if err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(1), keys[i])); err != nil {
+			t.Fatalf("failed to add pending transaction: %v", err)
+		}
+		if err := pool.AddRemote(pricedTransaction(2, 100000, big.NewInt(1), keys[i])); err != nil {
+			t.Fatalf("failed to add queued transaction: %v", err)
+		}
+	}
+	// Promote the first account to a local one and reprice the pool
+	pool.AddLocalAccounts([]common.Address{crypto.PubkeyToAddress(keys[0].PublicKey)})
+	pool.SetGasPrice(big.NewInt(2))
+
+	pending, queued := pool.Stats()
+	if pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+	if queued != 1 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 1)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the pool remembers replaced transactions and can reconstruct the
+// full replacement chain from any of its members.
+func TestTransactionPoolReplacementChain(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	// Replace a pending transaction twice and a queued one once
+	pending := []*types.Transaction{
+		pricedTransaction(0, 100000, big.NewInt(1), key),
+		pricedTransaction(0, 100000, big.NewInt(2), key),
+		pricedTransaction(0, 100000, big.NewInt(3), key),
+	}
+	queued := []*types.Transaction{
+		pricedTransaction(2, 100000, big.NewInt(1), key),
+		pricedTransaction(2, 100000, big.NewInt(2), key),
+	}
+	for _, tx := range append(pending, queued...) {
+		if err := pool.AddRemote(tx); err != nil {
+			t.Fatalf("failed to add transaction: %v", err)
+		}
+	}
+	for _, txs := range [][]*types.Transaction{pending, queued} {
+		want := make([]common.Hash, len(txs))
+		for i, tx := range txs {
+			want[i] = tx.Hash()
+		}
+		for _, tx := range txs {
+			chain, err := pool.ReplacementChain(tx.Hash())
+			if err != nil {
+				t.Fatalf("failed to retrieve replacement chain: %v", err)
+			}
+			if !reflect.DeepEqual(chain, want) {
+				t.Errorf("replacement chain mismatch: have %x, want %x", chain, want)
+			}
+		}
+	}
+	// Unknown transactions should have no history
+	if _, err := pool.ReplacementChain(common.Hash{}); err == nil {
+		t.Errorf("replacement chain returned for unknown transaction")
+	}
+}
+
 // Tests that when the pool reaches its global transaction limit, underpriced
 // transactions are gradually shifted out for more expensive ones and any gapped
 // pending transactions are moved into the queue.
@@ -1674,6 +1845,64 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// Tests that local transactions added while journaling is suspended are only
+// persisted once the journal is re-enabled.
+func TestTransactionJournalingPaused(t *testing.T) {
+	t.Parallel()
+
+	// Create a temporary file for the journal
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary journal: %v", err)
+	}
+	journal := file.Name()
+	defer os.Remove(journal)
+
+	// Clean up the temporary file, we only need the path for now
+	file.Close()
+	os.Remove(journal)
+
+	// Create the original pool and suspend journaling
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(eaidb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.Journal = journal
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	local, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(local.PublicKey), big.NewInt(1000000000))
+
+	pool.SetJournalEnabled(false)
+	if err := pool.AddLocal(pricedTransaction(0, 100000, big.NewInt(1), local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	// Re-enable the journal, then add another transaction while suspended again
+	pool.SetJournalEnabled(true)
+	pool.SetJournalEnabled(false)
+
+	if err := pool.AddLocal(pricedTransaction(1, 100000, big.NewInt(1), local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	pool.Stop()
+
+	// Restart the pool and ensure only the flushed transaction survived
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	pending, queued := pool.Stats()
+	if pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+	if queued != 0 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 0)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
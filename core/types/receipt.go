@@ -18,12 +18,15 @@ package types
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"unsafe"
 
 	"github.com/ethereumai/go-ethereumai/common"
 	"github.com/ethereumai/go-ethereumai/common/hexutil"
+	"github.com/ethereumai/go-ethereumai/crypto"
 	"github.com/ethereumai/go-ethereumai/rlp"
 )
 
@@ -55,6 +58,11 @@ type Receipt struct {
 	TxHash          common.Hash    `json:"transactionHash" gencodec:"required"`
 	ContractAddress common.Address `json:"contractAddress"`
 	GasUsed         uint64         `json:"gasUsed" gencodec:"required"`
+
+	// Inclusion information, not stored but derived from the containing block
+	BlockHash        common.Hash `json:"blockHash,omitempty"`
+	BlockNumber      *big.Int    `json:"blockNumber,omitempty"`
+	TransactionIndex uint        `json:"transactionIndex"`
 }
 
 type receiptMarshaling struct {
@@ -62,6 +70,8 @@ type receiptMarshaling struct {
 	Status            hexutil.Uint
 	CumulativeGasUsed hexutil.Uint64
 	GasUsed           hexutil.Uint64
+	BlockNumber       *hexutil.Big
+	TransactionIndex  hexutil.Uint
 }
 
 // receiptRLP is the consensus encoding of a receipt.
@@ -195,6 +205,44 @@ func (r *ReceiptForStorage) DecodeRLP(s *rlp.Stream) error {
 // Receipts is a wrapper around a Receipt array to implement DerivableList.
 type Receipts []*Receipt
 
+// DeriveFields fills in the receipt fields that are not part of the storage
+// encoding (or were not stored by older versions) from the transactions of the
+// containing block: the inclusion information, the gas used by the individual
+// transaction, the created contract address and the metadata of the logs.
+func (r Receipts) DeriveFields(signer Signer, hash common.Hash, number uint64, txs Transactions) error {
+	if len(txs) != len(r) {
+		return errors.New("transaction and receipt count mismatch")
+	}
+	logIndex := uint(0)
+	for i, receipt := range r {
+		receipt.TxHash = txs[i].Hash()
+		receipt.BlockHash = hash
+		receipt.BlockNumber = new(big.Int).SetUint64(number)
+		receipt.TransactionIndex = uint(i)
+
+		receipt.GasUsed = receipt.CumulativeGasUsed
+		if i > 0 {
+			receipt.GasUsed -= r[i-1].CumulativeGasUsed
+		}
+		if txs[i].To() == nil {
+			from, err := Sender(signer, txs[i])
+			if err != nil {
+				return err
+			}
+			receipt.ContractAddress = crypto.CreateAddress(from, txs[i].Nonce())
+		}
+		for _, log := range receipt.Logs {
+			log.BlockNumber = number
+			log.BlockHash = hash
+			log.TxHash = receipt.TxHash
+			log.TxIndex = uint(i)
+			log.Index = logIndex
+			logIndex++
+		}
+	}
+	return nil
+}
+
 // Len returns the number of receipts in this list.
 func (r Receipts) Len() int { return len(r) }
 
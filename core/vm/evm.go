@@ -109,6 +109,9 @@ type EVM struct {
 	// available gas is calculated in gasCall* according to the 63/64 rule and later
 	// applied in opCall*.
 	callGasTemp uint64
+	// limitErr is the first violation of a limit configured in
+	// vmConfig, if any.
+	limitErr error
 }
 
 // NewEVM returns a new EVM. The returned EVM is not thread safe and should
@@ -132,6 +135,32 @@ func (evm *EVM) Cancel() {
 	atomic.StoreInt32(&evm.abort, 1)
 }
 
+// depthExceeded reports whether the current call depth is above the protocol
+// limit or the stricter limit configured for this EVM.
+func (evm *EVM) depthExceeded() bool {
+	if evm.depth > int(params.CallCreateDepth) {
+		return true
+	}
+	if evm.vmConfig.MaxCallDepth > 0 && evm.depth > evm.vmConfig.MaxCallDepth {
+		evm.limitHit(ErrDepth)
+		return true
+	}
+	return false
+}
+
+// limitHit records the violation of a configured execution limit.
+func (evm *EVM) limitHit(err error) {
+	if evm.limitErr == nil {
+		evm.limitErr = err
+	}
+}
+
+// LimitError returns the first violation of the call depth or memory limits
+// configured in the EVM's Config, or nil if execution stayed within them.
+func (evm *EVM) LimitError() error {
+	return evm.limitErr
+}
+
 // Call executes the contract associated with the addr with the given input as
 // parameters. It also handles any necessary value transfer required and takes
 // the necessary steps to create accounts and reverses the state in case of an
@@ -142,7 +171,7 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 	}
 
 	// Fail if we're trying to execute above the call depth limit
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depthExceeded() {
 		return nil, gas, ErrDepth
 	}
 	// Fail if we're trying to transfer more than the available balance
@@ -213,7 +242,7 @@ func (evm *EVM) CallCode(caller ContractRef, addr common.Address, input []byte,
 	}
 
 	// Fail if we're trying to execute above the call depth limit
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depthExceeded() {
 		return nil, gas, ErrDepth
 	}
 	// Fail if we're trying to transfer more than the available balance
@@ -251,7 +280,7 @@ func (evm *EVM) DelegateCall(caller ContractRef, addr common.Address, input []by
 		return nil, gas, nil
 	}
 	// Fail if we're trying to execute above the call depth limit
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depthExceeded() {
 		return nil, gas, ErrDepth
 	}
 
@@ -283,7 +312,7 @@ func (evm *EVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 		return nil, gas, nil
 	}
 	// Fail if we're trying to execute above the call depth limit
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depthExceeded() {
 		return nil, gas, ErrDepth
 	}
 	// Make sure the readonly is only set if we aren't in readonly yet
@@ -322,7 +351,7 @@ func (evm *EVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 
 	// Depth check execution. Fail if we're trying to execute above the
 	// limit.
-	if evm.depth > int(params.CallCreateDepth) {
+	if evm.depthExceeded() {
 		return nil, common.Address{}, gas, ErrDepth
 	}
 	if !evm.CanTransfer(evm.StateDB, caller.Address(), value) {
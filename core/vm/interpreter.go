@@ -35,6 +35,12 @@ type Config struct {
 	NoRecursion bool
 	// Enable recording of SHA3/keccak preimages
 	EnablePreimageRecording bool
+	// MaxCallDepth restricts the call depth below the protocol
+	// limit. Zero leaves only the protocol limit in place.
+	MaxCallDepth int
+	// MaxMemory restricts the memory a single call frame may
+	// expand to, in bytes. Zero leaves memory bounded by gas only.
+	MaxMemory uint64
 	// JumpTable contains the EVM instruction table. This
 	// may be left uninitialised and will be set to the default
 	// table.
@@ -182,6 +188,10 @@ func (in *Interpreter) Run(contract *Contract, input []byte) (ret []byte, err er
 			if memorySize, overflow = math.SafeMul(toWordSize(memSize), 32); overflow {
 				return nil, errGasUintOverflow
 			}
+			if in.cfg.MaxMemory > 0 && memorySize > in.cfg.MaxMemory {
+				in.evm.limitHit(ErrMemoryLimit)
+				return nil, ErrMemoryLimit
+			}
 		}
 		// consume the gas and return an error if not enough gas is available.
 		// cost is explicitly set so that the capture state defer method can get the proper cost
@@ -93,6 +93,23 @@ func TestExecute(t *testing.T) {
 	}
 }
 
+func TestExecuteMemoryLimit(t *testing.T) {
+	code := []byte{
+		byte(vm.PUSH1), 10,
+		byte(vm.PUSH2), 0x10, 0x00,
+		byte(vm.MSTORE),
+	}
+	// Make sure the code runs fine without any limits in place
+	if _, _, err := Execute(code, nil, nil); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	// Restrict the memory below the required amount and ensure the call fails
+	_, _, err := Execute(code, nil, &Config{EVMConfig: vm.Config{MaxMemory: 1024}})
+	if err != vm.ErrMemoryLimit {
+		t.Fatalf("error mismatch: have %v, want %v", err, vm.ErrMemoryLimit)
+	}
+}
+
 func TestCall(t *testing.T) {
 	state, _ := state.New(common.Hash{}, state.NewDatabase(eaidb.NewMemDatabase()))
 	address := common.HexToAddress("0x0a")
@@ -25,14 +25,16 @@ import (
 	"math/big"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/ethereumai/go-ethereumai/common"
 	"github.com/ethereumai/go-ethereumai/common/hexutil"
+	"github.com/ethereumai/go-ethereumai/consensus/eaiash"
 	"github.com/ethereumai/go-ethereumai/core"
 	"github.com/ethereumai/go-ethereumai/core/rawdb"
 	"github.com/ethereumai/go-ethereumai/core/state"
 	"github.com/ethereumai/go-ethereumai/core/types"
-	"github.com/ethereumai/go-ethereumai/log"
+	"github.com/ethereumai/go-ethereumai/eai/filters"
 	"github.com/ethereumai/go-ethereumai/miner"
 	"github.com/ethereumai/go-ethereumai/params"
 	"github.com/ethereumai/go-ethereumai/rlp"
@@ -66,6 +68,134 @@ func (api *PublicEthereumAIAPI) Hashrate() hexutil.Uint64 {
 	return hexutil.Uint64(api.e.Miner().HashRate())
 }
 
+// NetworkInfo returns the chain ID and network ID of the node, and whether they
+// differ. A mismatch usually hints at a misconfigured private network.
+func (api *PublicEthereumAIAPI) NetworkInfo() (map[string]interface{}, error) {
+	chainID, networkID, mismatch, err := api.e.APIBackend.NetworkInfo()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"chainId":   (*hexutil.Big)(chainID),
+		"networkId": hexutil.Uint64(networkID),
+		"mismatch":  mismatch,
+	}, nil
+}
+
+// HeadAge returns the number of seconds elapsed since the timestamp of the
+// current head block, useful as a liveness signal for monitoring.
+func (api *PublicEthereumAIAPI) HeadAge() hexutil.Uint64 {
+	return hexutil.Uint64(api.e.APIBackend.HeadAge() / time.Second)
+}
+
+// MinGasPrice returns the minimum gas price a remote transaction must pay to be
+// accepted into the node's transaction pool.
+func (api *PublicEthereumAIAPI) MinGasPrice() *hexutil.Big {
+	return (*hexutil.Big)(api.e.APIBackend.MinAcceptedGasPrice())
+}
+
+// NextEpoch returns the number of blocks remaining until the next epoch boundary
+// of the consensus engine.
+func (api *PublicEthereumAIAPI) NextEpoch(ctx context.Context) (*EpochInfo, error) {
+	return api.e.APIBackend.NextEpochInfo(ctx)
+}
+
+// CumulativeGasBefore returns the gas used by all transactions preceding the
+// given transaction in its block.
+func (api *PublicEthereumAIAPI) CumulativeGasBefore(ctx context.Context, txHash common.Hash) (hexutil.Uint64, error) {
+	gas, err := api.e.APIBackend.CumulativeGasBefore(ctx, txHash)
+	return hexutil.Uint64(gas), err
+}
+
+// ReplacementChain returns the hashes of all transactions sharing the sender and
+// nonce of the given one that replaced or were replaced by it, oldest first.
+func (api *PublicEthereumAIAPI) ReplacementChain(hash common.Hash) ([]common.Hash, error) {
+	return api.e.APIBackend.ReplacementChain(hash)
+}
+
+// CalldataGasCost returns the breakdown of the intrinsic gas a transaction with
+// the given recipient and payload is charged, counting its zero and non-zero
+// bytes. A missing recipient denotes a contract creation.
+func (api *PublicEthereumAIAPI) CalldataGasCost(to *common.Address, data hexutil.Bytes) map[string]interface{} {
+	var tx *types.Transaction
+	if to == nil {
+		tx = types.NewContractCreation(0, new(big.Int), 0, new(big.Int), data)
+	} else {
+		tx = types.NewTransaction(0, *to, new(big.Int), 0, new(big.Int), data)
+	}
+	zeros, nonZeros, gas := api.e.APIBackend.CalldataGasCost(tx)
+	return map[string]interface{}{
+		"zeroBytes":    zeros,
+		"nonZeroBytes": nonZeros,
+		"gas":          hexutil.Uint64(gas),
+	}
+}
+
+// BlocksMinedBy returns the numbers of the blocks in the inclusive range [from, to]
+// mined by the given address.
+func (api *PublicEthereumAIAPI) BlocksMinedBy(ctx context.Context, miner common.Address, from, to hexutil.Uint64) ([]hexutil.Uint64, error) {
+	numbers, err := api.e.APIBackend.BlocksMinedBy(ctx, miner, uint64(from), uint64(to))
+	if err != nil {
+		return nil, err
+	}
+	mined := make([]hexutil.Uint64, len(numbers))
+	for i, number := range numbers {
+		mined[i] = hexutil.Uint64(number)
+	}
+	return mined, nil
+}
+
+// BalanceHistory returns the balance of the account at each of the given blocks,
+// with null entries for blocks whose state has been pruned. At most 256 blocks
+// may be queried at once.
+func (api *PublicEthereumAIAPI) BalanceHistory(ctx context.Context, addr common.Address, blocks []hexutil.Uint64) ([]*hexutil.Big, error) {
+	numbers := make([]uint64, len(blocks))
+	for i, number := range blocks {
+		numbers[i] = uint64(number)
+	}
+	balances, err := api.e.APIBackend.BalanceHistory(ctx, addr, numbers)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]*hexutil.Big, len(balances))
+	for i, balance := range balances {
+		history[i] = (*hexutil.Big)(balance)
+	}
+	return history, nil
+}
+
+// TotalTransactions returns the cumulative number of transactions in the chain up
+// to and including the given block.
+func (api *PublicEthereumAIAPI) TotalTransactions(ctx context.Context, blockNr rpc.BlockNumber) (hexutil.Uint64, error) {
+	count, err := api.e.APIBackend.TotalTransactions(ctx, blockNr)
+	return hexutil.Uint64(count), err
+}
+
+// NetworkHashrate returns the estimated total hashrate of the network, averaged
+// over the given number of most recent blocks.
+func (api *PublicEthereumAIAPI) NetworkHashrate(ctx context.Context, sampleBlocks hexutil.Uint64) (*hexutil.Big, error) {
+	hashrate, err := api.e.APIBackend.NetworkHashrate(ctx, uint64(sampleBlocks))
+	return (*hexutil.Big)(hashrate), err
+}
+
+// DifficultyBomb returns the seconds the difficulty bomb currently adds to the
+// average block time and the number of blocks until it adds at least a second.
+func (api *PublicEthereumAIAPI) DifficultyBomb() (map[string]interface{}, error) {
+	delay, blocks, err := api.e.DifficultyBombStatus()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"delaySeconds":          delay,
+		"blocksUntilNoticeable": hexutil.Uint64(blocks),
+	}, nil
+}
+
+// ActiveEIPs returns the numbers of the EIPs in effect at the given block.
+func (api *PublicEthereumAIAPI) ActiveEIPs(ctx context.Context, blockNr rpc.BlockNumber) ([]int, error) {
+	return api.e.APIBackend.ActiveEIPs(ctx, blockNr)
+}
+
 // PublicMinerAPI provides an API to control the miner.
 // It offers only methods that operate on data that pose no security risk when it is publicly accessible.
 type PublicMinerAPI struct {
@@ -98,7 +228,7 @@ func (api *PublicMinerAPI) SubmitWork(nonce types.BlockNonce, solution, digest c
 // result[2], 32 bytes hex encoded boundary condition ("target"), 2^256/difficulty
 func (api *PublicMinerAPI) GetWork() ([3]string, error) {
 	if !api.e.IsMining() {
-		if err := api.e.StartMining(false); err != nil {
+		if err := api.e.StartMining(nil); err != nil {
 			return [3]string{}, err
 		}
 	}
@@ -128,25 +258,16 @@ func NewPrivateMinerAPI(e *EthereumAI) *PrivateMinerAPI {
 	return &PrivateMinerAPI{e: e}
 }
 
-// Start the miner with the given number of threads. If threads is nil the number
-// of workers started is equal to the number of logical CPUs that are usable by
-// this process. If mining is already running, this method adjust the number of
-// threads allowed to use.
+// Start the miner with the given number of threads. If threads is nil or zero the
+// number of workers started is equal to the number of logical CPUs that are usable
+// by this process, while a negative count leaves sealing to remote agents. A nil
+// count starts no local threads if sealing is delegated to a remote sealer. If
+// mining is already running, this method adjust the number of threads allowed to use.
 func (api *PrivateMinerAPI) Start(threads *int) error {
-	// Set the number of threads if the seal engine supports it
-	if threads == nil {
+	// Default to all CPUs, unless the nonce search is delegated to a remote sealer
+	if threads == nil && !api.e.RemoteSealing() {
 		threads = new(int)
-	} else if *threads == 0 {
-		*threads = -1 // Disable the miner from within
 	}
-	type threaded interface {
-		SetThreads(threads int)
-	}
-	if th, ok := api.e.engine.(threaded); ok {
-		log.Info("Updated mining threads", "threads", *threads)
-		th.SetThreads(*threads)
-	}
-	// Start the miner and return
 	if !api.e.IsMining() {
 		// Propagate the initial price point to the transaction pool
 		api.e.lock.RLock()
@@ -154,9 +275,8 @@ func (api *PrivateMinerAPI) Start(threads *int) error {
 		api.e.lock.RUnlock()
 
 		api.e.txPool.SetGasPrice(price)
-		return api.e.StartMining(true)
 	}
-	return nil
+	return api.e.StartMining(threads)
 }
 
 // Stop the miner
@@ -181,11 +301,7 @@ func (api *PrivateMinerAPI) SetExtra(extra string) (bool, error) {
 
 // SetGasPrice sets the minimum accepted gas price for the miner.
 func (api *PrivateMinerAPI) SetGasPrice(gasPrice hexutil.Big) bool {
-	api.e.lock.Lock()
-	api.e.gasPrice = (*big.Int)(&gasPrice)
-	api.e.lock.Unlock()
-
-	api.e.txPool.SetGasPrice((*big.Int)(&gasPrice))
+	api.e.SetGasPrice((*big.Int)(&gasPrice))
 	return true
 }
 
@@ -200,6 +316,51 @@ func (api *PrivateMinerAPI) GetHashrate() uint64 {
 	return uint64(api.e.miner.HashRate())
 }
 
+// GetHashratePerThread returns the current hashrate of each local mining thread.
+func (api *PrivateMinerAPI) GetHashratePerThread() ([]float64, error) {
+	engine, ok := api.e.engine.(*eaiash.Eaiash)
+	if !ok {
+		return nil, errors.New("per-thread hashrates require an eaiash engine")
+	}
+	return engine.HashratePerThread(), nil
+}
+
+// ForceInclude places a pending transaction at the front of the next block the
+// miner builds, provided it is the next executable transaction of its sender.
+func (api *PrivateMinerAPI) ForceInclude(hash common.Hash) error {
+	return api.e.ForceInclude(hash)
+}
+
+// ExpectedWork describes the work needed to seal the next block and, given the
+// local hashrate, roughly how long that is expected to take.
+type ExpectedWork struct {
+	Hashes        *hexutil.Big    `json:"hashes"`
+	Hashrate      hexutil.Uint64  `json:"hashrate"`
+	EstimatedTime *hexutil.Uint64 `json:"estimatedTime"` // Seconds, nil if not mining
+}
+
+// ExpectedWork returns the approximate number of hashes needed to find the next
+// block at the current difficulty, along with the expected time to find it at
+// the node's measured hashrate.
+func (api *PrivateMinerAPI) ExpectedWork() (*ExpectedWork, error) {
+	hashes, err := api.e.ExpectedHashesPerBlock()
+	if err != nil {
+		return nil, err
+	}
+	work := &ExpectedWork{
+		Hashes:   (*hexutil.Big)(hashes),
+		Hashrate: hexutil.Uint64(api.e.miner.HashRate()),
+	}
+	if work.Hashrate > 0 {
+		seconds := new(big.Int).Div(hashes, new(big.Int).SetUint64(uint64(work.Hashrate)))
+		if seconds.IsUint64() {
+			estimate := hexutil.Uint64(seconds.Uint64())
+			work.EstimatedTime = &estimate
+		}
+	}
+	return work, nil
+}
+
 // PrivateAdminAPI is the collection of EthereumAI full node-related APIs
 // exposed over the private admin endpoint.
 type PrivateAdminAPI struct {
@@ -212,6 +373,41 @@ func NewPrivateAdminAPI(eai *EthereumAI) *PrivateAdminAPI {
 	return &PrivateAdminAPI{eai: eai}
 }
 
+// SetLocalAccounts marks the given accounts as local in the transaction pool,
+// exempting their transactions from the pool's pricing and eviction rules.
+func (api *PrivateAdminAPI) SetLocalAccounts(addrs []common.Address) bool {
+	api.eai.SetLocalAccounts(addrs)
+	return true
+}
+
+// SetJournalEnabled suspends or resumes writing local transactions to the disk
+// journal of the transaction pool. Local transactions added while suspended are
+// lost on a crash.
+func (api *PrivateAdminAPI) SetJournalEnabled(enabled bool) bool {
+	api.eai.SetJournalEnabled(enabled)
+	return true
+}
+
+// ScheduleStateExport periodically exports the head state into dir, keeping the
+// most recent exports only. The interval is given in seconds, zero stops the
+// scheduled exports.
+func (api *PrivateAdminAPI) ScheduleStateExport(interval uint64, dir string) (bool, error) {
+	if err := api.eai.ScheduleStateExport(time.Duration(interval)*time.Second, dir); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PruneAccountHistory removes the storage history of an account before the given
+// block. It is only supported by archive nodes and suspends block imports while
+// it runs, see EthereumAI.PruneAccountHistory for the details.
+func (api *PrivateAdminAPI) PruneAccountHistory(addr common.Address, beforeBlock hexutil.Uint64) (bool, error) {
+	if err := api.eai.PruneAccountHistory(addr, uint64(beforeBlock)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // ExportChain exports the current blockchain into a local file.
 func (api *PrivateAdminAPI) ExportChain(file string) (bool, error) {
 	// Make sure we can create the file to export into
@@ -244,6 +440,24 @@ func hasAllBlocks(chain *core.BlockChain, bs []*types.Block) bool {
 	return true
 }
 
+// LightServerInfo returns the light client serving status and capacity of the node.
+func (api *PrivateAdminAPI) LightServerInfo() *LightServerInfo {
+	return api.eai.LightServerInfo()
+}
+
+// CancelSync aborts the chain synchronisation in progress, if any, reporting the
+// progress it had made. The node resumes syncing with its next sync cycle.
+func (api *PrivateAdminAPI) CancelSync() string {
+	downloader := api.eai.protocolManager.downloader
+	if !downloader.Synchronising() {
+		return "no sync in progress"
+	}
+	progress := downloader.Progress()
+	downloader.Cancel()
+
+	return fmt.Sprintf("sync cancelled at block %d of %d (started at %d)", progress.CurrentBlock, progress.HighestBlock, progress.StartingBlock)
+}
+
 // ImportChain imports a blockchain from a local file.
 func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 	// Make sure the can access the file to import
@@ -351,6 +565,62 @@ func (api *PrivateDebugAPI) Preimage(ctx context.Context, hash common.Hash) (hex
 	return nil, errors.New("unknown preimage")
 }
 
+// StateAvailability returns for each block in the inclusive range [from, to]
+// whether its state is still available or has already been pruned.
+func (api *PrivateDebugAPI) StateAvailability(from, to uint64) ([]bool, error) {
+	return api.eai.StateAvailability(from, to)
+}
+
+// TracePeerMessages enables or disables debug logging of all protocol messages
+// exchanged with the given peer until it disconnects.
+func (api *PrivateDebugAPI) TracePeerMessages(peerID string, enable bool) error {
+	return api.eai.TracePeerMessages(peerID, enable)
+}
+
+// ActiveSubscriptions returns all the event subscriptions and filters currently
+// installed on the node, oldest first.
+func (api *PrivateDebugAPI) ActiveSubscriptions() []filters.SubscriptionInfo {
+	return api.eai.ActiveSubscriptions()
+}
+
+// BandwidthStats returns the message payload bytes exchanged with peers since the
+// node started, in total and per protocol message type.
+func (api *PrivateDebugAPI) BandwidthStats() *BandwidthStats {
+	return api.eai.BandwidthStats()
+}
+
+// StorageGrowth projects the disk usage of the chain data from its growth over
+// the recent blocks.
+func (api *PrivateDebugAPI) StorageGrowth() (*StorageGrowth, error) {
+	return api.eai.StorageGrowth()
+}
+
+// FutureBlockCount returns the number of blocks queued for import because their
+// timestamps are ahead of the local clock.
+func (api *PrivateDebugAPI) FutureBlockCount() int {
+	return api.eai.BlockChain().FutureBlockCount()
+}
+
+// FutureBlocks returns the blocks queued for import because their timestamps are
+// ahead of the local clock.
+func (api *PrivateDebugAPI) FutureBlocks() []FutureBlockInfo {
+	return api.eai.FutureBlocks()
+}
+
+// ClockSkew returns the estimated offset in seconds of the local clock to the
+// blocks propagated by each peer, positive if the local clock is ahead.
+func (api *PrivateDebugAPI) ClockSkew() (map[string]float64, error) {
+	skews, err := api.eai.ClockSkew()
+	if err != nil {
+		return nil, err
+	}
+	seconds := make(map[string]float64, len(skews))
+	for id, skew := range skews {
+		seconds[id] = skew.Seconds()
+	}
+	return seconds, nil
+}
+
 // GetBadBLocks returns a list of the last 'bad blocks' that the client has seen on the network
 // and returns them as a JSON list of block-hashes
 func (api *PrivateDebugAPI) GetBadBlocks(ctx context.Context) ([]core.BadBlockArgs, error) {
@@ -372,7 +642,7 @@ type storageEntry struct {
 
 // StorageRangeAt returns the storage at the given block height and transaction index.
 func (api *PrivateDebugAPI) StorageRangeAt(ctx context.Context, blockHash common.Hash, txIndex int, contractAddress common.Address, keyStart hexutil.Bytes, maxResult int) (StorageRangeResult, error) {
-	_, _, statedb, err := api.computeTxEnv(blockHash, txIndex, 0)
+	_, _, statedb, err := api.computeTxEnv(ctx, blockHash, txIndex, 0)
 	if err != nil {
 		return StorageRangeResult{}, err
 	}
@@ -17,12 +17,21 @@
 package eai
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/ethereumai/go-ethereumai/accounts"
 	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/common/hexutil"
 	"github.com/ethereumai/go-ethereumai/common/math"
+	"github.com/ethereumai/go-ethereumai/consensus/clique"
+	"github.com/ethereumai/go-ethereumai/consensus/eaiash"
 	"github.com/ethereumai/go-ethereumai/core"
 	"github.com/ethereumai/go-ethereumai/core/bloombits"
 	"github.com/ethereumai/go-ethereumai/core/rawdb"
@@ -33,8 +42,10 @@ import (
 	"github.com/ethereumai/go-ethereumai/eai/gasprice"
 	"github.com/ethereumai/go-ethereumai/eaidb"
 	"github.com/ethereumai/go-ethereumai/event"
+	"github.com/ethereumai/go-ethereumai/internal/eaiapi"
 	"github.com/ethereumai/go-ethereumai/params"
 	"github.com/ethereumai/go-ethereumai/rpc"
+	"github.com/ethereumai/go-ethereumai/trie"
 )
 
 // EaiAPIBackend implements eaiapi.Backend for full nodes
@@ -47,6 +58,39 @@ func (b *EaiAPIBackend) ChainConfig() *params.ChainConfig {
 	return b.eai.chainConfig
 }
 
+// RPCGasCap returns the maximum gas a single call over RPC may use, or nil if
+// calls are not capped.
+func (b *EaiAPIBackend) RPCGasCap() *big.Int {
+	return b.eai.config.RPCGasCap
+}
+
+// NetworkInfo returns the chain ID used for transaction signing alongside the
+// network ID used for peering, flagging whether the two differ.
+func (b *EaiAPIBackend) NetworkInfo() (chainID *big.Int, networkID uint64, mismatch bool, err error) {
+	if b.eai.chainConfig.ChainId == nil {
+		return nil, 0, false, errors.New("chain ID not configured")
+	}
+	chainID, networkID = new(big.Int).Set(b.eai.chainConfig.ChainId), b.eai.NetVersion()
+	return chainID, networkID, !chainID.IsUint64() || chainID.Uint64() != networkID, nil
+}
+
+// HeadAge returns the wall-clock time elapsed since the timestamp of the current
+// head block. Blocks stamped in the future yield a zero age.
+func (b *EaiAPIBackend) HeadAge() time.Duration {
+	head := b.eai.blockchain.CurrentHeader()
+	age := time.Since(time.Unix(head.Time.Int64(), 0))
+	if age < 0 {
+		return 0
+	}
+	return age
+}
+
+// MinAcceptedGasPrice returns the gas price floor the transaction pool currently
+// enforces on remote transactions, including any runtime adjustments.
+func (b *EaiAPIBackend) MinAcceptedGasPrice() *big.Int {
+	return b.eai.txPool.GasPrice()
+}
+
 func (b *EaiAPIBackend) CurrentBlock() *types.Block {
 	return b.eai.blockchain.CurrentBlock()
 }
@@ -82,6 +126,12 @@ func (b *EaiAPIBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumb
 	return b.eai.blockchain.GetBlockByNumber(uint64(blockNr)), nil
 }
 
+// HeaderChain returns the canonical headers within the inclusive range [from, to]
+// for external verification of their linkage and seals.
+func (b *EaiAPIBackend) HeaderChain(ctx context.Context, from, to uint64) ([]*types.Header, error) {
+	return eaiapi.RetrieveHeaderChain(ctx, b, from, to)
+}
+
 func (b *EaiAPIBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
 	// Pending state is only known by the miner
 	if blockNr == rpc.PendingBlockNumber {
@@ -97,15 +147,86 @@ func (b *EaiAPIBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.
 	return stateDb, header, err
 }
 
+// StateAndHeaderByHash returns the state and header of the block with the given
+// hash, regardless of whether it is canonical.
+func (b *EaiAPIBackend) StateAndHeaderByHash(ctx context.Context, hash common.Hash) (*state.StateDB, *types.Header, error) {
+	header := b.eai.blockchain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, nil, fmt.Errorf("block %x not found", hash)
+	}
+	stateDb, err := b.eai.BlockChain().StateAt(header.Root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("state of block #%d [%x] unavailable, possibly pruned: %v", header.Number, hash, err)
+	}
+	return stateDb, header, nil
+}
+
+// GetProof returns the merkle proofs of the account at the given address and of
+// the given storage slots in the state of the given block.
+func (b *EaiAPIBackend) GetProof(ctx context.Context, address common.Address, storageKeys []common.Hash, blockNr rpc.BlockNumber) (*eaiapi.AccountResult, error) {
+	state, _, err := b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	return eaiapi.ProveAccount(state, address, storageKeys)
+}
+
 func (b *EaiAPIBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
 	return b.eai.blockchain.GetBlockByHash(hash), nil
 }
 
+// GetTransaction retrieves a canonical transaction and its location from the
+// local transaction index. A nil transaction is returned if it is unknown.
+func (b *EaiAPIBackend) GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(b.eai.chainDb, txHash)
+	return tx, blockHash, blockNumber, index, nil
+}
+
+// GetReceipts retrieves the receipts of a block, deriving the fields not kept in
+// the database (or not stored by older versions) from the block's transactions.
 func (b *EaiAPIBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
-	if number := rawdb.ReadHeaderNumber(b.eai.chainDb, hash); number != nil {
-		return rawdb.ReadReceipts(b.eai.chainDb, hash, *number), nil
+	number := rawdb.ReadHeaderNumber(b.eai.chainDb, hash)
+	if number == nil {
+		return nil, nil
+	}
+	return b.readReceipts(hash, *number)
+}
+
+// GetReceiptsByNumber returns the fully derived receipts of the canonical block
+// with the given number, or nil if the block is unknown.
+func (b *EaiAPIBackend) GetReceiptsByNumber(ctx context.Context, blockNr rpc.BlockNumber) (types.Receipts, error) {
+	var number uint64
+	switch blockNr {
+	case rpc.PendingBlockNumber:
+		return nil, errors.New("receipts of the pending block are not available")
+	case rpc.LatestBlockNumber:
+		number = b.eai.blockchain.CurrentBlock().NumberU64()
+	default:
+		number = uint64(blockNr)
+	}
+	hash := rawdb.ReadCanonicalHash(b.eai.chainDb, number)
+	if hash == (common.Hash{}) {
+		return nil, nil
+	}
+	return b.readReceipts(hash, number)
+}
+
+// readReceipts reads the receipts of a block from the database and derives their
+// non-stored fields from the block body.
+func (b *EaiAPIBackend) readReceipts(hash common.Hash, number uint64) (types.Receipts, error) {
+	receipts := rawdb.ReadReceipts(b.eai.chainDb, hash, number)
+	if receipts == nil {
+		return nil, nil
+	}
+	body := rawdb.ReadBody(b.eai.chainDb, hash, number)
+	if body == nil {
+		return nil, fmt.Errorf("block body %x not found", hash)
 	}
-	return nil, nil
+	signer := types.MakeSigner(b.eai.chainConfig, new(big.Int).SetUint64(number))
+	if err := receipts.DeriveFields(signer, hash, number, body.Transactions); err != nil {
+		return nil, err
+	}
+	return receipts, nil
 }
 
 func (b *EaiAPIBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
@@ -128,12 +249,53 @@ func (b *EaiAPIBackend) GetTd(blockHash common.Hash) *big.Int {
 	return b.eai.blockchain.GetTdByHash(blockHash)
 }
 
+func (b *EaiAPIBackend) TrieNode(ctx context.Context, hash common.Hash) ([]byte, error) {
+	blob, err := b.eai.blockchain.TrieNode(hash)
+	if err != nil || len(blob) == 0 {
+		return nil, fmt.Errorf("trie node %x not found", hash)
+	}
+	return blob, nil
+}
+
+// ChainContext returns the chain the backend executes transactions against, as
+// needed to assemble EVM contexts for historical blocks.
+func (b *EaiAPIBackend) ChainContext() core.ChainContext {
+	return b.eai.blockchain
+}
+
+// GetEVM creates an EVM for executing a call on the given state. The execution is
+// aborted once ctx is cancelled, in which case the returned error function (to be
+// invoked after the call) reports the context's error.
 func (b *EaiAPIBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
 	state.SetBalance(msg.From(), math.MaxBig256)
-	vmError := func() error { return nil }
-
+	if vmCfg.MaxCallDepth == 0 {
+		vmCfg.MaxCallDepth = b.eai.config.EVMCallMaxDepth
+	}
+	if vmCfg.MaxMemory == 0 {
+		vmCfg.MaxMemory = b.eai.config.EVMCallMaxMemory
+	}
 	context := core.NewEVMContext(msg, header, b.eai.BlockChain(), nil)
-	return vm.NewEVM(context, state, b.eai.chainConfig, vmCfg), vmError, nil
+	evm := vm.NewEVM(context, state, b.eai.chainConfig, vmCfg)
+
+	// Abort the execution if the request is cancelled before the call completes,
+	// the watcher being released by the error retrieval after the call.
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			evm.Cancel()
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	vmError := func() error {
+		once.Do(func() { close(done) })
+		if err := evm.LimitError(); err != nil {
+			return err
+		}
+		return ctx.Err()
+	}
+	return evm, vmError, nil
 }
 
 func (b *EaiAPIBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
@@ -152,14 +314,32 @@ func (b *EaiAPIBackend) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) e
 	return b.eai.BlockChain().SubscribeChainSideEvent(ch)
 }
 
+// SubscribeReorgEvent registers a subscription of reorgs, each reported as a
+// single event with the full set of dropped and added blocks.
+func (b *EaiAPIBackend) SubscribeReorgEvent(ch chan<- core.ReorgEvent) event.Subscription {
+	return b.eai.BlockChain().SubscribeReorgEvent(ch)
+}
+
 func (b *EaiAPIBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return b.eai.BlockChain().SubscribeLogsEvent(ch)
 }
 
+// SubscribePendingLogsEvent delivers the logs of the miner's pending block each
+// time it is reassembled.
+func (b *EaiAPIBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription {
+	return b.eai.miner.SubscribePendingLogs(ch)
+}
+
 func (b *EaiAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
 	return b.eai.txPool.AddLocal(signedTx)
 }
 
+// WouldAccept checks whether the transaction pool would accept the transaction if
+// it were sent, returning the reason of the rejection otherwise.
+func (b *EaiAPIBackend) WouldAccept(ctx context.Context, signedTx *types.Transaction) error {
+	return b.eai.txPool.ValidateLocal(signedTx)
+}
+
 func (b *EaiAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending, err := b.eai.txPool.Pending()
 	if err != nil {
@@ -172,10 +352,138 @@ func (b *EaiAPIBackend) GetPoolTransactions() (types.Transactions, error) {
 	return txs, nil
 }
 
+// PendingAccounts returns the distinct senders of the pending transactions in the
+// pool, ordered by address, along with the total number of pending transactions.
+func (b *EaiAPIBackend) PendingAccounts() ([]common.Address, int, error) {
+	pending, err := b.eai.txPool.Pending()
+	if err != nil {
+		return nil, 0, err
+	}
+	accounts, count := make([]common.Address, 0, len(pending)), 0
+	for addr, txs := range pending {
+		accounts = append(accounts, addr)
+		count += len(txs)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return bytes.Compare(accounts[i][:], accounts[j][:]) < 0 })
+	return accounts, count, nil
+}
+
 func (b *EaiAPIBackend) GetPoolTransaction(hash common.Hash) *types.Transaction {
 	return b.eai.txPool.Get(hash)
 }
 
+// ReplacementChain returns the hashes of the transactions that replaced or were
+// replaced by the given one, oldest first, as long as the pool remembers them.
+func (b *EaiAPIBackend) ReplacementChain(hash common.Hash) ([]common.Hash, error) {
+	return b.eai.txPool.ReplacementChain(hash)
+}
+
+// SubscribeTxExpiryWarning notifies the given channel of queued transactions
+// that are within beforeExpiry of being evicted from the pool for their age.
+func (b *EaiAPIBackend) SubscribeTxExpiryWarning(ch chan<- common.Hash, beforeExpiry time.Duration) event.Subscription {
+	return b.eai.txPool.SubscribeTxExpiryWarning(ch, beforeExpiry)
+}
+
+// ReplayWithGasPrice re-executes a mined transaction on top of the state of its
+// parent block and the transactions preceding it in its own block, with all but
+// its gas price unchanged, reporting whether it would still have succeeded.
+func (b *EaiAPIBackend) ReplayWithGasPrice(ctx context.Context, txHash common.Hash, newGasPrice *big.Int) (*eaiapi.ExecutionResult, error) {
+	if newGasPrice == nil || newGasPrice.Sign() < 0 {
+		return nil, fmt.Errorf("invalid gas price %v", newGasPrice)
+	}
+	api := NewPrivateDebugAPI(b.eai.chainConfig, b.eai)
+	if err := api.acquireTraceSlot(); err != nil {
+		return nil, err
+	}
+	defer api.releaseTraceSlot()
+
+	tx, blockHash, _, index := rawdb.ReadTransaction(b.eai.chainDb, txHash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %x not found", txHash)
+	}
+	msg, vmctx, statedb, err := api.computeTxEnv(ctx, blockHash, int(index), defaultTraceReexec)
+	if err != nil {
+		return nil, err
+	}
+	msg = types.NewMessage(msg.From(), msg.To(), msg.Nonce(), msg.Value(), msg.Gas(), newGasPrice, msg.Data(), msg.CheckNonce())
+	vmctx.GasPrice = new(big.Int).Set(newGasPrice)
+
+	vmenv := vm.NewEVM(vmctx, statedb, b.eai.chainConfig, vm.Config{})
+
+	// Abort the replay if the RPC request is cancelled
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			vmenv.Cancel()
+		case <-done:
+		}
+	}()
+	ret, gas, failed, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas()))
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("replay failed: %v", err)
+	}
+	return &eaiapi.ExecutionResult{
+		Gas:         gas,
+		Failed:      failed,
+		ReturnValue: fmt.Sprintf("%x", ret),
+	}, nil
+}
+
+// InternalCallGas re-executes a mined transaction with a call recording tracer,
+// returning every internal CALL, CALLCODE, DELEGATECALL and STATICCALL it made
+// along with the gas each of them consumed.
+func (b *EaiAPIBackend) InternalCallGas(ctx context.Context, txHash common.Hash) ([]InternalCall, error) {
+	api := NewPrivateDebugAPI(b.eai.chainConfig, b.eai)
+	if err := api.acquireTraceSlot(); err != nil {
+		return nil, err
+	}
+	defer api.releaseTraceSlot()
+
+	tx, blockHash, _, index := rawdb.ReadTransaction(b.eai.chainDb, txHash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %x not found", txHash)
+	}
+	msg, vmctx, statedb, err := api.computeTxEnv(ctx, blockHash, int(index), defaultTraceReexec)
+	if err != nil {
+		return nil, err
+	}
+	tracer := new(callGasTracer)
+	vmenv := vm.NewEVM(vmctx, statedb, b.eai.chainConfig, vm.Config{Debug: true, Tracer: tracer})
+	if _, _, _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas())); err != nil {
+		return nil, fmt.Errorf("tracing failed: %v", err)
+	}
+	if tracer.calls == nil {
+		return []InternalCall{}, nil
+	}
+	return tracer.calls, nil
+}
+
+// CalldataGasCost returns the number of zero and non-zero bytes in the payload of
+// a transaction, along with the intrinsic gas it is billed for under the rules of
+// the fork active at the current head. Should the gas overflow, it is capped at
+// the maximum uint64 value.
+func (b *EaiAPIBackend) CalldataGasCost(tx *types.Transaction) (zeroBytes, nonZeroBytes int, totalGas uint64) {
+	for _, byt := range tx.Data() {
+		if byt == 0 {
+			zeroBytes++
+		} else {
+			nonZeroBytes++
+		}
+	}
+	homestead := b.eai.chainConfig.IsHomestead(b.eai.blockchain.CurrentBlock().Number())
+
+	gas, err := core.IntrinsicGas(tx.Data(), tx.To() == nil, homestead)
+	if err != nil {
+		gas = math.MaxUint64
+	}
+	return zeroBytes, nonZeroBytes, gas
+}
+
 func (b *EaiAPIBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
 	return b.eai.txPool.State().GetNonce(addr), nil
 }
@@ -204,6 +512,19 @@ func (b *EaiAPIBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	return b.gpo.SuggestPrice(ctx)
 }
 
+// SuggestPriceForPercentile returns the gas price at the given percentile of the
+// prices paid in the recent blocks, instead of the configured default percentile.
+func (b *EaiAPIBackend) SuggestPriceForPercentile(ctx context.Context, pct int) (*big.Int, error) {
+	return b.gpo.SuggestPriceForPercentile(ctx, pct)
+}
+
+// FeeHistory returns the gas used ratio of each of the given number of blocks
+// ending with lastBlock, along with the gas prices paid at the given percentiles
+// of the gas used in them.
+func (b *EaiAPIBackend) FeeHistory(ctx context.Context, blocks int, lastBlock rpc.BlockNumber, percentiles []float64) (*gasprice.FeeHistory, error) {
+	return b.gpo.FeeHistory(ctx, blocks, lastBlock, percentiles)
+}
+
 func (b *EaiAPIBackend) ChainDb() eaidb.Database {
 	return b.eai.ChainDb()
 }
@@ -221,8 +542,480 @@ func (b *EaiAPIBackend) BloomStatus() (uint64, uint64) {
 	return params.BloomBitsBlocks, sections
 }
 
+// BloomProgress returns the number of blocks covered by the bloom bits index and
+// the number of the current head block.
+func (b *EaiAPIBackend) BloomProgress() (indexed uint64, head uint64) {
+	size, sections := b.BloomStatus()
+	return size * sections, b.eai.blockchain.CurrentHeader().Number.Uint64()
+}
+
 func (b *EaiAPIBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	for i := 0; i < bloomFilterThreads; i++ {
 		go session.Multiplex(bloomRetrievalBatch, bloomRetrievalWait, b.eai.bloomRequests)
 	}
 }
+
+// EpochInfo describes where the current chain head sits within the epoch of
+// the active consensus engine.
+type EpochInfo struct {
+	Engine          string         `json:"engine"`
+	Epoch           hexutil.Uint64 `json:"epoch"`
+	EpochLength     hexutil.Uint64 `json:"epochLength"`
+	NextEpochBlock  hexutil.Uint64 `json:"nextEpochBlock"`
+	BlocksRemaining hexutil.Uint64 `json:"blocksRemaining"`
+}
+
+// NextEpochInfo reports the number of blocks left until the next epoch boundary.
+// For clique this is the next signer checkpoint, for eaiash the next DAG switch.
+func (b *EaiAPIBackend) NextEpochInfo(ctx context.Context) (*EpochInfo, error) {
+	engine, length := "eaiash", uint64(eaiash.EpochLength)
+	if config := b.eai.chainConfig.Clique; config != nil {
+		engine, length = "clique", config.Epoch
+		if length == 0 {
+			length = clique.DefaultEpochLength
+		}
+	}
+	head := b.eai.blockchain.CurrentBlock().NumberU64()
+	epoch := head / length
+	next := (epoch + 1) * length
+
+	return &EpochInfo{
+		Engine:          engine,
+		Epoch:           hexutil.Uint64(epoch),
+		EpochLength:     hexutil.Uint64(length),
+		NextEpochBlock:  hexutil.Uint64(next),
+		BlocksRemaining: hexutil.Uint64(next - head),
+	}, nil
+}
+
+// CumulativeGasBefore returns the total gas used by the transactions preceding
+// the given one within its block, as recorded in the block's receipts.
+func (b *EaiAPIBackend) CumulativeGasBefore(ctx context.Context, txHash common.Hash) (uint64, error) {
+	blockHash, blockNumber, index := rawdb.ReadTxLookupEntry(b.eai.chainDb, txHash)
+	if blockHash == (common.Hash{}) {
+		return 0, fmt.Errorf("transaction %x not mined", txHash)
+	}
+	if index == 0 {
+		return 0, nil
+	}
+	receipts := rawdb.ReadReceipts(b.eai.chainDb, blockHash, blockNumber)
+	if uint64(len(receipts)) < index {
+		return 0, fmt.Errorf("receipts for block %x not found", blockHash)
+	}
+	return receipts[index-1].CumulativeGasUsed, nil
+}
+
+// BlockForLog returns the block containing the log emitted by the given
+// transaction at the given block-wide log index, as reported by log filters.
+// The index is validated against the logs of the transaction's receipt.
+func (b *EaiAPIBackend) BlockForLog(ctx context.Context, txHash common.Hash, logIndex uint) (*types.Block, error) {
+	blockHash, blockNumber, index := rawdb.ReadTxLookupEntry(b.eai.chainDb, txHash)
+	if blockHash == (common.Hash{}) {
+		return nil, fmt.Errorf("transaction %x not mined", txHash)
+	}
+	receipts := rawdb.ReadReceipts(b.eai.chainDb, blockHash, blockNumber)
+	if uint64(len(receipts)) <= index {
+		return nil, fmt.Errorf("receipts for block %x not found", blockHash)
+	}
+	found := false
+	for _, log := range receipts[index].Logs {
+		if log.Index == logIndex {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("transaction %x has no log with index %d", txHash, logIndex)
+	}
+	block := b.eai.blockchain.GetBlock(blockHash, blockNumber)
+	if block == nil {
+		return nil, fmt.Errorf("block %x not found", blockHash)
+	}
+	return block, nil
+}
+
+// maxBalanceHistoryBlocks is the maximum number of blocks a single BalanceHistory
+// request may query, bounding the historical states a remote caller can open.
+const maxBalanceHistoryBlocks = 256
+
+// BalanceHistory returns the balance of the account at each of the requested
+// canonical blocks. Blocks whose state is no longer available (e.g. pruned) are
+// marked with a nil entry instead of failing the whole request.
+func (b *EaiAPIBackend) BalanceHistory(ctx context.Context, addr common.Address, blocks []uint64) ([]*big.Int, error) {
+	if len(blocks) > maxBalanceHistoryBlocks {
+		return nil, fmt.Errorf("requested %d blocks, limit is %d", len(blocks), maxBalanceHistoryBlocks)
+	}
+	head := b.eai.blockchain.CurrentBlock().NumberU64()
+
+	balances := make([]*big.Int, len(blocks))
+	for i, number := range blocks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if number > head {
+			return nil, fmt.Errorf("block %d beyond current head %d", number, head)
+		}
+		header := b.eai.blockchain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		statedb, err := b.eai.blockchain.StateAt(header.Root)
+		if err != nil {
+			continue
+		}
+		balance := statedb.GetBalance(addr)
+		if err := statedb.Error(); err != nil {
+			// Partially pruned states lack the account's trie path, anything else is fatal
+			if _, ok := err.(*trie.MissingNodeError); ok {
+				continue
+			}
+			return nil, err
+		}
+		balances[i] = balance
+	}
+	return balances, nil
+}
+
+// maxBlocksMinedByRange is the maximum number of headers a single BlocksMinedBy
+// request may scan, bounding the work a remote caller can trigger.
+const maxBlocksMinedByRange = 8192
+
+// BlocksMinedBy returns the numbers of the canonical blocks within the inclusive
+// range [from, to] whose coinbase is the given address. The bloom bits index does
+// not cover coinbases, so the headers in the range are scanned one by one.
+func (b *EaiAPIBackend) BlocksMinedBy(ctx context.Context, miner common.Address, from, to uint64) ([]uint64, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid range: start block %d after end block %d", from, to)
+	}
+	if to-from >= maxBlocksMinedByRange {
+		return nil, fmt.Errorf("range %d-%d exceeds limit of %d blocks", from, to, maxBlocksMinedByRange)
+	}
+	if head := b.eai.blockchain.CurrentHeader().Number.Uint64(); to > head {
+		return nil, fmt.Errorf("end block %d beyond current head %d", to, head)
+	}
+	mined := []uint64{}
+	for number := from; number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		header := b.eai.blockchain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		if header.Coinbase == miner {
+			mined = append(mined, number)
+		}
+	}
+	return mined, nil
+}
+
+// TotalTransactions returns the cumulative number of transactions in the chain up
+// to and including the given block.
+func (b *EaiAPIBackend) TotalTransactions(ctx context.Context, blockNr rpc.BlockNumber) (uint64, error) {
+	header, err := b.HeaderByNumber(ctx, blockNr)
+	if err != nil {
+		return 0, err
+	}
+	if header == nil {
+		return 0, fmt.Errorf("block #%d not found", blockNr)
+	}
+	return b.eai.blockchain.GetTxCount(header.Hash(), header.Number.Uint64())
+}
+
+// HashrateForBlockTime projects the network hashrate (in hashes per second) needed
+// to seal blocks at the current difficulty with the given average block time. On
+// eaiash chains a block takes difficulty hashes on average to find.
+func (b *EaiAPIBackend) HashrateForBlockTime(targetSeconds float64) (*big.Int, error) {
+	if b.eai.chainConfig.Clique != nil {
+		return nil, errors.New("hashrate projections require a proof-of-work chain")
+	}
+	if !(targetSeconds > 0) { // Negated to also reject NaN
+		return nil, fmt.Errorf("invalid target block time %v", targetSeconds)
+	}
+	difficulty := new(big.Float).SetInt(b.eai.blockchain.CurrentBlock().Difficulty())
+	hashrate, _ := difficulty.Quo(difficulty, big.NewFloat(targetSeconds)).Int(nil)
+	return hashrate, nil
+}
+
+// BlockTimeForHashrate is the inverse of HashrateForBlockTime, projecting the
+// average block time (in seconds) at the current difficulty if the network
+// hashes with the given hashrate.
+func (b *EaiAPIBackend) BlockTimeForHashrate(hashrate *big.Int) (float64, error) {
+	if b.eai.chainConfig.Clique != nil {
+		return 0, errors.New("block time projections require a proof-of-work chain")
+	}
+	if hashrate == nil || hashrate.Sign() <= 0 {
+		return 0, fmt.Errorf("invalid hashrate %v", hashrate)
+	}
+	difficulty := new(big.Float).SetInt(b.eai.blockchain.CurrentBlock().Difficulty())
+	seconds, _ := difficulty.Quo(difficulty, new(big.Float).SetInt(hashrate)).Float64()
+	return seconds, nil
+}
+
+// minHashrateSampleBlocks is the fewest blocks NetworkHashrate averages over, as
+// the block times of a handful of blocks are too noisy for a meaningful estimate.
+const minHashrateSampleBlocks = 4
+
+// NetworkHashrate estimates the total hashrate (in hashes per second) of the
+// network from the difficulty of the last sampleBlocks canonical blocks and the
+// time it took to mine them. On eaiash chains a block takes difficulty hashes on
+// average to find.
+func (b *EaiAPIBackend) NetworkHashrate(ctx context.Context, sampleBlocks uint64) (*big.Int, error) {
+	if b.eai.chainConfig.Clique != nil {
+		return nil, errors.New("hashrate estimates require a proof-of-work chain")
+	}
+	if sampleBlocks < minHashrateSampleBlocks {
+		return nil, fmt.Errorf("too few sample blocks: have %d, want at least %d", sampleBlocks, minHashrateSampleBlocks)
+	}
+	head := b.eai.blockchain.CurrentHeader()
+	if number := head.Number.Uint64(); number < sampleBlocks {
+		return nil, fmt.Errorf("chain too short: have %d blocks, want at least %d", number, sampleBlocks)
+	}
+	// Sum up the work of the sampled blocks, walking back to the parent of the
+	// oldest one whose timestamp marks the start of the sampled period
+	var (
+		work   = new(big.Int)
+		header = head
+	)
+	for i := uint64(0); i < sampleBlocks; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		work.Add(work, header.Difficulty)
+		if header = b.eai.blockchain.GetHeader(header.ParentHash, header.Number.Uint64()-1); header == nil {
+			return nil, fmt.Errorf("block #%d not found", head.Number.Uint64()-i-1)
+		}
+	}
+	elapsed := new(big.Int).Sub(head.Time, header.Time)
+	if elapsed.Sign() <= 0 {
+		return nil, fmt.Errorf("no time elapsed over the last %d blocks", sampleBlocks)
+	}
+	return work.Div(work, elapsed), nil
+}
+
+// defaultDifficultyAnomalyThreshold is the relative difficulty change between two
+// consecutive blocks flagged by DifficultyAnomalies if not configured otherwise.
+// The eaiash adjustment rules alone move the difficulty by less than 5%.
+const defaultDifficultyAnomalyThreshold = 0.05
+
+// DifficultyAnomalies returns the numbers of the canonical blocks within [from, to]
+// whose difficulty differs from their parent's by more than the configured
+// threshold, hinting at timestamp manipulation or sudden hashrate swings.
+func (b *EaiAPIBackend) DifficultyAnomalies(ctx context.Context, from, to uint64) ([]uint64, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid block range [%d, %d]", from, to)
+	}
+	threshold := b.eai.config.DifficultyAnomalyThreshold
+	if threshold <= 0 {
+		threshold = defaultDifficultyAnomalyThreshold
+	}
+	if from == 0 {
+		from = 1 // The genesis block has no parent to compare against
+	}
+	parent := b.eai.blockchain.GetHeaderByNumber(from - 1)
+	if parent == nil {
+		return nil, fmt.Errorf("block #%d not found", from-1)
+	}
+	var anomalies []uint64
+	for number := from; number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		header := b.eai.blockchain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		delta := new(big.Int).Sub(header.Difficulty, parent.Difficulty)
+		change, _ := new(big.Float).Quo(new(big.Float).SetInt(delta.Abs(delta)), new(big.Float).SetInt(parent.Difficulty)).Float64()
+		if change > threshold {
+			anomalies = append(anomalies, number)
+		}
+		parent = header
+	}
+	return anomalies, nil
+}
+
+// UncleRewards sums the rewards the given address earned through uncles within
+// the canonical blocks [from, to], both for mining the uncles and for including
+// them in its blocks.
+func (b *EaiAPIBackend) UncleRewards(ctx context.Context, miner common.Address, from, to uint64) (*big.Int, error) {
+	if b.eai.chainConfig.Clique != nil {
+		return nil, errors.New("uncle rewards require a proof-of-work chain")
+	}
+	if from > to {
+		return nil, fmt.Errorf("invalid block range [%d, %d]", from, to)
+	}
+	total := new(big.Int)
+	for number := from; number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block := b.eai.blockchain.GetBlockByNumber(number)
+		if block == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		header := block.Header()
+		for _, uncle := range block.Uncles() {
+			if uncle.Coinbase == miner {
+				total.Add(total, eaiash.UncleReward(b.eai.chainConfig, header, uncle))
+			}
+			if header.Coinbase == miner {
+				total.Add(total, eaiash.UncleInclusionReward(b.eai.chainConfig, header))
+			}
+		}
+	}
+	return total, nil
+}
+
+// PendingByGasPrice returns the pending transactions of the pool whose gas price
+// lies within [min, max], ordered by gas price with the highest paying first. A
+// nil bound leaves that side of the range open.
+func (b *EaiAPIBackend) PendingByGasPrice(min, max *big.Int) (types.Transactions, error) {
+	if min != nil && max != nil && min.Cmp(max) > 0 {
+		return nil, fmt.Errorf("empty gas price range [%v, %v]", min, max)
+	}
+	pending, err := b.eai.txPool.Pending()
+	if err != nil {
+		return nil, err
+	}
+	var txs types.Transactions
+	for _, batch := range pending {
+		for _, tx := range batch {
+			price := tx.GasPrice()
+			if (min != nil && price.Cmp(min) < 0) || (max != nil && price.Cmp(max) > 0) {
+				continue
+			}
+			txs = append(txs, tx)
+		}
+	}
+	sort.Stable(types.TxByPrice(txs))
+	return txs, nil
+}
+
+// confirmationSampleBlocks is the number of recent blocks ConfirmationEstimate
+// averages the block time over.
+const confirmationSampleBlocks = 64
+
+// ConfirmationEstimate estimates how many blocks, and how much time, a transaction
+// paying the given gas price would wait to be mined. All pending transactions
+// paying at least as much are assumed to go first, filling the blocks up to the
+// current gas limit, with blocks arriving at the recently observed rate.
+func (b *EaiAPIBackend) ConfirmationEstimate(ctx context.Context, gasPrice *big.Int) (blocks int, duration time.Duration, err error) {
+	if gasPrice == nil {
+		return 0, 0, errors.New("missing gas price")
+	}
+	head := b.eai.blockchain.CurrentHeader()
+	if head.GasLimit == 0 {
+		return 0, 0, errors.New("head block has no gas limit to fill")
+	}
+	if min := b.eai.txPool.GasPrice(); gasPrice.Cmp(min) < 0 {
+		return 0, 0, fmt.Errorf("gas price %v below the pool minimum %v", gasPrice, min)
+	}
+	if pending, _ := b.eai.txPool.Stats(); pending == 0 {
+		return 0, 0, errors.New("no pending transactions to estimate from")
+	}
+	ahead, err := b.PendingByGasPrice(gasPrice, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	var gas uint64
+	for _, tx := range ahead {
+		gas += tx.Gas()
+	}
+	// Average the block time over the recent blocks
+	sample := head.Number.Uint64()
+	if sample > confirmationSampleBlocks {
+		sample = confirmationSampleBlocks
+	}
+	if sample == 0 {
+		return 0, 0, errors.New("no blocks to measure the block time from")
+	}
+	ancestor := b.eai.blockchain.GetHeaderByNumber(head.Number.Uint64() - sample)
+	if ancestor == nil {
+		return 0, 0, fmt.Errorf("block #%d not found", head.Number.Uint64()-sample)
+	}
+	blockTime := time.Duration(new(big.Int).Sub(head.Time, ancestor.Time).Int64()) * time.Second / time.Duration(sample)
+
+	blocks = int(gas/head.GasLimit) + 1
+	return blocks, time.Duration(blocks) * blockTime, nil
+}
+
+// throughputSampleBlocks is the number of recent blocks ThroughputCapacity takes
+// the average transaction gas and block time from.
+const throughputSampleBlocks = 64
+
+// ThroughputCapacity estimates the sustainable transaction throughput of the
+// chain: blocks filled up to the current gas limit with transactions using the
+// average gas of the ones recently mined, arriving at the recently observed rate.
+func (b *EaiAPIBackend) ThroughputCapacity(ctx context.Context) (txPerSecond float64, err error) {
+	head := b.eai.blockchain.CurrentBlock()
+	sample := head.NumberU64()
+	if sample > throughputSampleBlocks {
+		sample = throughputSampleBlocks
+	}
+	if sample == 0 {
+		return 0, errors.New("no blocks to measure the throughput from")
+	}
+	var gasUsed, txs uint64
+	for number := head.NumberU64(); number > head.NumberU64()-sample; number-- {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		block := b.eai.blockchain.GetBlockByNumber(number)
+		if block == nil {
+			return 0, fmt.Errorf("block #%d not found", number)
+		}
+		gasUsed += block.GasUsed()
+		txs += uint64(len(block.Transactions()))
+	}
+	if txs == 0 {
+		return 0, fmt.Errorf("no transactions in the last %d blocks", sample)
+	}
+	ancestor := b.eai.blockchain.GetHeaderByNumber(head.NumberU64() - sample)
+	if ancestor == nil {
+		return 0, fmt.Errorf("block #%d not found", head.NumberU64()-sample)
+	}
+	elapsed := new(big.Int).Sub(head.Time(), ancestor.Time).Uint64()
+	if elapsed == 0 {
+		return 0, fmt.Errorf("no time elapsed over the last %d blocks", sample)
+	}
+	var (
+		txGas     = float64(gasUsed) / float64(txs)
+		blockTime = float64(elapsed) / float64(sample)
+	)
+	return float64(head.GasLimit()) / txGas / blockTime, nil
+}
+
+// forkEIPs lists the EIPs introduced by each of the hard forks of the chain config.
+// Constantinople only covers the EIPs implemented by the EVM of this release.
+var forkEIPs = []struct {
+	active func(*params.ChainConfig, *big.Int) bool
+	eips   []int
+}{
+	{(*params.ChainConfig).IsHomestead, []int{2, 7, 8}},
+	{(*params.ChainConfig).IsEIP150, []int{150}},
+	{(*params.ChainConfig).IsEIP155, []int{155}},
+	{(*params.ChainConfig).IsEIP158, []int{160, 161, 170}},
+	{(*params.ChainConfig).IsByzantium, []int{100, 140, 196, 197, 198, 211, 214, 649, 658}},
+	{(*params.ChainConfig).IsConstantinople, []int{145}},
+}
+
+// ActiveEIPs returns the numbers of all the EIPs in effect at the given block as
+// scheduled by the chain config, in increasing order.
+func (b *EaiAPIBackend) ActiveEIPs(ctx context.Context, blockNr rpc.BlockNumber) ([]int, error) {
+	header, err := b.HeaderByNumber(ctx, blockNr)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNr)
+	}
+	eips := []int{}
+	for _, fork := range forkEIPs {
+		if fork.active(b.eai.chainConfig, header.Number) {
+			eips = append(eips, fork.eips...)
+		}
+	}
+	sort.Ints(eips)
+	return eips, nil
+}
@@ -0,0 +1,997 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/common/hexutil"
+	"github.com/ethereumai/go-ethereumai/consensus/clique"
+	"github.com/ethereumai/go-ethereumai/consensus/eaiash"
+	"github.com/ethereumai/go-ethereumai/core"
+	"github.com/ethereumai/go-ethereumai/core/rawdb"
+	"github.com/ethereumai/go-ethereumai/core/types"
+	"github.com/ethereumai/go-ethereumai/core/vm"
+	"github.com/ethereumai/go-ethereumai/crypto"
+	"github.com/ethereumai/go-ethereumai/eai/gasprice"
+	"github.com/ethereumai/go-ethereumai/eaidb"
+	"github.com/ethereumai/go-ethereumai/internal/eaiapi"
+	"github.com/ethereumai/go-ethereumai/params"
+	"github.com/ethereumai/go-ethereumai/rpc"
+	"github.com/ethereumai/go-ethereumai/trie"
+)
+
+// Tests that receipts stored without their derivable fields, as done by older
+// versions, are returned fully populated.
+func TestGetReceiptsLegacy(t *testing.T) {
+	var (
+		backend = newTestBackend(t, core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}})
+		signer  = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+		db      = backend.eai.chainDb
+	)
+	defer backend.eai.blockchain.Stop()
+
+	chain := insertTestChain(t, backend, 1, func(i int, block *core.BlockGen) {
+		// LOG0 of empty memory followed by an empty runtime code
+		create, _ := types.SignTx(types.NewContractCreation(0, new(big.Int), 100000, big.NewInt(1), common.FromHex("0x60006000a0")), signer, testBankKey)
+		transfer, _ := types.SignTx(types.NewTransaction(1, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, testBankKey)
+		block.AddTx(create)
+		block.AddTx(transfer)
+	})
+	block := chain[0]
+	stored := rawdb.ReadReceipts(db, block.Hash(), block.NumberU64())
+
+	// Overwrite the receipts with ones lacking all non-consensus fields
+	legacy := make(types.Receipts, len(stored))
+	for i, receipt := range stored {
+		legacy[i] = &types.Receipt{
+			PostState:         receipt.PostState,
+			Status:            receipt.Status,
+			CumulativeGasUsed: receipt.CumulativeGasUsed,
+			Bloom:             receipt.Bloom,
+			Logs:              []*types.Log{},
+		}
+		for _, log := range receipt.Logs {
+			legacy[i].Logs = append(legacy[i].Logs, &types.Log{Address: log.Address, Topics: log.Topics, Data: log.Data})
+		}
+	}
+	rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), legacy)
+
+	receipts, err := backend.GetReceipts(context.Background(), block.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve receipts: %v", err)
+	}
+	if len(receipts) != 2 {
+		t.Fatalf("receipt count mismatch: have %d, want 2", len(receipts))
+	}
+	for i, receipt := range receipts {
+		tx := block.Transactions()[i]
+		if receipt.TxHash != tx.Hash() {
+			t.Errorf("receipt %d: tx hash mismatch: have %x, want %x", i, receipt.TxHash, tx.Hash())
+		}
+		if receipt.BlockHash != block.Hash() || receipt.BlockNumber.Uint64() != block.NumberU64() || receipt.TransactionIndex != uint(i) {
+			t.Errorf("receipt %d: inclusion mismatch: have %x #%v idx %d", i, receipt.BlockHash, receipt.BlockNumber, receipt.TransactionIndex)
+		}
+		if receipt.GasUsed != stored[i].GasUsed {
+			t.Errorf("receipt %d: gas used mismatch: have %d, want %d", i, receipt.GasUsed, stored[i].GasUsed)
+		}
+		if receipt.ContractAddress != stored[i].ContractAddress {
+			t.Errorf("receipt %d: contract address mismatch: have %x, want %x", i, receipt.ContractAddress, stored[i].ContractAddress)
+		}
+	}
+	if want := crypto.CreateAddress(testBank, 0); receipts[0].ContractAddress != want {
+		t.Errorf("created contract mismatch: have %x, want %x", receipts[0].ContractAddress, want)
+	}
+	if len(receipts[0].Logs) != 1 {
+		t.Fatalf("log count mismatch: have %d, want 1", len(receipts[0].Logs))
+	}
+	if log := receipts[0].Logs[0]; log.BlockHash != block.Hash() || log.TxHash != block.Transactions()[0].Hash() || log.BlockNumber != block.NumberU64() {
+		t.Errorf("log metadata mismatch: %+v", log)
+	}
+}
+
+// Tests that cancelling the context of a call aborts its EVM execution.
+func TestGetEVMCancellation(t *testing.T) {
+	backend := newTestBackend(t, nil)
+	defer backend.eai.blockchain.Stop()
+
+	contract := common.Address{0xc0, 0xde}
+	statedb, _ := backend.eai.blockchain.State()
+	statedb.SetCode(contract, common.FromHex("0x5b600056")) // JUMPDEST PUSH1 0 JUMP
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	msg := types.NewMessage(common.Address{}, &contract, 0, new(big.Int), math.MaxUint64/2, new(big.Int), nil, false)
+	evm, vmError, err := backend.GetEVM(ctx, msg, statedb, backend.eai.blockchain.CurrentHeader(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create EVM: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("execution not aborted")
+	}
+	if err := vmError(); err != context.DeadlineExceeded {
+		t.Errorf("error mismatch: have %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// Tests that account overrides are applied to the state a call is executed on
+// and that conflicting storage overrides are rejected without touching the state.
+func TestStateOverride(t *testing.T) {
+	var (
+		full    = common.Address{0x0a}
+		patched = common.Address{0x0b}
+		slot1   = common.BytesToHash([]byte{0x01})
+		slot2   = common.BytesToHash([]byte{0x02})
+		storage = map[common.Hash]common.Hash{slot1: {0x01}, slot2: {0x02}}
+		backend = newTestBackend(t, core.GenesisAlloc{
+			full:    {Balance: new(big.Int), Storage: storage},
+			patched: {Balance: new(big.Int), Storage: storage},
+		})
+		blockchain = backend.eai.blockchain
+	)
+	defer blockchain.Stop()
+
+	var (
+		balance = (*hexutil.Big)(big.NewInt(1000))
+		nonce   = hexutil.Uint64(7)
+		code    = hexutil.Bytes{0x60, 0x02, 0x54, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3} // return SLOAD(2)
+		state   = map[common.Hash]common.Hash{slot2: {0x05}}
+		diff    = map[common.Hash]common.Hash{slot2: {0x06}}
+	)
+	overrides := &eaiapi.StateOverride{
+		full:    {Balance: balance, Nonce: &nonce, Code: &code, State: &state},
+		patched: {StateDiff: &diff},
+	}
+	statedb, _ := blockchain.State()
+	if err := overrides.Apply(statedb); err != nil {
+		t.Fatalf("failed to apply overrides: %v", err)
+	}
+	msg := types.NewMessage(common.Address{}, &full, 0, new(big.Int), 100000, new(big.Int), nil, false)
+	evm, vmError, err := backend.GetEVM(context.Background(), msg, statedb, blockchain.CurrentHeader(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create EVM: %v", err)
+	}
+	res, _, failed, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+	if err := vmError(); err != nil {
+		t.Fatalf("call aborted: %v", err)
+	}
+	if err != nil || failed {
+		t.Fatalf("call failed: %v", err)
+	}
+	if have := common.BytesToHash(res); have != (common.Hash{0x05}) {
+		t.Errorf("overridden code result mismatch: have %x, want %x", have, common.Hash{0x05})
+	}
+	if have := statedb.GetBalance(full); have.Cmp(balance.ToInt()) != 0 {
+		t.Errorf("balance mismatch: have %v, want %v", have, balance)
+	}
+	if have := statedb.GetNonce(full); have != uint64(nonce) {
+		t.Errorf("nonce mismatch: have %d, want %d", have, nonce)
+	}
+	if have := statedb.GetState(full, slot1); have != (common.Hash{}) {
+		t.Errorf("replaced storage kept slot: have %x", have)
+	}
+	if have := statedb.GetState(patched, slot1); have != (common.Hash{0x01}) {
+		t.Errorf("patched storage lost slot: have %x, want %x", have, common.Hash{0x01})
+	}
+	if have := statedb.GetState(patched, slot2); have != (common.Hash{0x06}) {
+		t.Errorf("patched slot mismatch: have %x, want %x", have, common.Hash{0x06})
+	}
+	// Setting both the full storage and a diff of the same account is ambiguous
+	conflict := &eaiapi.StateOverride{
+		full:    {Balance: balance, Nonce: &nonce, Code: &code},
+		patched: {State: &state, StateDiff: &diff},
+	}
+	statedb, _ = blockchain.State()
+	if err := conflict.Apply(statedb); err == nil {
+		t.Error("conflicting storage overrides accepted")
+	}
+	if have := statedb.GetBalance(full); have.Sign() != 0 {
+		t.Errorf("balance overridden by rejected overrides: have %v", have)
+	}
+	if have := statedb.GetNonce(full); have != 0 {
+		t.Errorf("nonce overridden by rejected overrides: have %d", have)
+	}
+	if have := statedb.GetCode(full); len(have) != 0 {
+		t.Errorf("code overridden by rejected overrides: have %x", have)
+	}
+}
+
+// Tests that the network hashrate is estimated from the work and timespan of the
+// sampled blocks, and that too small samples are rejected.
+func TestNetworkHashrate(t *testing.T) {
+	backend := newTestBackendWithGenesis(t, &core.Genesis{Config: params.TestChainConfig, Difficulty: big.NewInt(1000000)}, nil)
+	defer backend.eai.blockchain.Stop()
+
+	chain := insertTestChain(t, backend, 8, nil)
+
+	work := new(big.Int)
+	for _, block := range chain[3:] {
+		work.Add(work, block.Difficulty())
+	}
+	want := work.Div(work, new(big.Int).Sub(chain[7].Time(), chain[2].Time()))
+
+	hashrate, err := backend.NetworkHashrate(context.Background(), 5)
+	if err != nil {
+		t.Fatalf("failed to estimate hashrate: %v", err)
+	}
+	if hashrate.Cmp(want) != 0 {
+		t.Errorf("hashrate mismatch: have %v, want %v", hashrate, want)
+	}
+	if _, err := backend.NetworkHashrate(context.Background(), minHashrateSampleBlocks-1); err == nil {
+		t.Error("estimate from too few blocks succeeded")
+	}
+	if _, err := backend.NetworkHashrate(context.Background(), 9); err == nil {
+		t.Error("estimate beyond the genesis block succeeded")
+	}
+}
+
+// Tests that blocks whose difficulty deviates too much from their parent's are
+// reported as anomalies.
+func TestDifficultyAnomalies(t *testing.T) {
+	backend := newTestBackendWithGenesis(t, &core.Genesis{Config: params.TestChainConfig, Difficulty: big.NewInt(1000000)}, nil)
+	defer backend.eai.blockchain.Stop()
+
+	// Stall block 5 long enough for the difficulty to drop by the maximum amount
+	insertTestChain(t, backend, 8, func(i int, block *core.BlockGen) {
+		if i == 4 {
+			block.OffsetTime(1000)
+		}
+	})
+	anomalies, err := backend.DifficultyAnomalies(context.Background(), 0, 8)
+	if err != nil {
+		t.Fatalf("failed to find anomalies: %v", err)
+	}
+	if len(anomalies) != 0 {
+		t.Errorf("anomalies below the default threshold reported: %v", anomalies)
+	}
+	backend.eai.config.DifficultyAnomalyThreshold = 0.01
+	if anomalies, err = backend.DifficultyAnomalies(context.Background(), 0, 8); err != nil {
+		t.Fatalf("failed to find anomalies: %v", err)
+	}
+	if len(anomalies) != 1 || anomalies[0] != 5 {
+		t.Errorf("anomalies mismatch: have %v, want [5]", anomalies)
+	}
+	if _, err := backend.DifficultyAnomalies(context.Background(), 5, 9); err == nil {
+		t.Error("range beyond the head succeeded")
+	}
+}
+
+// Tests that the rewards for mining and including uncles are attributed to the
+// right miners.
+func TestUncleRewards(t *testing.T) {
+	var (
+		backend = newTestBackend(t, nil)
+		miner   = common.Address{0x01}
+		uncler  = common.Address{0x02}
+	)
+	defer backend.eai.blockchain.Stop()
+
+	side := generateTestChain(backend, 1, func(i int, block *core.BlockGen) {
+		block.SetCoinbase(uncler)
+	})
+	insertTestChain(t, backend, 3, func(i int, block *core.BlockGen) {
+		block.SetCoinbase(miner)
+		if i == 1 {
+			block.AddUncle(side[0].Header())
+		}
+	})
+	// Uncle #1 included in block #2 earns 7/8 of the block reward, its includer 1/32
+	tests := []struct {
+		addr     common.Address
+		from, to uint64
+		want     *big.Int
+	}{
+		{miner, 0, 3, new(big.Int).Div(eaiash.ByzantiumBlockReward, big.NewInt(32))},
+		{uncler, 0, 3, new(big.Int).Div(new(big.Int).Mul(eaiash.ByzantiumBlockReward, big.NewInt(7)), big.NewInt(8))},
+		{miner, 3, 3, new(big.Int)},
+		{testBank, 0, 3, new(big.Int)},
+	}
+	for i, tt := range tests {
+		reward, err := backend.UncleRewards(context.Background(), tt.addr, tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("test %d: failed to sum uncle rewards: %v", i, err)
+		}
+		if reward.Cmp(tt.want) != 0 {
+			t.Errorf("test %d: reward mismatch: have %v, want %v", i, reward, tt.want)
+		}
+	}
+	if _, err := backend.UncleRewards(context.Background(), miner, 0, 4); err == nil {
+		t.Error("range beyond the head succeeded")
+	}
+}
+
+// Tests that header chain segments are returned linked and that invalid or
+// oversized ranges are rejected.
+func TestHeaderChain(t *testing.T) {
+	backend := newTestBackend(t, nil)
+	defer backend.eai.blockchain.Stop()
+
+	chain := insertTestChain(t, backend, 5, nil)
+
+	headers, err := backend.HeaderChain(context.Background(), 1, 5)
+	if err != nil {
+		t.Fatalf("failed to retrieve header chain: %v", err)
+	}
+	if len(headers) != 5 {
+		t.Fatalf("header count mismatch: have %d, want %d", len(headers), 5)
+	}
+	for i, header := range headers {
+		if hash := chain[i].Hash(); header.Hash() != hash {
+			t.Errorf("header %d: hash mismatch: have %x, want %x", i, header.Hash(), hash)
+		}
+	}
+	for i, r := range [][2]uint64{{3, 2}, {0, 6}, {0, eaiapi.MaxHeaderChainRange}} {
+		if _, err := backend.HeaderChain(context.Background(), r[0], r[1]); err == nil {
+			t.Errorf("test %d: invalid range %d-%d succeeded", i, r[0], r[1])
+		}
+	}
+	// The same range should be served over the API
+	api := eaiapi.NewPublicBlockChainAPI(backend)
+	if served, err := api.GetHeaderChain(context.Background(), 1, 5); err != nil || len(served) != len(headers) {
+		t.Fatalf("API header chain mismatch: have %d headers, %v, want %d", len(served), err, len(headers))
+	}
+}
+
+// Tests that the throughput capacity is derived from the average gas of recent
+// transactions, the gas limit and the block time.
+func TestThroughputCapacity(t *testing.T) {
+	var (
+		backend    = newTestBackend(t, core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}})
+		signer     = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+		blockchain = backend.eai.blockchain
+	)
+	defer blockchain.Stop()
+
+	if _, err := backend.ThroughputCapacity(context.Background()); err == nil {
+		t.Fatalf("capacity without blocks succeeded")
+	}
+	chain := generateTestChain(backend, 4, func(i int, block *core.BlockGen) {
+		if i == 2 {
+			for j := 0; j < 2; j++ {
+				tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testBank), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, testBankKey)
+				block.AddTx(tx)
+			}
+		}
+	})
+	if _, err := blockchain.InsertChain(chain[:2]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if _, err := backend.ThroughputCapacity(context.Background()); err == nil {
+		t.Fatalf("capacity without transactions succeeded")
+	}
+	if _, err := blockchain.InsertChain(chain[2:]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	// Generated blocks are 10 seconds apart
+	tps, err := backend.ThroughputCapacity(context.Background())
+	if err != nil {
+		t.Fatalf("failed to estimate capacity: %v", err)
+	}
+	if want := float64(blockchain.CurrentBlock().GasLimit()) / 21000 / 10; math.Abs(tps-want) > 1e-9 {
+		t.Errorf("capacity mismatch: have %v, want %v", tps, want)
+	}
+}
+
+// Tests that confirmation estimates account for the better paying transactions
+// waiting in the pool and the observed block time.
+func TestConfirmationEstimate(t *testing.T) {
+	var (
+		backend = newTestBackend(t, core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}})
+		signer  = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+	)
+	defer backend.eai.blockchain.Stop()
+
+	insertTestChain(t, backend, 4, nil)
+	pool := newTestTxPool(backend)
+	defer pool.Stop()
+
+	if _, _, err := backend.ConfirmationEstimate(context.Background(), big.NewInt(10)); err == nil {
+		t.Fatalf("estimate from empty pool succeeded")
+	}
+	// Queue up enough gas at a price of 10 to fill more than a block
+	limit := backend.eai.blockchain.CurrentHeader().GasLimit
+	for i := uint64(0); i < 3; i++ {
+		tx, _ := types.SignTx(types.NewTransaction(i, common.Address{0x01}, big.NewInt(1), limit/2, big.NewInt(10), nil), signer, testBankKey)
+		if err := pool.AddLocal(tx); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	tests := []struct {
+		price    int64
+		blocks   int
+		duration time.Duration
+	}{
+		{5, 2, 20 * time.Second},
+		{10, 2, 20 * time.Second},
+		{11, 1, 10 * time.Second},
+	}
+	for i, tt := range tests {
+		blocks, duration, err := backend.ConfirmationEstimate(context.Background(), big.NewInt(tt.price))
+		if err != nil {
+			t.Fatalf("test %d: failed to estimate: %v", i, err)
+		}
+		if blocks != tt.blocks || duration != tt.duration {
+			t.Errorf("test %d: estimate mismatch: have %d blocks/%v, want %d blocks/%v", i, blocks, duration, tt.blocks, tt.duration)
+		}
+	}
+	if _, _, err := backend.ConfirmationEstimate(context.Background(), big.NewInt(0)); err == nil {
+		t.Errorf("estimate below the pool minimum succeeded")
+	}
+	if _, _, err := backend.ConfirmationEstimate(context.Background(), nil); err == nil {
+		t.Errorf("estimate without a gas price succeeded")
+	}
+}
+
+// Tests that confirmation estimates on a chain without a gas limit fail instead
+// of dividing by zero.
+func TestConfirmationEstimateNoGasLimit(t *testing.T) {
+	// Commit a genesis block without a gas limit by hand, Genesis defaults it
+	db := eaidb.NewMemDatabase()
+	genesis := types.NewBlock(&types.Header{Difficulty: big.NewInt(1), Root: types.EmptyRootHash}, nil, nil, nil)
+	rawdb.WriteTd(db, genesis.Hash(), 0, genesis.Difficulty())
+	rawdb.WriteBlock(db, genesis)
+	rawdb.WriteCanonicalHash(db, genesis.Hash(), 0)
+	rawdb.WriteHeadBlockHash(db, genesis.Hash())
+	rawdb.WriteHeadHeaderHash(db, genesis.Hash())
+	rawdb.WriteChainConfig(db, genesis.Hash(), params.TestChainConfig)
+
+	backend := openTestBackend(t, db, params.TestChainConfig, nil)
+	defer backend.eai.blockchain.Stop()
+
+	pool := newTestTxPool(backend)
+	defer pool.Stop()
+
+	if _, _, err := backend.ConfirmationEstimate(context.Background(), big.NewInt(10)); err == nil || !strings.Contains(err.Error(), "gas limit") {
+		t.Errorf("error mismatch: have %v, want gas limit error", err)
+	}
+}
+
+// Tests that the senders of pending transactions are reported once each.
+func TestPendingAccounts(t *testing.T) {
+	var (
+		key, _   = crypto.GenerateKey()
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		backend  = newTestBackend(t, core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}, addr: {Balance: big.NewInt(1000000000)}})
+		signer   = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+		bankTxs  = 3
+		otherTxs = 1
+	)
+	defer backend.eai.blockchain.Stop()
+
+	pool := newTestTxPool(backend)
+	defer pool.Stop()
+
+	for i := 0; i < bankTxs; i++ {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, testBankKey)
+		pool.AddLocal(tx)
+	}
+	for i := 0; i < otherTxs; i++ {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+		pool.AddLocal(tx)
+	}
+	// Queued transactions don't count as pending
+	gapped, _ := types.SignTx(types.NewTransaction(uint64(otherTxs+1), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+	pool.AddLocal(gapped)
+
+	api := eaiapi.NewPublicTxPoolAPI(backend)
+	result, err := api.PendingAccounts()
+	if err != nil {
+		t.Fatalf("failed to list pending accounts: %v", err)
+	}
+	accounts, count := result["accounts"].([]common.Address), result["count"].(hexutil.Uint)
+	if int(count) != bankTxs+otherTxs {
+		t.Errorf("pending count mismatch: have %d, want %d", count, bankTxs+otherTxs)
+	}
+	if len(accounts) != 2 || (accounts[0] != testBank && accounts[0] != addr) || accounts[0] == accounts[1] {
+		t.Errorf("pending accounts mismatch: have %x, want %x and %x", accounts, testBank, addr)
+	}
+}
+
+// Tests that receipts resolved by block number match the ones resolved by hash.
+func TestGetReceiptsByNumber(t *testing.T) {
+	var (
+		backend = newTestBackend(t, core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}})
+		signer  = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+	)
+	defer backend.eai.blockchain.Stop()
+
+	chain := insertTestChain(t, backend, 2, func(i int, block *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, testBankKey)
+		block.AddTx(tx)
+	})
+	for number, block := range map[rpc.BlockNumber]*types.Block{1: chain[0], rpc.LatestBlockNumber: chain[1]} {
+		want, _ := backend.GetReceipts(context.Background(), block.Hash())
+		have, err := backend.GetReceiptsByNumber(context.Background(), number)
+		if err != nil {
+			t.Fatalf("block %d: failed to retrieve receipts: %v", number, err)
+		}
+		if len(have) != 1 || !reflect.DeepEqual(have, want) {
+			t.Errorf("block %d: receipts mismatch: have %s, want %s", number, dumper.Sdump(have), dumper.Sdump(want))
+		}
+	}
+	if receipts, err := backend.GetReceiptsByNumber(context.Background(), 3); receipts != nil || err != nil {
+		t.Errorf("unknown block: have %v, %v, want nil", receipts, err)
+	}
+}
+
+// Tests that transactions are replayed with the requested gas price.
+func TestReplayWithGasPrice(t *testing.T) {
+	var (
+		backend = newTestBackend(t, core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}})
+		signer  = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+		tx, _   = types.SignTx(types.NewTransaction(0, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, testBankKey)
+	)
+	defer backend.eai.blockchain.Stop()
+
+	insertTestChain(t, backend, 1, func(i int, block *core.BlockGen) {
+		block.AddTx(tx)
+	})
+	result, err := backend.ReplayWithGasPrice(context.Background(), tx.Hash(), big.NewInt(1000))
+	if err != nil {
+		t.Fatalf("failed to replay transaction: %v", err)
+	}
+	if result.Failed || result.Gas != 21000 {
+		t.Errorf("result mismatch: have %+v, want success using 21000 gas", result)
+	}
+	// A gas price the sender can't afford must fail the replay
+	if _, err := backend.ReplayWithGasPrice(context.Background(), tx.Hash(), big.NewInt(1000000)); err == nil {
+		t.Error("replay with unaffordable gas price succeeded")
+	}
+	// Replays must be cancellable and count against the trace limit
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := backend.ReplayWithGasPrice(ctx, tx.Hash(), big.NewInt(1000)); err != context.Canceled {
+		t.Errorf("cancelled replay error mismatch: have %v, want %v", err, context.Canceled)
+	}
+	backend.eai.traceSlots = make(chan struct{}, 1)
+	backend.eai.traceSlots <- struct{}{}
+	if _, err := backend.ReplayWithGasPrice(context.Background(), tx.Hash(), big.NewInt(1000)); err != errTooManyTraces {
+		t.Errorf("replay over the trace limit error mismatch: have %v, want %v", err, errTooManyTraces)
+	}
+}
+
+// Tests that internal calls are recorded in order with the gas consumed by each.
+func TestInternalCallGas(t *testing.T) {
+	var (
+		caller = common.Address{0x0a}
+		store  = common.Address{0x0b}
+		noop   = common.Address{0x0c}
+	)
+	// The caller CALLs a contract storing a slot, then STATICCALLs an empty one
+	code := []byte{0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x73}
+	code = append(code, store.Bytes()...)
+	code = append(code, 0x61, 0xff, 0xff, 0xf1, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x73)
+	code = append(code, noop.Bytes()...)
+	code = append(code, 0x61, 0xff, 0xff, 0xfa, 0x00)
+
+	var (
+		backend = newTestBackend(t, core.GenesisAlloc{
+			testBank: {Balance: big.NewInt(1000000000)},
+			caller:   {Balance: new(big.Int), Code: code},
+			store:    {Balance: new(big.Int), Code: []byte{0x60, 0x01, 0x60, 0x00, 0x55, 0x00}},
+			noop:     {Balance: new(big.Int), Code: []byte{0x00}},
+		})
+		signer = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+		tx, _  = types.SignTx(types.NewTransaction(0, caller, new(big.Int), 100000, big.NewInt(1), nil), signer, testBankKey)
+	)
+	defer backend.eai.blockchain.Stop()
+
+	insertTestChain(t, backend, 1, func(i int, block *core.BlockGen) {
+		block.AddTx(tx)
+	})
+	calls, err := backend.InternalCallGas(context.Background(), tx.Hash())
+	if err != nil {
+		t.Fatalf("failed to trace internal calls: %v", err)
+	}
+	want := []InternalCall{
+		{Type: "CALL", Depth: 1, From: caller, To: store, Value: new(hexutil.Big), GasUsed: 700 + 20006},
+		{Type: "STATICCALL", Depth: 1, From: caller, To: noop, Value: new(hexutil.Big), GasUsed: 700},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("internal calls mismatch:\nhave %v\nwant %v", dumper.Sdump(calls), dumper.Sdump(want))
+	}
+	if _, err := backend.InternalCallGas(context.Background(), common.Hash{0x01}); err == nil {
+		t.Error("tracing an unknown transaction succeeded")
+	}
+}
+
+// Tests that the fee history reports the gas usage and the percentiles of the
+// prices paid in each block.
+func TestFeeHistory(t *testing.T) {
+	var (
+		backend = newTestBackend(t, core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}})
+		signer  = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+	)
+	defer backend.eai.blockchain.Stop()
+
+	chain := insertTestChain(t, backend, 2, func(i int, block *core.BlockGen) {
+		if i == 1 {
+			for nonce, price := range []int64{3, 1} {
+				tx, _ := types.SignTx(types.NewTransaction(uint64(nonce), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(price), nil), signer, testBankKey)
+				block.AddTx(tx)
+			}
+		}
+	})
+	backend.gpo = gasprice.NewOracle(backend, gasprice.Config{Blocks: 1, Default: big.NewInt(1)})
+
+	history, err := backend.FeeHistory(context.Background(), 5, rpc.LatestBlockNumber, []float64{0, 50, 100})
+	if err != nil {
+		t.Fatalf("failed to retrieve fee history: %v", err)
+	}
+	if history.OldestBlock != 0 || len(history.GasUsedRatio) != 3 || len(history.Reward) != 3 {
+		t.Fatalf("history range mismatch: %+v", history)
+	}
+	if want := float64(42000) / float64(chain[1].GasLimit()); history.GasUsedRatio[2] != want {
+		t.Errorf("gas used ratio mismatch: have %v, want %v", history.GasUsedRatio[2], want)
+	}
+	for i, want := range []int64{1, 1, 3} {
+		if history.Reward[1][i].Sign() != 0 {
+			t.Errorf("empty block reward %d mismatch: have %v, want 0", i, history.Reward[1][i])
+		}
+		if history.Reward[2][i].Int64() != want {
+			t.Errorf("reward %d mismatch: have %v, want %d", i, history.Reward[2][i], want)
+		}
+	}
+	if _, err := backend.FeeHistory(context.Background(), 1, rpc.LatestBlockNumber, []float64{50, 10}); err == nil {
+		t.Error("unordered percentiles accepted")
+	}
+}
+
+// Tests that the distance to the next epoch boundary follows the engine in use.
+func TestNextEpochInfo(t *testing.T) {
+	backend := newTestBackend(t, nil)
+	defer backend.eai.blockchain.Stop()
+
+	insertTestChain(t, backend, 3, nil)
+
+	info, err := backend.NextEpochInfo(context.Background())
+	if err != nil {
+		t.Fatalf("failed to retrieve eaiash epoch: %v", err)
+	}
+	want := &EpochInfo{Engine: "eaiash", Epoch: 0, EpochLength: eaiash.EpochLength, NextEpochBlock: eaiash.EpochLength, BlocksRemaining: eaiash.EpochLength - 3}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("eaiash epoch mismatch: have %+v, want %+v", info, want)
+	}
+	// Clique epochs are the signer checkpoints
+	config := *params.TestChainConfig
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 2}
+	backend.eai.chainConfig = &config
+
+	info, err = backend.NextEpochInfo(context.Background())
+	if err != nil {
+		t.Fatalf("failed to retrieve clique epoch: %v", err)
+	}
+	want = &EpochInfo{Engine: "clique", Epoch: 1, EpochLength: 2, NextEpochBlock: 4, BlocksRemaining: 1}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("clique epoch mismatch: have %+v, want %+v", info, want)
+	}
+	// Unset clique epochs default to the engine's checkpoint interval
+	config.Clique.Epoch = 0
+
+	info, err = backend.NextEpochInfo(context.Background())
+	if err != nil {
+		t.Fatalf("failed to retrieve default clique epoch: %v", err)
+	}
+	want = &EpochInfo{Engine: "clique", Epoch: 0, EpochLength: clique.DefaultEpochLength, NextEpochBlock: clique.DefaultEpochLength, BlocksRemaining: clique.DefaultEpochLength - 3}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("default clique epoch mismatch: have %+v, want %+v", info, want)
+	}
+}
+
+// Tests that the gas used ahead of a transaction is read from its block's receipts.
+func TestCumulativeGasBefore(t *testing.T) {
+	var (
+		backend = newTestBackend(t, core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}})
+		signer  = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+		txs     []*types.Transaction
+	)
+	defer backend.eai.blockchain.Stop()
+
+	insertTestChain(t, backend, 1, func(i int, block *core.BlockGen) {
+		for j := 0; j < 3; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testBank), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, testBankKey)
+			block.AddTx(tx)
+			txs = append(txs, tx)
+		}
+	})
+	for i, tx := range txs {
+		gas, err := backend.CumulativeGasBefore(context.Background(), tx.Hash())
+		if err != nil {
+			t.Fatalf("tx %d: failed to retrieve gas: %v", i, err)
+		}
+		if want := uint64(i) * 21000; gas != want {
+			t.Errorf("tx %d: gas mismatch: have %d, want %d", i, gas, want)
+		}
+	}
+	if _, err := backend.CumulativeGasBefore(context.Background(), common.Hash{0x01}); err == nil {
+		t.Error("unknown transaction succeeded")
+	}
+}
+
+// Tests that states are looked up by block hash, and unknown blocks rejected.
+func TestStateAndHeaderByHash(t *testing.T) {
+	backend := newTestBackend(t, core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}})
+	defer backend.eai.blockchain.Stop()
+
+	genesis := backend.eai.blockchain.Genesis()
+	statedb, header, err := backend.StateAndHeaderByHash(context.Background(), genesis.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve genesis state: %v", err)
+	}
+	if header.Hash() != genesis.Hash() {
+		t.Errorf("header mismatch: have %x, want %x", header.Hash(), genesis.Hash())
+	}
+	if have := statedb.GetBalance(testBank); have.Cmp(big.NewInt(1000000000)) != 0 {
+		t.Errorf("balance mismatch: have %v, want %v", have, 1000000000)
+	}
+	if _, _, err := backend.StateAndHeaderByHash(context.Background(), common.Hash{0x01}); err == nil {
+		t.Error("unknown block state retrieved")
+	}
+}
+
+// Tests that differing chain and network IDs are flagged.
+func TestNetworkInfo(t *testing.T) {
+	tests := []struct {
+		chainID   *big.Int
+		networkID uint64
+		mismatch  bool
+	}{
+		{big.NewInt(1), 1, false},
+		{big.NewInt(1), 2, true},
+		{new(big.Int).Lsh(big.NewInt(1), 64), 0, true},
+	}
+	for i, tt := range tests {
+		backend := &EaiAPIBackend{eai: &EthereumAI{chainConfig: &params.ChainConfig{ChainId: tt.chainID}, networkId: tt.networkID}}
+		chainID, networkID, mismatch, err := backend.NetworkInfo()
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve network info: %v", i, err)
+		}
+		if chainID.Cmp(tt.chainID) != 0 || networkID != tt.networkID || mismatch != tt.mismatch {
+			t.Errorf("test %d: info mismatch: have %v/%d/%v, want %v/%d/%v", i, chainID, networkID, mismatch, tt.chainID, tt.networkID, tt.mismatch)
+		}
+	}
+	backend := &EaiAPIBackend{eai: &EthereumAI{chainConfig: new(params.ChainConfig)}}
+	if _, _, _, err := backend.NetworkInfo(); err == nil {
+		t.Error("network info without chain ID succeeded")
+	}
+}
+
+// Tests that the head age is measured from the head's timestamp and never
+// negative for blocks stamped in the future.
+func TestHeadAge(t *testing.T) {
+	tests := []struct {
+		offset   int64
+		min, max time.Duration
+	}{
+		{-100, 100 * time.Second, 110 * time.Second},
+		{100, 0, 0},
+	}
+	for i, tt := range tests {
+		backend := newTestBackendWithGenesis(t, &core.Genesis{Config: params.TestChainConfig, Timestamp: uint64(time.Now().Unix() + tt.offset)}, nil)
+		if age := backend.HeadAge(); age < tt.min || age > tt.max {
+			t.Errorf("test %d: head age %v outside [%v, %v]", i, age, tt.min, tt.max)
+		}
+		backend.eai.blockchain.Stop()
+	}
+}
+
+// Tests that the calldata gas breakdown counts zero and non-zero bytes and bills
+// contract creations accordingly.
+func TestCalldataGasCost(t *testing.T) {
+	backend := newTestBackend(t, nil)
+	defer backend.eai.blockchain.Stop()
+
+	data := []byte{0x00, 0x01, 0x00, 0x02, 0x03}
+
+	tests := []struct {
+		tx  *types.Transaction
+		gas uint64
+	}{
+		{types.NewTransaction(0, common.Address{0x01}, new(big.Int), 0, new(big.Int), data), params.TxGas + 2*params.TxDataZeroGas + 3*params.TxDataNonZeroGas},
+		{types.NewContractCreation(0, new(big.Int), 0, new(big.Int), data), params.TxGasContractCreation + 2*params.TxDataZeroGas + 3*params.TxDataNonZeroGas},
+	}
+	for i, tt := range tests {
+		zeros, nonZeros, gas := backend.CalldataGasCost(tt.tx)
+		if zeros != 2 || nonZeros != 3 || gas != tt.gas {
+			t.Errorf("test %d: cost mismatch: have %d/%d/%d, want %d/%d/%d", i, zeros, nonZeros, gas, 2, 3, tt.gas)
+		}
+	}
+}
+
+// Tests that blocks are attributed to their coinbase and that oversized or
+// cancelled scans are rejected.
+func TestBlocksMinedBy(t *testing.T) {
+	var (
+		backend = newTestBackend(t, nil)
+		miner   = common.Address{0x01}
+	)
+	defer backend.eai.blockchain.Stop()
+
+	insertTestChain(t, backend, 5, func(i int, block *core.BlockGen) {
+		if i%2 == 0 {
+			block.SetCoinbase(miner)
+		} else {
+			block.SetCoinbase(common.Address{0x02})
+		}
+	})
+	mined, err := backend.BlocksMinedBy(context.Background(), miner, 0, 5)
+	if err != nil {
+		t.Fatalf("failed to retrieve mined blocks: %v", err)
+	}
+	if want := []uint64{1, 3, 5}; !reflect.DeepEqual(mined, want) {
+		t.Errorf("mined blocks mismatch: have %v, want %v", mined, want)
+	}
+	for i, r := range [][2]uint64{{3, 2}, {0, 6}, {0, maxBlocksMinedByRange}} {
+		if _, err := backend.BlocksMinedBy(context.Background(), miner, r[0], r[1]); err == nil {
+			t.Errorf("test %d: invalid range %d-%d succeeded", i, r[0], r[1])
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := backend.BlocksMinedBy(ctx, miner, 0, 5); err != context.Canceled {
+		t.Errorf("cancelled scan error mismatch: have %v, want %v", err, context.Canceled)
+	}
+}
+
+// Tests that the revert reason of a mined transaction is recovered by replaying
+// it on top of the transactions preceding it in its block.
+func TestRevertReason(t *testing.T) {
+	var (
+		reverter = common.Address{0xde, 0xad}
+		// PUSH1 100 PUSH1 12 PUSH1 0 CODECOPY PUSH1 100 PUSH1 0 REVERT, followed
+		// by the ABI encoding of Error("boom")
+		code = common.FromHex("0x6064600c600039606460" + "00fd" +
+			"08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"0000000000000000000000000000000000000000000000000000000000000004" +
+			"626f6f6d00000000000000000000000000000000000000000000000000000000")
+		backend = newTestBackend(t, core.GenesisAlloc{
+			testBank: {Balance: big.NewInt(1000000000)},
+			reverter: {Balance: new(big.Int), Code: code},
+		})
+		signer = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+		txs    []*types.Transaction
+	)
+	defer backend.eai.blockchain.Stop()
+
+	insertTestChain(t, backend, 1, func(i int, block *core.BlockGen) {
+		for _, to := range []common.Address{{0x01}, reverter} {
+			tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testBank), to, new(big.Int), 100000, big.NewInt(1), nil), signer, testBankKey)
+			block.AddTx(tx)
+			txs = append(txs, tx)
+		}
+	})
+	api := eaiapi.NewPublicTransactionPoolAPI(backend, new(eaiapi.AddrLocker))
+
+	reason, err := api.RevertReason(context.Background(), txs[1].Hash())
+	if err != nil {
+		t.Fatalf("failed to replay reverted transaction: %v", err)
+	}
+	if reason != "boom" {
+		t.Errorf("revert reason mismatch: have %q, want %q", reason, "boom")
+	}
+	if _, err := api.RevertReason(context.Background(), txs[0].Hash()); err == nil {
+		t.Error("replaying a successful transaction succeeded")
+	}
+	if _, err := api.RevertReason(context.Background(), common.Hash{0x01}); err == nil {
+		t.Error("replaying an unknown transaction succeeded")
+	}
+}
+
+// Tests that the block of a log is resolved from its transaction and block-wide index.
+func TestBlockForLog(t *testing.T) {
+	var (
+		logger  = common.Address{0x10, 0x99}
+		backend = newTestBackend(t, core.GenesisAlloc{
+			testBank: {Balance: big.NewInt(1000000000)},
+			logger:   {Balance: new(big.Int), Code: common.FromHex("0x60006000a0")}, // PUSH1 0 PUSH1 0 LOG0
+		})
+		signer = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+		txs    []*types.Transaction
+	)
+	defer backend.eai.blockchain.Stop()
+
+	chain := insertTestChain(t, backend, 2, func(i int, block *core.BlockGen) {
+		for j := 0; j < 2; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testBank), logger, new(big.Int), 100000, big.NewInt(1), nil), signer, testBankKey)
+			block.AddTx(tx)
+			txs = append(txs, tx)
+		}
+	})
+	// The second transaction of each block emits the block's second log
+	for i, tx := range txs {
+		block, err := backend.BlockForLog(context.Background(), tx.Hash(), uint(i%2))
+		if err != nil {
+			t.Fatalf("tx %d: failed to resolve block: %v", i, err)
+		}
+		if want := chain[i/2].Hash(); block.Hash() != want {
+			t.Errorf("tx %d: block mismatch: have %x, want %x", i, block.Hash(), want)
+		}
+		if _, err := backend.BlockForLog(context.Background(), tx.Hash(), uint(1-i%2)); err == nil {
+			t.Errorf("tx %d: log index of sibling transaction accepted", i)
+		}
+	}
+	if _, err := backend.BlockForLog(context.Background(), common.Hash{0x01}, 0); err == nil {
+		t.Error("unknown transaction succeeded")
+	}
+}
+
+// Tests that the balance history skips pruned states and rejects future blocks.
+func TestBalanceHistory(t *testing.T) {
+	var (
+		addr    = common.Address{0x42}
+		gspec   = &core.Genesis{Config: params.TestChainConfig, Alloc: core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}}}
+		backend = newTestBackendWithGenesis(t, gspec, &core.CacheConfig{Disabled: true})
+		signer  = types.NewEIP155Signer(gspec.Config.ChainId)
+		db      = backend.eai.chainDb
+	)
+	chain := insertTestChain(t, backend, 3, func(i int, block *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testBank), addr, big.NewInt(1000), 21000, big.NewInt(1), nil), signer, testBankKey)
+		block.AddTx(tx)
+	})
+	// Drop the state root of the first block and the account's trie path in the
+	// second to simulate pruning, and reopen the chain to get rid of cached tries
+	backend.eai.blockchain.Stop()
+	db.Delete(chain[0].Root().Bytes())
+
+	accounts, err := trie.NewSecure(chain[1].Root(), trie.NewDatabase(db), 0)
+	if err != nil {
+		t.Fatalf("failed to open state trie: %v", err)
+	}
+	path := eaidb.NewMemDatabase()
+	if err := accounts.Prove(crypto.Keccak256(addr[:]), 0, path); err != nil {
+		t.Fatalf("failed to collect account path: %v", err)
+	}
+	for _, key := range path.Keys() {
+		if !bytes.Equal(key, chain[1].Root().Bytes()) {
+			db.Delete(key)
+		}
+	}
+	backend = openTestBackend(t, db, gspec.Config, &core.CacheConfig{Disabled: true})
+	defer backend.eai.blockchain.Stop()
+
+	balances, err := backend.BalanceHistory(context.Background(), addr, []uint64{3, 0, 1, 2})
+	if err != nil {
+		t.Fatalf("failed to retrieve balance history: %v", err)
+	}
+	want := []*big.Int{big.NewInt(3000), big.NewInt(0), nil, nil}
+	if !reflect.DeepEqual(balances, want) {
+		t.Errorf("balance history mismatch: have %v, want %v", balances, want)
+	}
+	if _, err := backend.BalanceHistory(context.Background(), addr, []uint64{4}); err == nil {
+		t.Error("block beyond the head succeeded")
+	}
+	if _, err := backend.BalanceHistory(context.Background(), addr, make([]uint64, maxBalanceHistoryBlocks+1)); err == nil {
+		t.Error("request above the block limit succeeded")
+	}
+}
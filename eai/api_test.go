@@ -17,12 +17,14 @@
 package eai
 
 import (
+	"math/big"
 	"reflect"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/ethereumai/go-ethereumai/common"
 	"github.com/ethereumai/go-ethereumai/core/state"
+	"github.com/ethereumai/go-ethereumai/eai/downloader"
 	"github.com/ethereumai/go-ethereumai/eaidb"
 )
 
@@ -88,3 +90,33 @@ func TestStorageRangeAt(t *testing.T) {
 		}
 	}
 }
+
+// Tests that cancelling the sync while none is running reports so without
+// touching the downloader.
+func TestCancelSyncIdle(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	defer pm.Stop()
+
+	api := NewPrivateAdminAPI(&EthereumAI{protocolManager: pm})
+	if msg := api.CancelSync(); msg != "no sync in progress" {
+		t.Errorf("message mismatch: have %q, want %q", msg, "no sync in progress")
+	}
+}
+
+// Tests that the reported minimum gas price follows runtime adjustments of the pool.
+func TestMinGasPrice(t *testing.T) {
+	backend := newTestBackend(t, nil)
+	defer backend.eai.blockchain.Stop()
+
+	pool := newTestTxPool(backend)
+	defer pool.Stop()
+
+	api := NewPublicEthereumAIAPI(backend.eai)
+	if have, want := api.MinGasPrice().ToInt(), new(big.Int).SetUint64(testTxPoolConfig.PriceLimit); have.Cmp(want) != 0 {
+		t.Errorf("initial gas price floor mismatch: have %v, want %v", have, want)
+	}
+	pool.SetGasPrice(big.NewInt(42))
+	if have := api.MinGasPrice().ToInt(); have.Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("adjusted gas price floor mismatch: have %v, want 42", have)
+	}
+}
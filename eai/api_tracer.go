@@ -24,6 +24,7 @@ import (
 	"io/ioutil"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereumai/go-ethereumai/common"
@@ -52,6 +53,10 @@ const (
 	defaultTraceReexec = uint64(128)
 )
 
+// errTooManyTraces is returned if a trace is requested while the configured
+// maximum number of concurrent traces is already running.
+var errTooManyTraces = errors.New("too many concurrent traces")
+
 // TraceConfig holds extra parameters to trace functions.
 type TraceConfig struct {
 	*vm.LogConfig
@@ -170,6 +175,10 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 			}
 		}
 	}
+	// Reserve a trace slot, released once the whole chain segment is traced
+	if err := api.acquireTraceSlot(); err != nil {
+		return nil, err
+	}
 	// Execute all the transaction contained within the chain concurrently for each block
 	blocks := int(end.NumberU64() - origin)
 
@@ -239,6 +248,7 @@ func (api *PrivateDebugAPI) traceChain(ctx context.Context, start, end *types.Bl
 				log.Info("Chain tracing finished", "start", start.NumberU64(), "end", end.NumberU64(), "transactions", traced, "elapsed", time.Since(begin))
 			}
 			close(results)
+			api.releaseTraceSlot()
 		}()
 		// Feed all the blocks both into the tracer, as well as fast process concurrently
 		for number = start.NumberU64() + 1; number <= end.NumberU64(); number++ {
@@ -387,6 +397,11 @@ func (api *PrivateDebugAPI) TraceBlockFromFile(ctx context.Context, file string,
 // executes all the transactions contained within. The return value will be one item
 // per transaction, dependent on the requestd tracer.
 func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *types.Block, config *TraceConfig) ([]*txTraceResult, error) {
+	if err := api.acquireTraceSlot(); err != nil {
+		return nil, err
+	}
+	defer api.releaseTraceSlot()
+
 	// Create the parent state database
 	if err := api.eai.engine.VerifyHeader(api.eai.blockchain, block.Header(), true); err != nil {
 		return nil, err
@@ -533,6 +548,11 @@ func (api *PrivateDebugAPI) computeStateDB(block *types.Block, reexec uint64) (*
 // TraceTransaction returns the structured logs created during the execution of EVM
 // and returns them as a JSON object.
 func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Hash, config *TraceConfig) (interface{}, error) {
+	if err := api.acquireTraceSlot(); err != nil {
+		return nil, err
+	}
+	defer api.releaseTraceSlot()
+
 	// Retrieve the transaction and assemble its EVM context
 	tx, blockHash, _, index := rawdb.ReadTransaction(api.eai.ChainDb(), hash)
 	if tx == nil {
@@ -542,7 +562,7 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Ha
 	if config != nil && config.Reexec != nil {
 		reexec = *config.Reexec
 	}
-	msg, vmctx, statedb, err := api.computeTxEnv(blockHash, int(index), reexec)
+	msg, vmctx, statedb, err := api.computeTxEnv(ctx, blockHash, int(index), reexec)
 	if err != nil {
 		return nil, err
 	}
@@ -550,6 +570,28 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Ha
 	return api.traceTx(ctx, msg, vmctx, statedb, config)
 }
 
+// acquireTraceSlot reserves one of the configured concurrent trace slots, failing
+// with errTooManyTraces if all of them are taken.
+func (api *PrivateDebugAPI) acquireTraceSlot() error {
+	if slots := api.eai.traceSlots; slots != nil {
+		select {
+		case slots <- struct{}{}:
+		default:
+			return errTooManyTraces
+		}
+	}
+	traceInflightGauge.Update(int64(atomic.AddInt32(&api.eai.tracesInflight, 1)))
+	return nil
+}
+
+// releaseTraceSlot returns a trace slot previously reserved by acquireTraceSlot.
+func (api *PrivateDebugAPI) releaseTraceSlot() {
+	traceInflightGauge.Update(int64(atomic.AddInt32(&api.eai.tracesInflight, -1)))
+	if slots := api.eai.traceSlots; slots != nil {
+		<-slots
+	}
+}
+
 // traceTx configures a new tracer according to the provided configuration, and
 // executes the given message in the provided environment. The return value will
 // be tracer dependent.
@@ -611,8 +653,9 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 	}
 }
 
-// computeTxEnv returns the execution environment of a certain transaction.
-func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, reexec uint64) (core.Message, vm.Context, *state.StateDB, error) {
+// computeTxEnv returns the execution environment of a certain transaction. The
+// replay of the preceding transactions is aborted if the context is cancelled.
+func (api *PrivateDebugAPI) computeTxEnv(ctx context.Context, blockHash common.Hash, txIndex int, reexec uint64) (core.Message, vm.Context, *state.StateDB, error) {
 	// Create the parent state database
 	block := api.eai.blockchain.GetBlockByHash(blockHash)
 	if block == nil {
@@ -630,6 +673,9 @@ func (api *PrivateDebugAPI) computeTxEnv(blockHash common.Hash, txIndex int, ree
 	signer := types.MakeSigner(api.config, block.Number())
 
 	for idx, tx := range block.Transactions() {
+		if err := ctx.Err(); err != nil {
+			return nil, vm.Context{}, nil, err
+		}
 		// Assemble the transaction call message and return if the requested offset
 		msg, _ := tx.AsMessage(signer)
 		context := core.NewEVMContext(msg, block.Header(), api.eai.blockchain, nil)
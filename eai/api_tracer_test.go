@@ -0,0 +1,52 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereumai/go-ethereumai/params"
+)
+
+// Tests that traces beyond the configured concurrency limit are rejected until a
+// running one finishes.
+func TestTraceSlots(t *testing.T) {
+	api := NewPrivateDebugAPI(params.TestChainConfig, &EthereumAI{traceSlots: make(chan struct{}, 2)})
+	for i := 0; i < 2; i++ {
+		if err := api.acquireTraceSlot(); err != nil {
+			t.Fatalf("trace %d: failed to acquire slot: %v", i, err)
+		}
+	}
+	if err := api.acquireTraceSlot(); err != errTooManyTraces {
+		t.Fatalf("trace over the limit: error mismatch: have %v, want %v", err, errTooManyTraces)
+	}
+	if inflight := atomic.LoadInt32(&api.eai.tracesInflight); inflight != 2 {
+		t.Errorf("inflight traces mismatch: have %d, want %d", inflight, 2)
+	}
+	api.releaseTraceSlot()
+	if err := api.acquireTraceSlot(); err != nil {
+		t.Fatalf("failed to acquire released slot: %v", err)
+	}
+	// Without a limit, traces are never rejected
+	api = NewPrivateDebugAPI(params.TestChainConfig, new(EthereumAI))
+	for i := 0; i < 10; i++ {
+		if err := api.acquireTraceSlot(); err != nil {
+			t.Fatalf("unlimited trace %d: failed to acquire slot: %v", i, err)
+		}
+	}
+}
@@ -21,9 +21,11 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
 	"runtime"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/ethereumai/go-ethereumai/accounts"
 	"github.com/ethereumai/go-ethereumai/common"
@@ -43,12 +45,15 @@ import (
 	"github.com/ethereumai/go-ethereumai/event"
 	"github.com/ethereumai/go-ethereumai/internal/eaiapi"
 	"github.com/ethereumai/go-ethereumai/log"
+	"github.com/ethereumai/go-ethereumai/metrics"
+	"github.com/ethereumai/go-ethereumai/metrics/prometheus"
 	"github.com/ethereumai/go-ethereumai/miner"
 	"github.com/ethereumai/go-ethereumai/node"
 	"github.com/ethereumai/go-ethereumai/p2p"
 	"github.com/ethereumai/go-ethereumai/params"
 	"github.com/ethereumai/go-ethereumai/rlp"
 	"github.com/ethereumai/go-ethereumai/rpc"
+	"github.com/hashicorp/golang-lru"
 )
 
 type LesServer interface {
@@ -56,6 +61,26 @@ type LesServer interface {
 	Stop()
 	Protocols() []p2p.Protocol
 	SetBloomBitsIndexer(bbIndexer *core.ChainIndexer)
+	PeerCount() int
+	RequestRate() float64
+}
+
+// LightServerInfo summarises the light client serving footprint of a node.
+type LightServerInfo struct {
+	Serving     bool    `json:"serving"`     // Whether a light server is attached
+	LightServ   int     `json:"lightServ"`   // Maximum percentage of time allowed for serving
+	LightPeers  int     `json:"lightPeers"`  // Maximum number of light client slots
+	Peers       int     `json:"peers"`       // Number of currently connected light clients
+	RequestRate float64 `json:"requestRate"` // Average requests served per second
+}
+
+// FutureBlockInfo describes a block queued for import because its timestamp is
+// ahead of the local clock.
+type FutureBlockInfo struct {
+	Hash       common.Hash    `json:"hash"`
+	Number     hexutil.Uint64 `json:"number"`
+	Timestamp  hexutil.Uint64 `json:"timestamp"`
+	ImportTime time.Time      `json:"importTime"` // Time the block stops being ahead of the local clock
 }
 
 // EthereumAI implements the EthereumAI full node service.
@@ -90,6 +115,20 @@ type EthereumAI struct {
 
 	networkId     uint64
 	netRPCService *eaiapi.PublicNetAPI
+	filterAPI     *filters.PublicFilterAPI
+
+	exportStop chan struct{}  // Quit channel of the scheduled state exporter (nil = not scheduled)
+	exportWg   sync.WaitGroup // Waits for the scheduled state exporters to finish
+
+	storageSamples []storageSample // Chain data size samples over the recent blocks
+	storageLock    sync.Mutex      // Protects the chain data size samples
+
+	txHistory   *lru.Cache    // Block inclusion history of transactions affected by reorgs
+	reorgedTxs  []common.Hash // Transactions dropped from the canonical chain by recent reorgs
+	reorgedLock sync.Mutex    // Protects the reorged transactions
+
+	traceSlots     chan struct{} // Semaphore limiting concurrent trace operations (nil = unlimited)
+	tracesInflight int32         // Number of trace operations currently running (atomic)
 
 	lock sync.RWMutex // Protects the variadic fields (e.g. gas price and etheraibase)
 }
@@ -108,6 +147,9 @@ func New(ctx *node.ServiceContext, config *Config) (*EthereumAI, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
+	if config.Eaiash.RemoteSealerURL != "" && config.MinerThreads > 0 {
+		return nil, fmt.Errorf("can't mine on %d local threads with remote sealer %s configured", config.MinerThreads, config.Eaiash.RemoteSealerURL)
+	}
 	chainDb, err := CreateDB(ctx, config, "chaindata")
 	if err != nil {
 		return nil, err
@@ -132,7 +174,23 @@ func New(ctx *node.ServiceContext, config *Config) (*EthereumAI, error) {
 		bloomRequests:  make(chan chan *bloombits.Retrieval),
 		bloomIndexer:   NewBloomIndexer(chainDb, params.BloomBitsBlocks),
 	}
+	eai.txHistory, _ = lru.New(txHistoryLimit)
 
+	if config.MaxConcurrentTraces > 0 {
+		eai.traceSlots = make(chan struct{}, config.MaxConcurrentTraces)
+	}
+	if config.FutureBlockTolerance > core.MaxFutureBlockTime {
+		// Blocks beyond the chain's limit are rejected, never queued, cap it there
+		log.Warn("Future block tolerance exceeds the chain's limit, capping", "requested", config.FutureBlockTolerance, "limit", core.MaxFutureBlockTime)
+		config.FutureBlockTolerance = core.MaxFutureBlockTime
+	}
+	if config.FutureBlockTolerance != 0 {
+		if engine, ok := eai.engine.(*eaiash.Eaiash); ok {
+			engine.SetFutureBlockTolerance(config.FutureBlockTolerance)
+		} else {
+			log.Warn("Future block tolerance not supported by consensus engine", "tolerance", config.FutureBlockTolerance)
+		}
+	}
 	log.Info("Initialising EthereumAI protocol", "versions", ProtocolVersions, "network", config.NetworkId)
 
 	if !config.SkipBcVersionCheck {
@@ -163,11 +221,22 @@ func New(ctx *node.ServiceContext, config *Config) (*EthereumAI, error) {
 	}
 	eai.txPool = core.NewTxPool(config.TxPool, eai.chainConfig, eai.blockchain)
 
+	if config.StateExportInterval > 0 {
+		if config.StateExportDir == "" {
+			config.StateExportDir = "stateexports"
+		}
+		config.StateExportDir = ctx.ResolvePath(config.StateExportDir)
+	}
+
 	if eai.protocolManager, err = NewProtocolManager(eai.chainConfig, config.SyncMode, config.NetworkId, eai.eventMux, eai.txPool, eai.engine, eai.blockchain, chainDb); err != nil {
 		return nil, err
 	}
+	eai.protocolManager.broadcastRetries = config.BroadcastRetries
+	eai.protocolManager.fetcher.SetBodyCacheSize(config.FetcherBodyCacheSize)
+	eai.protocolManager.importPriority.setPreferred(config.PreferGossipImport)
 	eai.miner = miner.New(eai, eai.chainConfig, eai.EventMux(), eai.engine)
 	eai.miner.SetExtra(makeExtraData(config.ExtraData))
+	eai.miner.SetWatchdogTimeout(config.MinerWatchdogTimeout)
 
 	eai.APIBackend = &EaiAPIBackend{eai, nil}
 	gpoParams := config.GPO
@@ -227,12 +296,16 @@ func CreateConsensusEngine(ctx *node.ServiceContext, config *eaiash.Config, chai
 		return eaiash.NewShared()
 	default:
 		engine := eaiash.New(eaiash.Config{
-			CacheDir:       ctx.ResolvePath(config.CacheDir),
-			CachesInMem:    config.CachesInMem,
-			CachesOnDisk:   config.CachesOnDisk,
-			DatasetDir:     config.DatasetDir,
-			DatasetsInMem:  config.DatasetsInMem,
-			DatasetsOnDisk: config.DatasetsOnDisk,
+			CacheDir:          ctx.ResolvePath(config.CacheDir),
+			CachesInMem:       config.CachesInMem,
+			CachesOnDisk:      config.CachesOnDisk,
+			DatasetDir:        config.DatasetDir,
+			DatasetsInMem:     config.DatasetsInMem,
+			DatasetsOnDisk:    config.DatasetsOnDisk,
+			TargetBlockTime:   config.TargetBlockTime,
+			GenerationThreads: config.GenerationThreads,
+			RemoteSealerURL:   config.RemoteSealerURL,
+			SealDeadline:      config.SealDeadline,
 		})
 		engine.SetThreads(-1) // Disable CPU mining
 		return engine
@@ -247,6 +320,9 @@ func (s *EthereumAI) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// Retain the filter API to allow inspecting its subscriptions
+	s.filterAPI = filters.NewPublicFilterAPI(s.APIBackend, false)
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -272,7 +348,7 @@ func (s *EthereumAI) APIs() []rpc.API {
 		}, {
 			Namespace: "eai",
 			Version:   "1.0",
-			Service:   filters.NewPublicFilterAPI(s.APIBackend, false),
+			Service:   s.filterAPI,
 			Public:    true,
 		}, {
 			Namespace: "admin",
@@ -300,6 +376,13 @@ func (s *EthereumAI) ResetWithGenesisBlock(gb *types.Block) {
 	s.blockchain.ResetWithGenesisBlock(gb)
 }
 
+// ErrNoEtherAIbase is returned if no etheraibase is configured and none may be
+// derived from the local wallets.
+var ErrNoEtherAIbase = errors.New("etheraibase must be explicitly specified")
+
+// EtherAIbase returns the configured mining reward address. If none is set and
+// AllowAutoEtherAIbase is enabled, the first account of the first wallet is used,
+// otherwise ErrNoEtherAIbase is returned.
 func (s *EthereumAI) EtherAIbase() (eb common.Address, err error) {
 	s.lock.RLock()
 	etheraibase := s.etheraibase
@@ -308,6 +391,9 @@ func (s *EthereumAI) EtherAIbase() (eb common.Address, err error) {
 	if etheraibase != (common.Address{}) {
 		return etheraibase, nil
 	}
+	if !s.config.AllowAutoEtherAIbase {
+		return common.Address{}, ErrNoEtherAIbase
+	}
 	if wallets := s.AccountManager().Wallets(); len(wallets) > 0 {
 		if accounts := wallets[0].Accounts(); len(accounts) > 0 {
 			etheraibase := accounts[0].Address
@@ -320,7 +406,25 @@ func (s *EthereumAI) EtherAIbase() (eb common.Address, err error) {
 			return etheraibase, nil
 		}
 	}
-	return common.Address{}, fmt.Errorf("etheraibase must be explicitly specified")
+	return common.Address{}, ErrNoEtherAIbase
+}
+
+// SetGasPrice updates the minimum gas price of the node, applying it as the new
+// floor of the transaction pool (and hence of the transactions the miner packs)
+// and re-seeding the gas price oracle. The stored price is swapped under the
+// node lock first, which is released before the pool and oracle are updated so
+// that concurrent EtherAIbase calls never wait on the pool's own lock.
+func (s *EthereumAI) SetGasPrice(price *big.Int) {
+	price = new(big.Int).Set(price)
+
+	s.lock.Lock()
+	s.gasPrice = price
+	s.lock.Unlock()
+
+	s.txPool.SetGasPrice(price)
+	if s.APIBackend != nil && s.APIBackend.gpo != nil {
+		s.APIBackend.gpo.SetDefault(price)
+	}
 }
 
 // SetEtherAIbase sets the mining reward address.
@@ -332,11 +436,40 @@ func (s *EthereumAI) SetEtherAIbase(etheraibase common.Address) {
 	s.miner.SetEtherAIbase(etheraibase)
 }
 
-func (s *EthereumAI) StartMining(local bool) error {
+// StartMining starts the miner, first updating the thread count of the seal
+// engine if it supports one (e.g. eaiash) and a count is given. Zero threads use
+// all logical CPUs, while a negative count disables local sealing, leaving block
+// sealing to remote agents. A nil count keeps the current setting of the engine.
+// Engines without a thread count (e.g. clique) are started irrespective of it.
+// If the miner is already running, only the thread count is updated. Enabling
+// local threads while sealing is delegated to a remote sealer is an error.
+func (s *EthereumAI) StartMining(threads *int) error {
+	type threaded interface {
+		Threads() int
+		SetThreads(threads int)
+	}
+	local := true
+	if th, ok := s.engine.(threaded); ok {
+		if threads != nil && *threads >= 0 && s.RemoteSealing() {
+			return fmt.Errorf("can't mine on %d local threads with remote sealer %s configured", *threads, s.config.Eaiash.RemoteSealerURL)
+		}
+		if threads != nil {
+			count := *threads
+			if count == 0 {
+				count = runtime.NumCPU()
+			}
+			log.Info("Updated mining threads", "threads", count)
+			th.SetThreads(count)
+		}
+		local = th.Threads() >= 0
+	}
+	if s.IsMining() {
+		return nil
+	}
 	eb, err := s.EtherAIbase()
 	if err != nil {
 		log.Error("Cannot start mining without etheraibase", "err", err)
-		return fmt.Errorf("etheraibase missing: %v", err)
+		return err
 	}
 	if clique, ok := s.engine.(*clique.Clique); ok {
 		wallet, err := s.accountManager.Find(accounts.Account{Address: eb})
@@ -357,6 +490,211 @@ func (s *EthereumAI) StartMining(local bool) error {
 	return nil
 }
 
+// RemoteSealing reports whether the seal engine delegates the nonce search to a
+// remote sealer, in which case no local mining threads can be started.
+func (s *EthereumAI) RemoteSealing() bool {
+	remote, ok := s.engine.(interface{ RemoteSealing() bool })
+	return ok && remote.RemoteSealing()
+}
+
+// LightServerInfo reports whether the node is serving light clients and what its
+// configured and current serving capacity is. If no light server is attached,
+// the zero value is returned.
+func (s *EthereumAI) LightServerInfo() *LightServerInfo {
+	if s.lesServer == nil {
+		return new(LightServerInfo)
+	}
+	return &LightServerInfo{
+		Serving:     true,
+		LightServ:   s.config.LightServ,
+		LightPeers:  s.config.LightPeers,
+		Peers:       s.lesServer.PeerCount(),
+		RequestRate: s.lesServer.RequestRate(),
+	}
+}
+
+// SetLocalAccounts marks the given accounts as local in the transaction pool, in
+// addition to the ones that already submitted transactions through this node.
+func (s *EthereumAI) SetLocalAccounts(addrs []common.Address) {
+	s.txPool.AddLocalAccounts(addrs)
+}
+
+// SetJournalEnabled suspends or resumes the transaction pool's local journal,
+// e.g. to avoid excessive disk writes during bulk transaction injection. While
+// suspended, newly added local transactions do not survive a crash.
+func (s *EthereumAI) SetJournalEnabled(enabled bool) {
+	s.txPool.SetJournalEnabled(enabled)
+}
+
+// ExpectedHashesPerBlock returns the approximate number of hashes needed to seal
+// the next block on top of the current head, which for proof-of-work equals the
+// difficulty the next block is expected to have.
+func (s *EthereumAI) ExpectedHashesPerBlock() (*big.Int, error) {
+	if _, ok := s.engine.(*eaiash.Eaiash); !ok {
+		return nil, errors.New("consensus engine is not proof-of-work")
+	}
+	parent := s.blockchain.CurrentHeader()
+
+	timestamp := uint64(time.Now().Unix())
+	if parent.Time.Uint64() >= timestamp {
+		timestamp = parent.Time.Uint64() + 1
+	}
+	return s.engine.CalcDifficulty(s.blockchain, timestamp, parent), nil
+}
+
+// ForceInclude marks a pending transaction to be placed at the front of the next
+// block the miner builds, overriding the price ordering. It fails if the
+// transaction isn't pooled or couldn't be executed next.
+func (s *EthereumAI) ForceInclude(hash common.Hash) error {
+	return s.miner.ForceInclude(hash)
+}
+
+// SetMinedBlockSink registers a channel receiving every block successfully
+// mined by this node, before it is broadcast to the network. Unlike chain head
+// subscriptions, blocks imported from peers are not delivered. If the consumer
+// falls behind, blocks are dropped rather than blocking the miner. Passing nil
+// removes the sink.
+func (s *EthereumAI) SetMinedBlockSink(ch chan<- *types.Block) {
+	s.protocolManager.setMinedSink(ch)
+}
+
+// TracePeerMessages enables or disables debug logging of every eai protocol
+// message exchanged with the given peer. The peer may be identified by its full
+// node ID or its short form. Tracing ends automatically once the peer drops.
+func (s *EthereumAI) TracePeerMessages(peerID string, enable bool) error {
+	peer := s.protocolManager.peers.PeerByNodeID(peerID)
+	if peer == nil {
+		return fmt.Errorf("peer %s not connected", peerID)
+	}
+	return peer.SetTracing(enable)
+}
+
+// ClockSkew estimates for every peer that propagated blocks to us how far the
+// local clock is ahead of the timestamps of those blocks (negative if behind),
+// based on their arrival times. A clock skewed against most peers delays the
+// import of their blocks and gets locally mined blocks rejected.
+func (s *EthereumAI) ClockSkew() (map[string]time.Duration, error) {
+	skews := s.protocolManager.peers.ClockSkews()
+	if len(skews) == 0 {
+		return nil, errors.New("no blocks propagated by peers yet")
+	}
+	return skews, nil
+}
+
+// ActiveSubscriptions reports the event subscriptions and filters currently
+// installed through the node's RPC interface, along with their creation time,
+// helping to track down leaked subscriptions of abandoned clients.
+func (s *EthereumAI) ActiveSubscriptions() []filters.SubscriptionInfo {
+	if s.filterAPI == nil {
+		return nil
+	}
+	return filters.ActiveSubscriptions(s.filterAPI)
+}
+
+// StateAvailability reports for every canonical block in the inclusive range
+// [from, to] whether its state trie is still present in the database, allowing
+// callers to detect pruned ranges before starting expensive operations on them.
+func (s *EthereumAI) StateAvailability(from, to uint64) ([]bool, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid range: start block %d after end block %d", from, to)
+	}
+	if head := s.blockchain.CurrentBlock().NumberU64(); to > head {
+		return nil, fmt.Errorf("end block %d beyond current head %d", to, head)
+	}
+	available := make([]bool, 0, to-from+1)
+	for number := from; number <= to; number++ {
+		header := s.blockchain.GetHeaderByNumber(number)
+		if header == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		available = append(available, s.blockchain.HasState(header.Root))
+	}
+	return available, nil
+}
+
+// FutureBlocks returns the blocks currently held back from import because their
+// timestamps are ahead of the local clock, along with the time they become
+// importable. Queued blocks are retried every few seconds, so a long list hints
+// at a skewed local clock.
+func (s *EthereumAI) FutureBlocks() []FutureBlockInfo {
+	var tolerance time.Duration
+	if engine, ok := s.engine.(*eaiash.Eaiash); ok {
+		tolerance = engine.FutureBlockTolerance()
+	}
+	blocks := s.blockchain.FutureBlocks()
+
+	infos := make([]FutureBlockInfo, len(blocks))
+	for i, block := range blocks {
+		infos[i] = FutureBlockInfo{
+			Hash:       block.Hash(),
+			Number:     hexutil.Uint64(block.NumberU64()),
+			Timestamp:  hexutil.Uint64(block.Time().Uint64()),
+			ImportTime: time.Unix(block.Time().Int64(), 0).Add(-tolerance),
+		}
+	}
+	return infos
+}
+
+// PrometheusHandler returns an HTTP handler exposing all registered metrics in
+// the Prometheus text exposition format, so they can be scraped directly.
+func (s *EthereumAI) PrometheusHandler() http.Handler {
+	return prometheus.Handler(metrics.DefaultRegistry)
+}
+
+// PendingBlockStats returns the number of pool transactions the miner included in
+// its current pending block and the number it skipped, helping to diagnose why
+// transactions aren't mined. Zeros are returned if mining is stopped.
+func (s *EthereumAI) PendingBlockStats() (included int, skipped int) {
+	return s.miner.PendingStats()
+}
+
+// ValidateBlock runs the full validation of a block without importing it: the
+// header and seal are verified, the body checked against the header, and the
+// transactions executed on top of the parent state, whose resulting root and
+// receipts must match the ones committed to. The state of the parent must be
+// available locally. A block already imported is reported as core.ErrKnownBlock.
+func (s *EthereumAI) ValidateBlock(block *types.Block) error {
+	parent := s.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	if err := s.engine.VerifyHeader(s.blockchain, block.Header(), true); err != nil {
+		return err
+	}
+	if err := s.blockchain.Validator().ValidateBody(block); err != nil {
+		return err
+	}
+	statedb, err := s.blockchain.StateAt(parent.Root())
+	if err != nil {
+		return fmt.Errorf("state of parent #%d [%x] unavailable: %v", parent.NumberU64(), parent.Hash(), err)
+	}
+	receipts, _, usedGas, err := s.blockchain.Processor().Process(block, statedb, vm.Config{})
+	if err != nil {
+		return err
+	}
+	return s.blockchain.Validator().ValidateState(block, parent, statedb, receipts, usedGas)
+}
+
+// PauseMining suspends block sealing without tearing down the miner. Pending
+// blocks keep being assembled from new transactions and chain heads, so sealing
+// continues without a warm-up stall once ResumeMining is called.
+func (s *EthereumAI) PauseMining() error {
+	if !s.miner.Mining() {
+		return errors.New("miner not running")
+	}
+	s.miner.Pause()
+	return nil
+}
+
+// ResumeMining restarts block sealing after a PauseMining.
+func (s *EthereumAI) ResumeMining() error {
+	if !s.miner.Paused() {
+		return errors.New("miner not paused")
+	}
+	s.miner.Resume()
+	return nil
+}
+
 func (s *EthereumAI) StopMining()         { s.miner.Stop() }
 func (s *EthereumAI) IsMining() bool      { return s.miner.Mining() }
 func (s *EthereumAI) Miner() *miner.Miner { return s.miner }
@@ -387,6 +725,20 @@ func (s *EthereumAI) Start(srvr *p2p.Server) error {
 	// Start the bloom bits servicing goroutines
 	s.startBloomHandlers()
 
+	// Start retaining the inclusion history of transactions affected by reorgs
+	go s.trackTxReorgs()
+
+	// Start tracking the chain data growth if backed by a disk database
+	if db, ok := s.chainDb.(*eaidb.LDBDatabase); ok {
+		go s.sampleStorage(db.Path())
+	}
+
+	// Start exporting the state periodically if requested
+	if s.config.StateExportInterval > 0 {
+		if err := s.ScheduleStateExport(s.config.StateExportInterval, s.config.StateExportDir); err != nil {
+			return err
+		}
+	}
 	// Start the RPC service
 	s.netRPCService = eaiapi.NewPublicNetAPI(srvr, s.NetVersion())
 
@@ -409,6 +761,9 @@ func (s *EthereumAI) Start(srvr *p2p.Server) error {
 // Stop implements node.Service, terminating all internal goroutines used by the
 // EthereumAI protocol.
 func (s *EthereumAI) Stop() error {
+	s.ScheduleStateExport(0, "")
+	s.exportWg.Wait()
+
 	s.bloomIndexer.Close()
 	s.blockchain.Stop()
 	s.protocolManager.Stop()
@@ -416,6 +771,7 @@ func (s *EthereumAI) Stop() error {
 		s.lesServer.Stop()
 	}
 	s.txPool.Stop()
+	s.miner.SetWatchdogTimeout(0)
 	s.miner.Stop()
 	s.eventMux.Stop()
 
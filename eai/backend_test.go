@@ -0,0 +1,248 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"math/big"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/consensus"
+	"github.com/ethereumai/go-ethereumai/consensus/clique"
+	"github.com/ethereumai/go-ethereumai/consensus/eaiash"
+	"github.com/ethereumai/go-ethereumai/core"
+	"github.com/ethereumai/go-ethereumai/core/types"
+	"github.com/ethereumai/go-ethereumai/event"
+	"github.com/ethereumai/go-ethereumai/miner"
+	"github.com/ethereumai/go-ethereumai/p2p"
+	"github.com/ethereumai/go-ethereumai/params"
+)
+
+// Tests that candidate blocks are fully validated without being imported.
+func TestValidateBlock(t *testing.T) {
+	var (
+		backend = newTestBackend(t, core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}})
+		signer  = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+		eai     = backend.eai
+	)
+	defer eai.blockchain.Stop()
+
+	chain := generateTestChain(backend, 2, func(i int, block *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testBank), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, testBankKey)
+		block.AddTx(tx)
+	})
+	if _, err := eai.blockchain.InsertChain(chain[:1]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if err := eai.ValidateBlock(chain[1]); err != nil {
+		t.Fatalf("valid block rejected: %v", err)
+	}
+	if eai.blockchain.HasBlock(chain[1].Hash(), chain[1].NumberU64()) {
+		t.Fatalf("validated block was imported")
+	}
+	// A header committing to a different state root must be rejected
+	header := chain[1].Header()
+	header.Root = common.Hash{0x01}
+	if err := eai.ValidateBlock(chain[1].WithSeal(header)); err == nil {
+		t.Error("block with invalid state root accepted")
+	}
+	// A block on top of an unknown parent must be rejected
+	orphan, _ := core.GenerateChain(eai.chainConfig, chain[1], eai.engine, eai.chainDb, 1, nil)
+	if err := eai.ValidateBlock(orphan[0]); err != consensus.ErrUnknownAncestor {
+		t.Errorf("orphan block error mismatch: have %v, want %v", err, consensus.ErrUnknownAncestor)
+	}
+}
+
+// Tests that state availability reflects which block states are present on disk.
+func TestStateAvailability(t *testing.T) {
+	backend := newTestBackendWithGenesis(t, &core.Genesis{Config: params.TestChainConfig}, &core.CacheConfig{Disabled: true})
+	chain := insertTestChain(t, backend, 3, nil)
+
+	// Drop the state root of the first block to simulate pruning and reopen the
+	// chain to get rid of any cached tries
+	backend.eai.blockchain.Stop()
+	backend.eai.chainDb.Delete(chain[0].Root().Bytes())
+
+	backend = openTestBackend(t, backend.eai.chainDb, params.TestChainConfig, &core.CacheConfig{Disabled: true})
+	defer backend.eai.blockchain.Stop()
+
+	eai := backend.eai
+	available, err := eai.StateAvailability(0, 3)
+	if err != nil {
+		t.Fatalf("failed to retrieve availability: %v", err)
+	}
+	if want := []bool{true, false, true, true}; !reflect.DeepEqual(available, want) {
+		t.Errorf("availability mismatch: have %v, want %v", available, want)
+	}
+	if _, err := eai.StateAvailability(2, 1); err == nil {
+		t.Error("inverted range succeeded")
+	}
+	if _, err := eai.StateAvailability(0, 4); err == nil {
+		t.Error("range beyond the head succeeded")
+	}
+}
+
+// testLesServer is a light server reporting a fixed serving load.
+type testLesServer struct {
+	peers int
+	rate  float64
+}
+
+func (s *testLesServer) Start(srvr *p2p.Server)                           {}
+func (s *testLesServer) Stop()                                            {}
+func (s *testLesServer) Protocols() []p2p.Protocol                        { return nil }
+func (s *testLesServer) SetBloomBitsIndexer(bbIndexer *core.ChainIndexer) {}
+func (s *testLesServer) PeerCount() int                                   { return s.peers }
+func (s *testLesServer) RequestRate() float64                             { return s.rate }
+
+// Tests that the light server info reflects the configuration and the load of
+// the attached light server.
+func TestLightServerInfo(t *testing.T) {
+	eai := &EthereumAI{config: &Config{LightServ: 50, LightPeers: 20}}
+	if info := eai.LightServerInfo(); !reflect.DeepEqual(info, new(LightServerInfo)) {
+		t.Errorf("info without light server mismatch: have %+v", info)
+	}
+	eai.lesServer = &testLesServer{peers: 3, rate: 1.5}
+
+	want := &LightServerInfo{Serving: true, LightServ: 50, LightPeers: 20, Peers: 3, RequestRate: 1.5}
+	if info := eai.LightServerInfo(); !reflect.DeepEqual(info, want) {
+		t.Errorf("info mismatch: have %+v, want %+v", info, want)
+	}
+}
+
+// Tests that the expected work of the next block is its proof-of-work difficulty.
+func TestExpectedHashesPerBlock(t *testing.T) {
+	gspec := &core.Genesis{Config: params.TestChainConfig, Difficulty: big.NewInt(131072), Timestamp: uint64(time.Now().Unix() + 1000)}
+	backend := newTestBackendWithGenesis(t, gspec, nil)
+	defer backend.eai.blockchain.Stop()
+
+	// The head is stamped in the future, so the next block follows it by a second
+	eai := backend.eai
+	hashes, err := eai.ExpectedHashesPerBlock()
+	if err != nil {
+		t.Fatalf("failed to estimate work: %v", err)
+	}
+	head := eai.blockchain.CurrentHeader()
+	if want := eaiash.CalcDifficulty(gspec.Config, head.Time.Uint64()+1, head); hashes.Cmp(want) != 0 {
+		t.Errorf("expected work mismatch: have %v, want %v", hashes, want)
+	}
+	eai.engine = clique.New(&params.CliqueConfig{Period: 1, Epoch: 30000}, eai.chainDb)
+	if _, err := eai.ExpectedHashesPerBlock(); err == nil {
+		t.Error("expected work of a proof-of-authority engine succeeded")
+	}
+}
+
+// Tests that starting the miner only touches the thread count of the seal engine
+// if the caller specifies one, and that zero threads enable all CPUs.
+func TestStartMiningThreads(t *testing.T) {
+	backend := newTestBackend(t, nil)
+	defer backend.eai.blockchain.Stop()
+
+	pool := newTestTxPool(backend)
+	defer pool.Stop()
+
+	eai := backend.eai
+	eai.eventMux = new(event.TypeMux)
+	eai.etheraibase = testBank
+	eai.gasPrice = big.NewInt(1)
+	eai.protocolManager = new(ProtocolManager)
+
+	engine := eai.engine.(*eaiash.Eaiash)
+	engine.SetThreads(-1)
+
+	eai.miner = miner.New(eai, eai.chainConfig, eai.EventMux(), engine)
+	defer eai.miner.Stop()
+
+	// Remote work requests start the miner without enabling local sealing
+	if err := eai.StartMining(nil); err != nil {
+		t.Fatalf("failed to start remote mining: %v", err)
+	}
+	if threads := engine.Threads(); threads != -1 {
+		t.Errorf("remote start changed threads: have %d, want -1", threads)
+	}
+	if atomic.LoadUint32(&eai.protocolManager.acceptTxs) != 0 {
+		t.Errorf("remote start enabled transaction acceptance")
+	}
+	for i := 0; i < 100 && !eai.IsMining(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !eai.IsMining() {
+		t.Fatalf("miner not started")
+	}
+	// Explicit counts are applied to the running miner, zero meaning all CPUs
+	api := NewPrivateMinerAPI(eai)
+	for _, tt := range []struct{ threads, want int }{{2, 2}, {0, runtime.NumCPU()}, {-1, -1}} {
+		threads := tt.threads
+		if err := api.Start(&threads); err != nil {
+			t.Fatalf("failed to start miner with %d threads: %v", tt.threads, err)
+		}
+		if have := engine.Threads(); have != tt.want {
+			t.Errorf("threads mismatch for %d: have %d, want %d", tt.threads, have, tt.want)
+		}
+	}
+	if err := api.Start(nil); err != nil {
+		t.Fatalf("failed to start miner: %v", err)
+	}
+	if have := engine.Threads(); have != runtime.NumCPU() {
+		t.Errorf("threads mismatch for default: have %d, want %d", have, runtime.NumCPU())
+	}
+}
+
+// Tests that local mining threads can't be started if sealing is delegated to a
+// remote sealer, while starting without a thread count still works.
+func TestStartMiningRemoteSealer(t *testing.T) {
+	backend := newTestBackend(t, nil)
+	defer backend.eai.blockchain.Stop()
+
+	pool := newTestTxPool(backend)
+	defer pool.Stop()
+
+	eai := backend.eai
+	eai.eventMux = new(event.TypeMux)
+	eai.etheraibase = testBank
+	eai.gasPrice = big.NewInt(1)
+	eai.protocolManager = new(ProtocolManager)
+	eai.config = &Config{Eaiash: eaiash.Config{PowMode: eaiash.ModeTest, RemoteSealerURL: "http://127.0.0.1:1"}}
+	eai.engine = eaiash.New(eai.config.Eaiash)
+
+	eai.miner = miner.New(eai, eai.chainConfig, eai.EventMux(), eai.engine)
+	defer eai.miner.Stop()
+
+	api := NewPrivateMinerAPI(eai)
+	for _, threads := range []int{0, 2} {
+		threads := threads
+		if err := api.Start(&threads); err == nil {
+			t.Errorf("started %d local threads with remote sealer", threads)
+		}
+	}
+	if eai.IsMining() {
+		t.Fatalf("miner started despite conflict")
+	}
+	if err := api.Start(nil); err != nil {
+		t.Fatalf("failed to start remote mining: %v", err)
+	}
+	if threads := eai.engine.(*eaiash.Eaiash).Threads(); threads != -1 {
+		t.Errorf("local threads mismatch: have %d, want -1", threads)
+	}
+	if atomic.LoadUint32(&eai.protocolManager.acceptTxs) != 0 {
+		t.Errorf("remote sealing enabled transaction acceptance")
+	}
+}
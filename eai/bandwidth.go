@@ -0,0 +1,73 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"fmt"
+
+	"github.com/ethereumai/go-ethereumai/p2p"
+)
+
+// msgNames maps the eai protocol message codes to readable names.
+var msgNames = map[uint64]string{
+	StatusMsg:          "Status",
+	NewBlockHashesMsg:  "NewBlockHashes",
+	TxMsg:              "Transactions",
+	GetBlockHeadersMsg: "GetBlockHeaders",
+	BlockHeadersMsg:    "BlockHeaders",
+	GetBlockBodiesMsg:  "GetBlockBodies",
+	BlockBodiesMsg:     "BlockBodies",
+	NewBlockMsg:        "NewBlock",
+	GetNodeDataMsg:     "GetNodeData",
+	NodeDataMsg:        "NodeData",
+	GetReceiptsMsg:     "GetReceipts",
+	ReceiptsMsg:        "Receipts",
+}
+
+// MessageTraffic is the number of payload bytes exchanged for a message type.
+type MessageTraffic struct {
+	Sent     uint64 `json:"sent"`
+	Received uint64 `json:"received"`
+}
+
+// BandwidthStats summarises the message payload bytes exchanged with peers
+// across all sub-protocols since the node started.
+type BandwidthStats struct {
+	Sent     uint64                     `json:"sent"`
+	Received uint64                     `json:"received"`
+	Messages map[string]*MessageTraffic `json:"messages"` // Keyed by protocol/version/message
+}
+
+// BandwidthStats reports the bandwidth used by the p2p layer, broken down by
+// protocol message type. Messages of the eai protocol are named, those of other
+// protocols (e.g. les) are identified by their message code.
+func (s *EthereumAI) BandwidthStats() *BandwidthStats {
+	stats := &BandwidthStats{Messages: make(map[string]*MessageTraffic)}
+	for key, traffic := range p2p.TrafficStats() {
+		name, ok := msgNames[key.Code]
+		if !ok || key.Protocol != ProtocolName {
+			name = fmt.Sprintf("%#02x", key.Code)
+		}
+		stats.Messages[fmt.Sprintf("%s/%d/%s", key.Protocol, key.Version, name)] = &MessageTraffic{
+			Sent:     traffic.Egress,
+			Received: traffic.Ingress,
+		}
+		stats.Sent += traffic.Egress
+		stats.Received += traffic.Ingress
+	}
+	return stats
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereumai/go-ethereumai/consensus/eaiash"
+)
+
+// bombSampleBlocks is the number of recent blocks the network hashrate is
+// averaged over when assessing the impact of the difficulty bomb.
+const bombSampleBlocks = 64
+
+// DifficultyBombStatus reports the impact of the difficulty bomb on the eaiash
+// chain: the seconds it currently adds to the average block time at the network's
+// hashrate, and the number of blocks until it adds at least a second, which is
+// zero once it already does.
+func (s *EthereumAI) DifficultyBombStatus() (delaySeconds float64, blocksUntilNoticeable uint64, err error) {
+	if s.chainConfig.Clique != nil {
+		return 0, 0, errors.New("difficulty bomb requires a proof-of-work chain")
+	}
+	head := s.blockchain.CurrentHeader().Number.Uint64()
+
+	samples := uint64(bombSampleBlocks)
+	if head < samples {
+		samples = head
+	}
+	hashrate, err := s.APIBackend.NetworkHashrate(context.Background(), samples)
+	if err != nil {
+		return 0, 0, err
+	}
+	if hashrate.Sign() == 0 {
+		return 0, 0, errors.New("no network hashrate")
+	}
+	next := new(big.Int).SetUint64(head + 1)
+
+	bomb := new(big.Float).SetInt(eaiash.BombDifficulty(s.chainConfig, next))
+	delaySeconds, _ = bomb.Quo(bomb, new(big.Float).SetInt(hashrate)).Float64()
+
+	// The bomb adds a second once its 2^(period - 2) hashes reach the hashrate
+	noticeable := 2 + uint64(new(big.Int).Sub(hashrate, big.NewInt(1)).BitLen())
+	if period := eaiash.BombPeriod(s.chainConfig, next); period >= noticeable {
+		return delaySeconds, 0, nil
+	}
+	target := eaiash.BombDelay(s.chainConfig, next) + noticeable*eaiash.ExpDiffPeriod
+	return delaySeconds, target - head - 1, nil
+}
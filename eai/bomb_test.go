@@ -0,0 +1,64 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereumai/go-ethereumai/consensus/eaiash"
+	"github.com/ethereumai/go-ethereumai/core"
+	"github.com/ethereumai/go-ethereumai/params"
+)
+
+// Tests that a difficulty bomb still far away reports no delay along with the
+// number of blocks until it outgrows the network hashrate.
+func TestDifficultyBombStatus(t *testing.T) {
+	backend := newTestBackendWithGenesis(t, &core.Genesis{Config: params.TestChainConfig, Difficulty: big.NewInt(1000000)}, nil)
+	defer backend.eai.blockchain.Stop()
+
+	insertTestChain(t, backend, 8, nil)
+	eai := backend.eai
+
+	hashrate, err := eai.APIBackend.NetworkHashrate(context.Background(), 8)
+	if err != nil {
+		t.Fatalf("failed to estimate hashrate: %v", err)
+	}
+	// The bomb adds a second once 2^(period - 2) reaches the hashrate
+	period := uint64(2)
+	for new(big.Int).Lsh(big.NewInt(1), uint(period-2)).Cmp(hashrate) < 0 {
+		period++
+	}
+	want := eaiash.BombDelay(eai.chainConfig, big.NewInt(9)) + period*eaiash.ExpDiffPeriod - 9
+
+	delay, blocks, err := eai.DifficultyBombStatus()
+	if err != nil {
+		t.Fatalf("failed to assess difficulty bomb: %v", err)
+	}
+	if delay != 0 {
+		t.Errorf("delay mismatch: have %v, want 0", delay)
+	}
+	if blocks != want {
+		t.Errorf("blocks until noticeable mismatch: have %d, want %d", blocks, want)
+	}
+	// Proof-of-authority chains have no bomb
+	eai.chainConfig = &params.ChainConfig{Clique: &params.CliqueConfig{Period: 15}}
+	if _, _, err := eai.DifficultyBombStatus(); err == nil {
+		t.Error("clique chain assessed")
+	}
+}
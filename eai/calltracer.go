@@ -0,0 +1,111 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/common/hexutil"
+	"github.com/ethereumai/go-ethereumai/core/vm"
+)
+
+// InternalCall is a message call made by a contract while executing a transaction.
+// The gas used is measured from the caller's side, so it includes the cost of the
+// call instruction itself along with everything the callee consumed.
+type InternalCall struct {
+	Type    string         `json:"type"`
+	Depth   int            `json:"depth"`
+	From    common.Address `json:"from"`
+	To      common.Address `json:"to"`
+	Value   *hexutil.Big   `json:"value"`
+	GasUsed hexutil.Uint64 `json:"gasUsed"`
+}
+
+// pendingCall is an internal call whose callee is still executing.
+type pendingCall struct {
+	index int    // Position of the call in the recorded list
+	depth int    // Depth of the calling frame
+	gas   uint64 // Gas available to the caller before the call instruction
+}
+
+// callGasTracer is a vm.Tracer recording the internal calls of a transaction in
+// the order they were made, along with the gas each of them consumed.
+type callGasTracer struct {
+	calls   []InternalCall
+	pending []pendingCall
+}
+
+// CaptureStart implements vm.Tracer, ignoring the outer transaction call.
+func (t *callGasTracer) CaptureStart(from common.Address, to common.Address, call bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureState implements vm.Tracer, opening a record on every successfully
+// charged call instruction and closing it once execution resumes in the caller.
+func (t *callGasTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	t.settle(depth, gas)
+	if err != nil {
+		return nil
+	}
+	switch op {
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		call := InternalCall{
+			Type:  op.String(),
+			Depth: depth,
+			From:  contract.Address(),
+			To:    common.BigToAddress(stack.Back(1)),
+			Value: new(hexutil.Big),
+		}
+		if op == vm.CALL || op == vm.CALLCODE {
+			call.Value = (*hexutil.Big)(new(big.Int).Set(stack.Back(2)))
+		}
+		t.pending = append(t.pending, pendingCall{index: len(t.calls), depth: depth, gas: gas})
+		t.calls = append(t.calls, call)
+	}
+	return nil
+}
+
+// CaptureFault implements vm.Tracer.
+func (t *callGasTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd implements vm.Tracer, settling any calls whose caller never resumed.
+func (t *callGasTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	t.settle(0, 0)
+	return nil
+}
+
+// settle closes the pending calls made from the given depth or deeper, now that
+// execution is back at the given depth with the given gas available. Calls made
+// from deeper frames belong to callers that faulted, losing all their gas.
+func (t *callGasTracer) settle(depth int, gas uint64) {
+	for len(t.pending) > 0 {
+		last := t.pending[len(t.pending)-1]
+		if last.depth < depth {
+			return
+		}
+		used := last.gas
+		if last.depth == depth && gas <= last.gas {
+			used = last.gas - gas
+		}
+		t.calls[last.index].GasUsed = hexutil.Uint64(used)
+		t.pending = t.pending[:len(t.pending)-1]
+	}
+}
@@ -43,12 +43,14 @@ var DefaultConfig = Config{
 		DatasetsInMem:  1,
 		DatasetsOnDisk: 2,
 	},
-	NetworkId:     1,
-	LightPeers:    100,
-	DatabaseCache: 768,
-	TrieCache:     256,
-	TrieTimeout:   5 * time.Minute,
-	GasPrice:      big.NewInt(5 * params.Shannon),
+	NetworkId:            1,
+	BroadcastRetries:     1,
+	FetcherBodyCacheSize: 256,
+	LightPeers:           100,
+	DatabaseCache:        768,
+	TrieCache:            256,
+	TrieTimeout:          5 * time.Minute,
+	GasPrice:             big.NewInt(5 * params.Shannon),
 
 	TxPool: core.DefaultTxPoolConfig,
 	GPO: gasprice.Config{
@@ -83,10 +85,20 @@ type Config struct {
 	SyncMode  downloader.SyncMode
 	NoPruning bool
 
+	BroadcastRetries     int  // Number of times a failed block propagation to a peer is retried
+	FetcherBodyCacheSize int  // Number of recently fetched block bodies cached by the fetcher (0 = disabled)
+	PreferGossipImport   bool // Import propagated blocks ahead of the downloader's backfill
+
 	// Light client options
 	LightServ  int `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
 	LightPeers int `toml:",omitempty"` // Maximum number of LES client peers
 
+	LightServMaxResponseSize int `toml:",omitempty"` // Maximum size in bytes of a single LES response (0 = default)
+
+	// Retrieval timeout of light client on-demand requests not bounded by the
+	// caller (0 = unlimited)
+	OdrTimeout time.Duration `toml:",omitempty"`
+
 	// Database options
 	SkipBcVersionCheck bool `toml:"-"`
 	DatabaseHandles    int  `toml:"-"`
@@ -100,6 +112,13 @@ type Config struct {
 	ExtraData    []byte         `toml:",omitempty"`
 	GasPrice     *big.Int
 
+	// Time without any sealing activity after which mining is restarted (0 = never)
+	MinerWatchdogTimeout time.Duration `toml:",omitempty"`
+
+	// Whether the etheraibase may fall back to the first wallet account if none
+	// is explicitly configured
+	AllowAutoEtherAIbase bool `toml:",omitempty"`
+
 	// Eaiash options
 	Eaiash eaiash.Config
 
@@ -112,6 +131,33 @@ type Config struct {
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Execution limits of the VM when serving calls (0 = protocol limits)
+	EVMCallMaxDepth  int    `toml:",omitempty"` // Maximum call depth of an RPC call
+	EVMCallMaxMemory uint64 `toml:",omitempty"` // Maximum memory in bytes of a call frame in an RPC call
+
+	// Maximum gas a single RPC call may use (nil or 0 = unlimited)
+	RPCGasCap *big.Int `toml:",omitempty"`
+
+	// Maximum number of trace operations running at once (0 = unlimited)
+	MaxConcurrentTraces int `toml:",omitempty"`
+
+	// Maximum time a block's timestamp may be ahead of the local clock before it
+	// is queued as a future block (0 = protocol default). Only eaiash honours it,
+	// and it's capped at the chain's future block limit (core.MaxFutureBlockTime).
+	FutureBlockTolerance time.Duration `toml:",omitempty"`
+
+	// Interval of the automatic head state exports (0 = disabled) and the directory
+	// they are written into (relative paths are resolved against the data dir)
+	StateExportInterval time.Duration `toml:",omitempty"`
+	StateExportDir      string        `toml:",omitempty"`
+
+	// Number of recent blocks to average the chain data growth over (0 = default)
+	StorageGrowthWindow uint64 `toml:",omitempty"`
+
+	// Relative difficulty change between consecutive blocks considered anomalous,
+	// e.g. 0.1 for 10% (0 = default)
+	DifficultyAnomalyThreshold float64 `toml:",omitempty"`
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 }
@@ -27,17 +27,22 @@ var (
 	headerReqTimer     = metrics.NewRegisteredTimer("eai/downloader/headers/req", nil)
 	headerDropMeter    = metrics.NewRegisteredMeter("eai/downloader/headers/drop", nil)
 	headerTimeoutMeter = metrics.NewRegisteredMeter("eai/downloader/headers/timeout", nil)
+	headerQueuedGauge  = metrics.NewRegisteredGauge("eai/downloader/headers/queued", nil)
 
 	bodyInMeter      = metrics.NewRegisteredMeter("eai/downloader/bodies/in", nil)
 	bodyReqTimer     = metrics.NewRegisteredTimer("eai/downloader/bodies/req", nil)
 	bodyDropMeter    = metrics.NewRegisteredMeter("eai/downloader/bodies/drop", nil)
 	bodyTimeoutMeter = metrics.NewRegisteredMeter("eai/downloader/bodies/timeout", nil)
+	bodyQueuedGauge  = metrics.NewRegisteredGauge("eai/downloader/bodies/queued", nil)
 
 	receiptInMeter      = metrics.NewRegisteredMeter("eai/downloader/receipts/in", nil)
 	receiptReqTimer     = metrics.NewRegisteredTimer("eai/downloader/receipts/req", nil)
 	receiptDropMeter    = metrics.NewRegisteredMeter("eai/downloader/receipts/drop", nil)
 	receiptTimeoutMeter = metrics.NewRegisteredMeter("eai/downloader/receipts/timeout", nil)
+	receiptQueuedGauge  = metrics.NewRegisteredGauge("eai/downloader/receipts/queued", nil)
 
-	stateInMeter   = metrics.NewRegisteredMeter("eai/downloader/states/in", nil)
-	stateDropMeter = metrics.NewRegisteredMeter("eai/downloader/states/drop", nil)
+	stateInMeter    = metrics.NewRegisteredMeter("eai/downloader/states/in", nil)
+	stateReqTimer   = metrics.NewRegisteredTimer("eai/downloader/states/req", nil)
+	stateBytesMeter = metrics.NewRegisteredMeter("eai/downloader/states/bytes", nil)
+	stateDropMeter  = metrics.NewRegisteredMeter("eai/downloader/states/drop", nil)
 )
@@ -122,6 +122,7 @@ func newQueue() *queue {
 func (q *queue) Reset() {
 	q.lock.Lock()
 	defer q.lock.Unlock()
+	defer q.updateQueueGauges()
 
 	q.closed = false
 	q.mode = FullSync
@@ -265,11 +266,26 @@ func (q *queue) resultSlots(pendPool map[string]*fetchRequest, donePool map[comm
 	return limit - finished - pending
 }
 
+// updateQueueGauges refreshes the queued task gauges from the current sizes of
+// the header, body and receipt task queues.
+//
+// Note, this method expects the queue lock to be already held.
+func (q *queue) updateQueueGauges() {
+	if q.headerTaskQueue != nil {
+		headerQueuedGauge.Update(int64(q.headerTaskQueue.Size()))
+	} else {
+		headerQueuedGauge.Update(0)
+	}
+	bodyQueuedGauge.Update(int64(q.blockTaskQueue.Size()))
+	receiptQueuedGauge.Update(int64(q.receiptTaskQueue.Size()))
+}
+
 // ScheduleSkeleton adds a batch of header retrieval tasks to the queue to fill
 // up an already retrieved header skeleton.
 func (q *queue) ScheduleSkeleton(from uint64, skeleton []*types.Header) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
+	defer q.updateQueueGauges()
 
 	// No skeleton retrieval can be in progress, fail hard if so (huge implementation bug)
 	if q.headerResults != nil {
@@ -309,6 +325,7 @@ func (q *queue) RetrieveHeaders() ([]*types.Header, int) {
 func (q *queue) Schedule(headers []*types.Header, from uint64) []*types.Header {
 	q.lock.Lock()
 	defer q.lock.Unlock()
+	defer q.updateQueueGauges()
 
 	// Insert all the headers prioritised by the contained block number
 	inserts := make([]*types.Header, 0, len(headers))
@@ -416,6 +433,7 @@ func (q *queue) countProcessableItems() int {
 func (q *queue) ReserveHeaders(p *peerConnection, count int) *fetchRequest {
 	q.lock.Lock()
 	defer q.lock.Unlock()
+	defer q.updateQueueGauges()
 
 	// Short circuit if the peer's already downloading something (sanity check to
 	// not corrupt state)
@@ -486,6 +504,8 @@ func (q *queue) ReserveReceipts(p *peerConnection, count int) (*fetchRequest, bo
 // to access the queue, so they already need a lock anyway.
 func (q *queue) reserveHeaders(p *peerConnection, count int, taskPool map[common.Hash]*types.Header, taskQueue *prque.Prque,
 	pendPool map[string]*fetchRequest, donePool map[common.Hash]struct{}, isNoop func(*types.Header) bool) (*fetchRequest, bool, error) {
+	defer q.updateQueueGauges()
+
 	// Short circuit if the pool has been depleted, or if the peer's already
 	// downloading something (sanity check not to corrupt state)
 	if taskQueue.Empty() {
@@ -583,6 +603,7 @@ func (q *queue) CancelReceipts(request *fetchRequest) {
 func (q *queue) cancel(request *fetchRequest, taskQueue *prque.Prque, pendPool map[string]*fetchRequest) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
+	defer q.updateQueueGauges()
 
 	if request.From > 0 {
 		taskQueue.Push(request.From, -float32(request.From))
@@ -599,6 +620,7 @@ func (q *queue) cancel(request *fetchRequest, taskQueue *prque.Prque, pendPool m
 func (q *queue) Revoke(peerId string) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
+	defer q.updateQueueGauges()
 
 	if request, ok := q.blockPendPool[peerId]; ok {
 		for _, header := range request.Headers {
@@ -648,6 +670,8 @@ func (q *queue) ExpireReceipts(timeout time.Duration) map[string]int {
 // reason the lock is not obtained in here is because the parameters already need
 // to access the queue, so they already need a lock anyway.
 func (q *queue) expire(timeout time.Duration, pendPool map[string]*fetchRequest, taskQueue *prque.Prque, timeoutMeter metrics.Meter) map[string]int {
+	defer q.updateQueueGauges()
+
 	// Iterate over the expired requests and return each to the queue
 	expiries := make(map[string]int)
 	for id, request := range pendPool {
@@ -683,6 +707,7 @@ func (q *queue) expire(timeout time.Duration, pendPool map[string]*fetchRequest,
 func (q *queue) DeliverHeaders(id string, headers []*types.Header, headerProcCh chan []*types.Header) (int, error) {
 	q.lock.Lock()
 	defer q.lock.Unlock()
+	defer q.updateQueueGauges()
 
 	// Short circuit if the data was never requested
 	request := q.headerPendPool[id]
@@ -804,6 +829,7 @@ func (q *queue) DeliverReceipts(id string, receiptList [][]*types.Receipt) (int,
 func (q *queue) deliver(id string, taskPool map[common.Hash]*types.Header, taskQueue *prque.Prque,
 	pendPool map[string]*fetchRequest, donePool map[common.Hash]struct{}, reqTimer metrics.Timer,
 	results int, reconstruct func(header *types.Header, index int, result *fetchResult) error) (int, error) {
+	defer q.updateQueueGauges()
 
 	// Short circuit if the data was never requested
 	request := pendPool[id]
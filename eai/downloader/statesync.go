@@ -38,6 +38,7 @@ type stateReq struct {
 	tasks    map[common.Hash]*stateTask // Download tasks to track previous attempts
 	timeout  time.Duration              // Maximum round trip time for this to complete
 	timer    *time.Timer                // Timer to fire when the RTT timeout expires
+	sent     time.Time                  // Time the request was handed to the peer
 	peer     *peerConnection            // Peer that we're requesting from
 	response [][]byte                   // Response data of the peer (nil for timeouts)
 	dropped  bool                       // Flag whether the peer dropped off early
@@ -148,6 +149,7 @@ func (d *Downloader) runStateSync(s *stateSync) *stateSync {
 			// Finalize the request and queue up for processing
 			req.timer.Stop()
 			req.response = pack.(*statePack).states
+			stateReqTimer.UpdateSince(req.sent)
 
 			finished = append(finished, req)
 			delete(active, pack.PeerId())
@@ -196,6 +198,7 @@ func (d *Downloader) runStateSync(s *stateSync) *stateSync {
 				finished = append(finished, old)
 			}
 			// Start a timer to notify the sync loop if the peer stalled.
+			req.sent = time.Now()
 			req.timer = time.AfterFunc(req.timeout, func() {
 				select {
 				case timeout <- req:
@@ -417,6 +420,7 @@ func (s *stateSync) process(req *stateReq) error {
 		case nil:
 			s.numUncommitted++
 			s.bytesUncommitted += len(blob)
+			stateBytesMeter.Mark(int64(len(blob)))
 			progress = progress || prog
 		case trie.ErrNotRequested:
 			unexpected++
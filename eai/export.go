@@ -0,0 +1,170 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereumai/go-ethereumai/core/state"
+	"github.com/ethereumai/go-ethereumai/log"
+)
+
+const (
+	stateExportPrefix = "state-" // Filename prefix of the scheduled state exports
+	stateExportSuffix = ".json"  // Filename suffix of the scheduled state exports
+	stateExportRetain = 5        // Number of most recent state exports to keep
+)
+
+// ScheduleStateExport periodically dumps the state of the current head block
+// into a JSON file in dir, keeping only the most recent few exports. The state
+// being exported is pinned in memory, so block imports proceed unhindered. Any
+// previously scheduled export is replaced, a zero interval stops exporting.
+func (s *EthereumAI) ScheduleStateExport(interval time.Duration, dir string) error {
+	if interval < 0 {
+		return errors.New("negative export interval")
+	}
+	if interval > 0 {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.exportStop != nil {
+		close(s.exportStop)
+		s.exportStop = nil
+	}
+	if interval == 0 {
+		return nil
+	}
+	s.exportStop = make(chan struct{})
+	s.exportWg.Add(1)
+	go s.stateExportLoop(interval, dir, s.exportStop)
+
+	log.Info("Scheduled state exports", "interval", interval, "dir", dir)
+	return nil
+}
+
+// stateExportLoop exports the head state into dir on every tick until stopped.
+// An export in progress is aborted by quit, so it never holds up shutdown.
+func (s *EthereumAI) stateExportLoop(interval time.Duration, dir string, quit chan struct{}) {
+	defer s.exportWg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.exportState(dir, quit); err != nil {
+				if err == state.ErrDumpAborted {
+					log.Info("State export aborted", "dir", dir)
+					return
+				}
+				log.Warn("Failed to export state", "dir", dir, "err", err)
+				continue
+			}
+			if err := rotateStateExports(dir, stateExportRetain); err != nil {
+				log.Warn("Failed to rotate state exports", "dir", dir, "err", err)
+			}
+		case <-quit:
+			return
+		}
+	}
+}
+
+// exportState streams the state of the current head block into dir. The file is
+// written under a temporary name first, so partial exports are never rotated in,
+// and is removed if the export fails or is aborted by closing abort.
+func (s *EthereumAI) exportState(dir string, abort <-chan struct{}) error {
+	block := s.blockchain.CurrentBlock()
+
+	release, err := s.blockchain.PinState(block.Root())
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	statedb, err := s.blockchain.StateAt(block.Root())
+	if err != nil {
+		return err
+	}
+	var (
+		name = fmt.Sprintf("%s%012d-%x%s", stateExportPrefix, block.NumberU64(), block.Hash().Bytes()[:4], stateExportSuffix)
+		path = filepath.Join(dir, name)
+		temp = path + ".tmp"
+	)
+	out, err := os.OpenFile(temp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(out)
+	if err := statedb.WriteDump(w, abort); err != nil {
+		out.Close()
+		os.Remove(temp)
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		out.Close()
+		os.Remove(temp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	if err := os.Rename(temp, path); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	stateExportTimeGauge.Update(time.Now().Unix())
+
+	log.Info("Exported state", "number", block.NumberU64(), "hash", block.Hash(), "file", path)
+	return nil
+}
+
+// rotateStateExports deletes all but the most recent keep state exports in dir.
+func rotateStateExports(dir string, keep int) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var exports []string
+	for _, file := range files {
+		if name := file.Name(); strings.HasPrefix(name, stateExportPrefix) && strings.HasSuffix(name, stateExportSuffix) {
+			exports = append(exports, name)
+		}
+	}
+	// Export names start with the zero padded block number, so they sort by age
+	sort.Strings(exports)
+	for len(exports) > keep {
+		if err := os.Remove(filepath.Join(dir, exports[0])); err != nil {
+			return err
+		}
+		exports = exports[1:]
+	}
+	return nil
+}
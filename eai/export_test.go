@@ -0,0 +1,109 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereumai/go-ethereumai/consensus/eaiash"
+	"github.com/ethereumai/go-ethereumai/core"
+	"github.com/ethereumai/go-ethereumai/core/state"
+	"github.com/ethereumai/go-ethereumai/core/vm"
+	"github.com/ethereumai/go-ethereumai/eaidb"
+	"github.com/ethereumai/go-ethereumai/params"
+)
+
+// Tests that the head state is exported into the requested directory and that
+// rotation only retains the most recent exports.
+func TestStateExport(t *testing.T) {
+	var (
+		db    = eaidb.NewMemDatabase()
+		gspec = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+	)
+	blockchain, _ := core.NewBlockChain(db, nil, gspec.Config, eaiash.NewFaker(), vm.Config{})
+	defer blockchain.Stop()
+
+	chain, _ := core.GenerateChain(gspec.Config, genesis, eaiash.NewFaker(), db, 3, nil)
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	dir, err := ioutil.TempDir("", "state-export-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := &EthereumAI{blockchain: blockchain}
+
+	// An aborted export must not leave any partial file behind
+	abort := make(chan struct{})
+	close(abort)
+	if err := s.exportState(dir, abort); err != state.ErrDumpAborted {
+		t.Fatalf("aborted export error mismatch: have %v, want %v", err, state.ErrDumpAborted)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Fatalf("aborted export left %d files behind", len(files))
+	}
+	if err := s.exportState(dir, nil); err != nil {
+		t.Fatalf("failed to export state: %v", err)
+	}
+	head := blockchain.CurrentBlock()
+	name := fmt.Sprintf("state-%012d-%x.json", head.NumberU64(), head.Hash().Bytes()[:4])
+
+	blob, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatalf("failed to read export: %v", err)
+	}
+	var dump state.Dump
+	if err := json.Unmarshal(blob, &dump); err != nil {
+		t.Fatalf("failed to decode export: %v", err)
+	}
+	if dump.Root != fmt.Sprintf("%x", head.Root()) {
+		t.Errorf("export root mismatch: have %s, want %x", dump.Root, head.Root())
+	}
+	if account, ok := dump.Accounts[fmt.Sprintf("%x", testBank)]; !ok || account.Balance != "1000000" {
+		t.Errorf("exported bank account mismatch: have %+v, want balance 1000000", account)
+	}
+	// Add a few older exports and an unrelated file, and check rotation
+	for i := 0; i < 3; i++ {
+		ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("state-%012d-00000000.json", i)), nil, 0644)
+	}
+	ioutil.WriteFile(filepath.Join(dir, "unrelated.json"), nil, 0644)
+
+	if err := rotateStateExports(dir, 2); err != nil {
+		t.Fatalf("failed to rotate exports: %v", err)
+	}
+	files, _ := ioutil.ReadDir(dir)
+	var have []string
+	for _, file := range files {
+		have = append(have, file.Name())
+	}
+	want := []string{"state-000000000002-00000000.json", name, "unrelated.json"}
+	if fmt.Sprint(have) != fmt.Sprint(want) {
+		t.Errorf("retained files mismatch: have %v, want %v", have, want)
+	}
+}
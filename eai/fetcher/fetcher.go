@@ -26,6 +26,7 @@ import (
 	"github.com/ethereumai/go-ethereumai/consensus"
 	"github.com/ethereumai/go-ethereumai/core/types"
 	"github.com/ethereumai/go-ethereumai/log"
+	"github.com/hashicorp/golang-lru"
 	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
 )
 
@@ -37,6 +38,7 @@ const (
 	maxQueueDist  = 32                     // Maximum allowed distance from the chain head to queue
 	hashLimit     = 256                    // Maximum number of unique blocks a peer may have announced
 	blockLimit    = 64                     // Maximum number of unique blocks a peer may have delivered
+	bodyCacheSize = 256                    // Default number of recently fetched block bodies to cache
 )
 
 var (
@@ -75,6 +77,8 @@ type announce struct {
 	header *types.Header // Header of the block partially reassembled (new protocol)
 	time   time.Time     // Timestamp of the announcement
 
+	announced time.Time // Timestamp the block was first announced (kept across header/body arrivals)
+
 	origin string // Identifier of the peer originating the notification
 
 	fetchHeader headerRequesterFn // Fetcher function to retrieve the header of an announced block
@@ -99,8 +103,9 @@ type bodyFilterTask struct {
 
 // inject represents a schedules import operation.
 type inject struct {
-	origin string
-	block  *types.Block
+	origin    string
+	block     *types.Block
+	announced time.Time // Timestamp of the originating announcement (zero for direct broadcasts)
 }
 
 // Fetcher is responsible for accumulating block announcements from various peers
@@ -129,6 +134,9 @@ type Fetcher struct {
 	queues map[string]int          // Per peer block counts to prevent memory exhaustion
 	queued map[common.Hash]*inject // Set of already queued blocks (to dedupe imports)
 
+	// Body cache
+	bodies *lru.Cache // Recently fetched block bodies, keyed by header hash (nil if disabled)
+
 	// Callbacks
 	getBlock       blockRetrievalFn   // Retrieves a block from the local chain
 	verifyHeader   headerVerifierFn   // Checks if a block's headers have a valid proof of work
@@ -147,6 +155,7 @@ type Fetcher struct {
 
 // New creates a block fetcher to retrieve blocks based on hash announcements.
 func New(getBlock blockRetrievalFn, verifyHeader headerVerifierFn, broadcastBlock blockBroadcasterFn, chainHeight chainHeightFn, insertChain chainInsertFn, dropPeer peerDropFn) *Fetcher {
+	bodies, _ := lru.New(bodyCacheSize)
 	return &Fetcher{
 		notify:         make(chan *announce),
 		inject:         make(chan *inject),
@@ -163,6 +172,7 @@ func New(getBlock blockRetrievalFn, verifyHeader headerVerifierFn, broadcastBloc
 		queue:          prque.New(),
 		queues:         make(map[string]int),
 		queued:         make(map[common.Hash]*inject),
+		bodies:         bodies,
 		getBlock:       getBlock,
 		verifyHeader:   verifyHeader,
 		broadcastBlock: broadcastBlock,
@@ -172,6 +182,17 @@ func New(getBlock blockRetrievalFn, verifyHeader headerVerifierFn, broadcastBloc
 	}
 }
 
+// SetBodyCacheSize changes the number of recently fetched block bodies retained
+// to avoid downloading them again when re-announced. A non-positive size disables
+// the cache. It must be called before the fetcher is started.
+func (f *Fetcher) SetBodyCacheSize(size int) {
+	if size <= 0 {
+		f.bodies = nil
+		return
+	}
+	f.bodies, _ = lru.New(size)
+}
+
 // Start boots up the announcement based synchroniser, accepting and processing
 // hash notifications and block fetches until termination requested.
 func (f *Fetcher) Start() {
@@ -192,6 +213,7 @@ func (f *Fetcher) Notify(peer string, hash common.Hash, number uint64, time time
 		hash:        hash,
 		number:      number,
 		time:        time,
+		announced:   time,
 		origin:      peer,
 		fetchHeader: headerFetcher,
 		fetchBodies: bodyFetcher,
@@ -310,7 +332,7 @@ func (f *Fetcher) loop() {
 				f.forgetBlock(hash)
 				continue
 			}
-			f.insert(op.origin, op.block)
+			f.insert(op.origin, op.block, op.announced)
 		}
 		// Wait for an outside event to occur
 		select {
@@ -344,6 +366,9 @@ func (f *Fetcher) loop() {
 				break
 			}
 			f.announces[notification.origin] = count
+			if prev := f.announced[notification.hash]; len(prev) > 0 {
+				notification.announced = prev[0].announced
+			}
 			f.announced[notification.hash] = append(f.announced[notification.hash], notification)
 			if f.announceChangeHook != nil && len(f.announced[notification.hash]) == 1 {
 				f.announceChangeHook(notification.hash, true)
@@ -401,16 +426,30 @@ func (f *Fetcher) loop() {
 		case <-completeTimer.C:
 			// At least one header's timer ran out, retrieve everything
 			request := make(map[string][]common.Hash)
+			cached := []*types.Block{}
 
 			for hash, announces := range f.fetched {
 				// Pick a random peer to retrieve from, reset all others
 				announce := announces[rand.Intn(len(announces))]
 				f.forgetHash(hash)
 
-				// If the block still didn't arrive, queue for completion
+				// If the block still didn't arrive, complete from cache or queue for completion
 				if f.getBlock(hash) == nil {
-					request[announce.origin] = append(request[announce.origin], hash)
 					f.completing[hash] = announce
+					if body := f.cachedBody(hash); body != nil {
+						block := types.NewBlockWithHeader(announce.header).WithBody(body.Transactions, body.Uncles)
+						block.ReceivedAt = announce.time
+
+						cached = append(cached, block)
+						continue
+					}
+					request[announce.origin] = append(request[announce.origin], hash)
+				}
+			}
+			// Schedule any blocks completed from the body cache for import
+			for _, block := range cached {
+				if announce := f.completing[block.Hash()]; announce != nil {
+					f.enqueue(announce.origin, block)
 				}
 			}
 			// Send out all block body requests
@@ -533,6 +572,9 @@ func (f *Fetcher) loop() {
 								block := types.NewBlockWithHeader(announce.header).WithBody(task.transactions[i], task.uncles[i])
 								block.ReceivedAt = task.time
 
+								if f.bodies != nil {
+									f.bodies.Add(hash, block.Body())
+								}
 								blocks = append(blocks, block)
 							} else {
 								f.forgetHash(hash)
@@ -596,6 +638,20 @@ func (f *Fetcher) rescheduleComplete(complete *time.Timer) {
 	complete.Reset(gatherSlack - time.Since(earliest))
 }
 
+// cachedBody retrieves a recently fetched block body from the body cache, or nil
+// if it's not available.
+func (f *Fetcher) cachedBody(hash common.Hash) *types.Body {
+	if f.bodies == nil {
+		return nil
+	}
+	if body, ok := f.bodies.Get(hash); ok {
+		bodyCacheHitMeter.Mark(1)
+		return body.(*types.Body)
+	}
+	bodyCacheMissMeter.Mark(1)
+	return nil
+}
+
 // enqueue schedules a new future import operation, if the block to be imported
 // has not yet been seen.
 func (f *Fetcher) enqueue(peer string, block *types.Block) {
@@ -622,6 +678,9 @@ func (f *Fetcher) enqueue(peer string, block *types.Block) {
 			origin: peer,
 			block:  block,
 		}
+		if announce := f.completing[hash]; announce != nil {
+			op.announced = announce.announced
+		}
 		f.queues[peer] = count
 		f.queued[hash] = op
 		f.queue.Push(op, -float32(block.NumberU64()))
@@ -634,8 +693,9 @@ func (f *Fetcher) enqueue(peer string, block *types.Block) {
 
 // insert spawns a new goroutine to run a block insertion into the chain. If the
 // block's number is at the same height as the current import phase, it updates
-// the phase states accordingly.
-func (f *Fetcher) insert(peer string, block *types.Block) {
+// the phase states accordingly. A non-zero announced time is used to measure the
+// latency from announcement to import.
+func (f *Fetcher) insert(peer string, block *types.Block, announced time.Time) {
 	hash := block.Hash()
 
 	// Run the import on a new thread
@@ -672,6 +732,9 @@ func (f *Fetcher) insert(peer string, block *types.Block) {
 		}
 		// If import succeeded, broadcast the block
 		propAnnounceOutTimer.UpdateSince(block.ReceivedAt)
+		if !announced.IsZero() {
+			propAnnounceLatencyTimer.UpdateSince(announced)
+		}
 		go f.broadcastBlock(block, false)
 
 		// Invoke the testing hook if needed
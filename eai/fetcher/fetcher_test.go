@@ -481,6 +481,45 @@ func testQueueGapFill(t *testing.T, protocol int) {
 	verifyImportCount(t, imported, len(hashes)-1)
 }
 
+// Tests that block bodies already present in the body cache are not retrieved
+// from the network again when their blocks are re-announced.
+func TestCachedBodyCompletion62(t *testing.T) { testCachedBodyCompletion(t, 62) }
+func TestCachedBodyCompletion63(t *testing.T) { testCachedBodyCompletion(t, 63) }
+func TestCachedBodyCompletion64(t *testing.T) { testCachedBodyCompletion(t, 64) }
+
+func testCachedBodyCompletion(t *testing.T, protocol int) {
+	// Create a chain of blocks to import and cache all their bodies
+	targetBlocks := 16
+	hashes, blocks := makeChain(targetBlocks, 0, genesis)
+
+	tester := newTester()
+	for hash, block := range blocks {
+		tester.fetcher.bodies.Add(hash, block.Body())
+	}
+	headerFetcher := tester.makeHeaderFetcher("valid", blocks, -gatherSlack)
+	bodyFetcher := tester.makeBodyFetcher("valid", blocks, 0)
+
+	counter := uint32(0)
+	bodyWrapper := func(hashes []common.Hash) error {
+		atomic.AddUint32(&counter, uint32(len(hashes)))
+		return bodyFetcher(hashes)
+	}
+	// Iteratively announce blocks until all are imported
+	imported := make(chan *types.Block)
+	tester.fetcher.importedHook = func(block *types.Block) { imported <- block }
+
+	for i := len(hashes) - 2; i >= 0; i-- {
+		tester.fetcher.Notify("valid", hashes[i], uint64(len(hashes)-i-1), time.Now().Add(-arriveTimeout), headerFetcher, bodyWrapper)
+		verifyImportEvent(t, imported, true)
+	}
+	verifyImportDone(t, imported)
+
+	// Make sure no bodies were retrieved from the network
+	if fetched := atomic.LoadUint32(&counter); fetched != 0 {
+		t.Fatalf("body retrieval count mismatch: have %v, want %v", fetched, 0)
+	}
+}
+
 // Tests that blocks arriving from various sources (multiple propagations, hash
 // announces, etc) do not get scheduled for import multiple times.
 func TestImportDeduplication62(t *testing.T) { testImportDeduplication(t, 62) }
@@ -23,18 +23,21 @@ import (
 )
 
 var (
-	propAnnounceInMeter   = metrics.NewRegisteredMeter("eai/fetcher/prop/announces/in", nil)
-	propAnnounceOutTimer  = metrics.NewRegisteredTimer("eai/fetcher/prop/announces/out", nil)
-	propAnnounceDropMeter = metrics.NewRegisteredMeter("eai/fetcher/prop/announces/drop", nil)
-	propAnnounceDOSMeter  = metrics.NewRegisteredMeter("eai/fetcher/prop/announces/dos", nil)
+	propAnnounceInMeter      = metrics.NewRegisteredMeter("eai/fetcher/prop/announces/in", nil)
+	propAnnounceOutTimer     = metrics.NewRegisteredTimer("eai/fetcher/prop/announces/out", nil)
+	propAnnounceDropMeter    = metrics.NewRegisteredMeter("eai/fetcher/prop/announces/drop", nil)
+	propAnnounceDOSMeter     = metrics.NewRegisteredMeter("eai/fetcher/prop/announces/dos", nil)
+	propAnnounceLatencyTimer = metrics.NewRegisteredTimer("eai/fetcher/prop/announces/latency", nil)
 
 	propBroadcastInMeter   = metrics.NewRegisteredMeter("eai/fetcher/prop/broadcasts/in", nil)
 	propBroadcastOutTimer  = metrics.NewRegisteredTimer("eai/fetcher/prop/broadcasts/out", nil)
 	propBroadcastDropMeter = metrics.NewRegisteredMeter("eai/fetcher/prop/broadcasts/drop", nil)
 	propBroadcastDOSMeter  = metrics.NewRegisteredMeter("eai/fetcher/prop/broadcasts/dos", nil)
 
-	headerFetchMeter = metrics.NewRegisteredMeter("eai/fetcher/fetch/headers", nil)
-	bodyFetchMeter   = metrics.NewRegisteredMeter("eai/fetcher/fetch/bodies", nil)
+	headerFetchMeter   = metrics.NewRegisteredMeter("eai/fetcher/fetch/headers", nil)
+	bodyFetchMeter     = metrics.NewRegisteredMeter("eai/fetcher/fetch/bodies", nil)
+	bodyCacheHitMeter  = metrics.NewRegisteredMeter("eai/fetcher/fetch/bodies/cache/hit", nil)
+	bodyCacheMissMeter = metrics.NewRegisteredMeter("eai/fetcher/fetch/bodies/cache/miss", nil)
 
 	headerFilterInMeter  = metrics.NewRegisteredMeter("eai/fetcher/filter/headers/in", nil)
 	headerFilterOutMeter = metrics.NewRegisteredMeter("eai/fetcher/filter/headers/out", nil)
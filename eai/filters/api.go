@@ -75,6 +75,13 @@ func NewPublicFilterAPI(backend Backend, lightMode bool) *PublicFilterAPI {
 	return api
 }
 
+// ActiveSubscriptions returns the subscriptions and filters currently installed
+// through the given filter API. It is intentionally not a method of the API, as
+// that would expose the identifiers of all clients over public RPC.
+func ActiveSubscriptions(api *PublicFilterAPI) []SubscriptionInfo {
+	return api.events.Subscriptions()
+}
+
 // timeoutLoop runs every 5 minutes and deletes filters that have not been recently used.
 // Tt is started when the api is created.
 func (api *PublicFilterAPI) timeoutLoop() {
@@ -200,7 +207,12 @@ func (api *PublicFilterAPI) NewBlockFilter() rpc.ID {
 }
 
 // NewHeads send a notification each time a new (header) block is appended to the chain.
-func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+//
+// If options with a positive MaxPending are given, heads are delivered to the
+// client independently of their arrival. Once more than MaxPending heads are
+// waiting for a slow client, they are collapsed into the latest one, which then
+// reports the number of heads dropped in its "skipped" field.
+func (api *PublicFilterAPI) NewHeads(ctx context.Context, opts *NewHeadsOptions) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -208,6 +220,10 @@ func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, er
 
 	rpcSub := notifier.CreateSubscription()
 
+	if opts != nil && opts.MaxPending > 0 {
+		go api.coalescedHeads(notifier, rpcSub, opts.MaxPending)
+		return rpcSub, nil
+	}
 	go func() {
 		headers := make(chan *types.Header)
 		headersSub := api.events.SubscribeNewHeads(headers)
@@ -229,6 +245,119 @@ func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, er
 	return rpcSub, nil
 }
 
+// NewHeadsOptions configures the delivery of a new heads subscription.
+type NewHeadsOptions struct {
+	MaxPending int `json:"maxPending"` // Number of undelivered heads to queue before collapsing them (0 = unbounded)
+}
+
+// headNotification is a chain head delivered to a coalescing subscriber, along
+// with the number of heads dropped before it.
+type headNotification struct {
+	*types.Header
+	Skipped uint64
+}
+
+// MarshalJSON encodes the header, adding the skipped count if any heads were
+// dropped before it.
+func (n *headNotification) MarshalJSON() ([]byte, error) {
+	if n.Skipped == 0 {
+		return json.Marshal(n.Header)
+	}
+	var fields map[string]json.RawMessage
+	enc, err := json.Marshal(n.Header)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(enc, &fields); err != nil {
+		return nil, err
+	}
+	fields["skipped"], _ = json.Marshal(hexutil.Uint64(n.Skipped))
+	return json.Marshal(fields)
+}
+
+// headQueue is a bounded queue of heads awaiting delivery, which collapses its
+// contents into the latest head once its limit is exceeded.
+type headQueue struct {
+	limit int
+	heads []*headNotification
+	lock  sync.Mutex
+	wake  chan struct{} // Signalled whenever a head is added to the queue
+}
+
+func newHeadQueue(limit int) *headQueue {
+	return &headQueue{limit: limit, wake: make(chan struct{}, 1)}
+}
+
+// push adds a new head to the queue, collapsing it if it grew beyond its limit.
+func (q *headQueue) push(header *types.Header) {
+	q.lock.Lock()
+	q.heads = append(q.heads, &headNotification{Header: header})
+	if len(q.heads) > q.limit {
+		last := q.heads[len(q.heads)-1]
+		for _, head := range q.heads[:len(q.heads)-1] {
+			last.Skipped += head.Skipped + 1
+		}
+		q.heads = append(q.heads[:0], last)
+	}
+	q.lock.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pop removes and returns the oldest head in the queue, or nil if it's empty.
+func (q *headQueue) pop() *headNotification {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if len(q.heads) == 0 {
+		return nil
+	}
+	head := q.heads[0]
+	q.heads[0] = nil
+	q.heads = q.heads[1:]
+	return head
+}
+
+// coalescedHeads feeds new heads into a bounded queue drained by a separate
+// goroutine, so a slow client never stalls the event system nor accumulates an
+// unbounded backlog.
+func (api *PublicFilterAPI) coalescedHeads(notifier *rpc.Notifier, rpcSub *rpc.Subscription, limit int) {
+	var (
+		queue      = newHeadQueue(limit)
+		quit       = make(chan struct{})
+		headers    = make(chan *types.Header)
+		headersSub = api.events.SubscribeNewHeads(headers)
+	)
+	defer headersSub.Unsubscribe()
+	defer close(quit)
+
+	go func() {
+		for {
+			select {
+			case <-queue.wake:
+				for head := queue.pop(); head != nil; head = queue.pop() {
+					notifier.Notify(rpcSub.ID, head)
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case h := <-headers:
+			queue.push(h)
+		case <-rpcSub.Err():
+			return
+		case <-notifier.Closed():
+			return
+		}
+	}
+}
+
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
 func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
@@ -19,9 +19,11 @@ package filters
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/core/types"
 	"github.com/ethereumai/go-ethereumai/rpc"
 )
 
@@ -183,3 +185,36 @@ func TestUnmarshalJSONNewFilterArgs(t *testing.T) {
 		t.Fatalf("expected 0 topics, got %d topics", len(test7.Topics[2]))
 	}
 }
+
+// Tests that the head queue of slow subscribers collapses its backlog into the
+// latest head, accounting for all the dropped ones.
+func TestHeadQueueCoalescing(t *testing.T) {
+	queue := newHeadQueue(2)
+	for i := 1; i <= 5; i++ {
+		queue.push(&types.Header{Number: big.NewInt(int64(i))})
+	}
+	head := queue.pop()
+	if head == nil || head.Number.Uint64() != 5 || head.Skipped != 4 {
+		t.Fatalf("collapsed head mismatch: have %v", head)
+	}
+	if head := queue.pop(); head != nil {
+		t.Fatalf("unexpected head after collapse: %v", head)
+	}
+	// Heads within the limit are delivered in order without skipping
+	queue.push(&types.Header{Number: big.NewInt(6)})
+	queue.push(&types.Header{Number: big.NewInt(7)})
+	for want := uint64(6); want <= 7; want++ {
+		if head := queue.pop(); head == nil || head.Number.Uint64() != want || head.Skipped != 0 {
+			t.Fatalf("head %d mismatch: have %v", want, head)
+		}
+	}
+	// The skipped count is only reported if heads were dropped
+	enc, _ := json.Marshal(&headNotification{Header: &types.Header{Number: big.NewInt(8)}, Skipped: 3})
+	var fields map[string]interface{}
+	if err := json.Unmarshal(enc, &fields); err != nil {
+		t.Fatalf("failed to decode notification: %v", err)
+	}
+	if fields["skipped"] != "0x3" || fields["number"] != "0x8" {
+		t.Errorf("notification fields mismatch: %v", fields)
+	}
+}
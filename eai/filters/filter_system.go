@@ -22,6 +22,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -57,6 +58,31 @@ const (
 	LastIndexSubscription
 )
 
+// String implements fmt.Stringer, returning a human readable subscription type.
+func (t Type) String() string {
+	switch t {
+	case LogsSubscription:
+		return "logs"
+	case PendingLogsSubscription:
+		return "pendingLogs"
+	case MinedAndPendingLogsSubscription:
+		return "minedAndPendingLogs"
+	case PendingTransactionsSubscription:
+		return "pendingTransactions"
+	case BlocksSubscription:
+		return "blocks"
+	default:
+		return "unknown"
+	}
+}
+
+// SubscriptionInfo describes a subscription installed in the event system.
+type SubscriptionInfo struct {
+	ID      rpc.ID    `json:"id"`
+	Type    string    `json:"type"`
+	Created time.Time `json:"created"`
+}
+
 const (
 
 	// txChanSize is the size of channel listening to TxPreEvent.
@@ -102,12 +128,14 @@ type EventSystem struct {
 	pendingLogSub *event.TypeMuxSubscription // Subscription for pending log event
 
 	// Channels
-	install   chan *subscription         // install filter for event notification
-	uninstall chan *subscription         // remove filter for event notification
-	txCh      chan core.TxPreEvent       // Channel to receive new transaction event
-	logsCh    chan []*types.Log          // Channel to receive new log event
-	rmLogsCh  chan core.RemovedLogsEvent // Channel to receive removed log event
-	chainCh   chan core.ChainEvent       // Channel to receive new chain event
+	install   chan *subscription           // install filter for event notification
+	uninstall chan *subscription           // remove filter for event notification
+	listing   chan chan []SubscriptionInfo // list installed filters for diagnostics
+	quit      chan struct{}                // closed when the event loop terminates
+	txCh      chan core.TxPreEvent         // Channel to receive new transaction event
+	logsCh    chan []*types.Log            // Channel to receive new log event
+	rmLogsCh  chan core.RemovedLogsEvent   // Channel to receive removed log event
+	chainCh   chan core.ChainEvent         // Channel to receive new chain event
 }
 
 // NewEventSystem creates a new manager that listens for event on the given mux,
@@ -123,6 +151,8 @@ func NewEventSystem(mux *event.TypeMux, backend Backend, lightMode bool) *EventS
 		lightMode: lightMode,
 		install:   make(chan *subscription),
 		uninstall: make(chan *subscription),
+		listing:   make(chan chan []SubscriptionInfo),
+		quit:      make(chan struct{}),
 		txCh:      make(chan core.TxPreEvent, txChanSize),
 		logsCh:    make(chan []*types.Log, logsChanSize),
 		rmLogsCh:  make(chan core.RemovedLogsEvent, rmLogsChanSize),
@@ -192,6 +222,19 @@ func (es *EventSystem) subscribe(sub *subscription) *Subscription {
 	return &Subscription{ID: sub.id, f: sub, es: es}
 }
 
+// Subscriptions returns the details of all the subscriptions currently installed
+// in the event system, including those backing polled filters. Once the event
+// system is stopped, nil is returned.
+func (es *EventSystem) Subscriptions() []SubscriptionInfo {
+	res := make(chan []SubscriptionInfo)
+	select {
+	case es.listing <- res:
+		return <-res
+	case <-es.quit:
+		return nil
+	}
+}
+
 // SubscribeLogs creates a subscription that will write all logs matching the
 // given criteria to the given logs channel. Default value for the from and to
 // block is "latest". If the fromBlock > toBlock an error is returned.
@@ -445,6 +488,7 @@ func (es *EventSystem) lightFilterLogs(header *types.Header, addresses []common.
 func (es *EventSystem) eventLoop() {
 	// Ensure all subscriptions get cleaned up
 	defer func() {
+		close(es.quit)
 		es.pendingLogSub.Unsubscribe()
 		es.txSub.Unsubscribe()
 		es.logsSub.Unsubscribe()
@@ -494,6 +538,21 @@ func (es *EventSystem) eventLoop() {
 			}
 			close(f.err)
 
+		case res := <-es.listing:
+			// Subscriptions for mined and pending logs are indexed twice, report once
+			seen := make(map[rpc.ID]bool)
+			infos := []SubscriptionInfo{}
+			for _, filters := range index {
+				for id, f := range filters {
+					if !seen[id] {
+						seen[id] = true
+						infos = append(infos, SubscriptionInfo{ID: id, Type: f.typ.String(), Created: f.created})
+					}
+				}
+			}
+			sort.Slice(infos, func(i, j int) bool { return infos[i].Created.Before(infos[j].Created) })
+			res <- infos
+
 		// System stopped
 		case <-es.txSub.Err():
 			return
@@ -315,6 +315,55 @@ func TestLogFilterCreation(t *testing.T) {
 	}
 }
 
+// TestActiveSubscriptions tests whether installed filters are reported by the
+// event system, and whether uninstalled ones are omitted.
+func TestActiveSubscriptions(t *testing.T) {
+	var (
+		mux        = new(event.TypeMux)
+		db         = eaidb.NewMemDatabase()
+		txFeed     = new(event.Feed)
+		rmLogsFeed = new(event.Feed)
+		logsFeed   = new(event.Feed)
+		chainFeed  = new(event.Feed)
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		api        = NewPublicFilterAPI(backend, false)
+	)
+	blockID := api.NewBlockFilter()
+	txID := api.NewPendingTransactionFilter()
+	logID, err := api.NewFilter(FilterCriteria{FromBlock: big.NewInt(rpc.LatestBlockNumber.Int64()), ToBlock: big.NewInt(rpc.PendingBlockNumber.Int64())})
+	if err != nil {
+		t.Fatalf("failed to create log filter: %v", err)
+	}
+	want := map[rpc.ID]string{blockID: "blocks", txID: "pendingTransactions", logID: "minedAndPendingLogs"}
+
+	subs := ActiveSubscriptions(api)
+	if len(subs) != len(want) {
+		t.Fatalf("subscription count mismatch: have %d, want %d", len(subs), len(want))
+	}
+	for _, sub := range subs {
+		if typ, ok := want[sub.ID]; !ok || typ != sub.Type {
+			t.Errorf("subscription %s: type mismatch: have %s, want %s", sub.ID, sub.Type, typ)
+		}
+	}
+	api.UninstallFilter(txID)
+	if subs := ActiveSubscriptions(api); len(subs) != len(want)-1 {
+		t.Fatalf("subscription count mismatch after uninstall: have %d, want %d", len(subs), len(want)-1)
+	}
+	// Listing must not block once the event system is stopped
+	mux.Stop()
+
+	done := make(chan []SubscriptionInfo)
+	go func() { done <- ActiveSubscriptions(api) }()
+	select {
+	case subs := <-done:
+		if subs != nil {
+			t.Errorf("stopped event system reported subscriptions: %v", subs)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("listing subscriptions of a stopped event system blocked")
+	}
+}
+
 // TestInvalidLogFilterCreation tests whether invalid filter log criteria results in an error
 // when the filter is created.
 func TestInvalidLogFilterCreation(t *testing.T) {
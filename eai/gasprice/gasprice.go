@@ -18,9 +18,11 @@ package gasprice
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/ethereumai/go-ethereumai/common"
 	"github.com/ethereumai/go-ethereumai/core/types"
@@ -31,6 +33,10 @@ import (
 
 var maxPrice = big.NewInt(5 * params.Shannon)
 
+// percentileCacheTTL is the time a gas price suggestion for a custom percentile
+// is reused for, as long as the head doesn't change.
+const percentileCacheTTL = 3 * time.Second
+
 type Config struct {
 	Blocks     int
 	Percentile int
@@ -48,6 +54,20 @@ type Oracle struct {
 
 	checkBlocks, maxEmpty, maxBlocks int
 	percentile                       int
+
+	percentileCache map[percentileKey]percentileEntry // Suggestions for custom percentiles
+}
+
+// percentileKey identifies a gas price suggestion for a custom percentile.
+type percentileKey struct {
+	head common.Hash
+	pct  int
+}
+
+// percentileEntry is a cached gas price suggestion for a custom percentile.
+type percentileEntry struct {
+	price   *big.Int
+	expires time.Time
 }
 
 // NewOracle returns a new oracle.
@@ -70,9 +90,22 @@ func NewOracle(backend eaiapi.Backend, params Config) *Oracle {
 		maxEmpty:    blocks / 2,
 		maxBlocks:   blocks * 5,
 		percentile:  percent,
+
+		percentileCache: make(map[percentileKey]percentileEntry),
 	}
 }
 
+// SetDefault re-seeds the oracle with a new default price, discarding the cached
+// suggestions so the next requests are recalculated.
+func (gpo *Oracle) SetDefault(price *big.Int) {
+	gpo.cacheLock.Lock()
+	defer gpo.cacheLock.Unlock()
+
+	gpo.lastHead = common.Hash{}
+	gpo.lastPrice = new(big.Int).Set(price)
+	gpo.percentileCache = make(map[percentileKey]percentileEntry)
+}
+
 // SuggestPrice returns the recommended gas price.
 func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	gpo.cacheLock.RLock()
@@ -98,6 +131,63 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 		return lastPrice, nil
 	}
 
+	blockPrices, err := gpo.sampleBlockPrices(ctx, head)
+	if err != nil {
+		return lastPrice, err
+	}
+	price := percentilePrice(blockPrices, gpo.percentile, lastPrice)
+
+	gpo.cacheLock.Lock()
+	gpo.lastHead = headHash
+	gpo.lastPrice = price
+	gpo.cacheLock.Unlock()
+	return price, nil
+}
+
+// SuggestPriceForPercentile returns the gas price at the given percentile of the
+// lowest prices paid in the recent blocks, e.g. a high one for transactions that
+// should be included fast. Suggestions are cached per head and percentile for a
+// short while, so repeated requests don't sample the blocks again.
+func (gpo *Oracle) SuggestPriceForPercentile(ctx context.Context, pct int) (*big.Int, error) {
+	if pct < 0 || pct > 100 {
+		return nil, fmt.Errorf("invalid percentile %d", pct)
+	}
+	head, _ := gpo.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	key := percentileKey{head: head.Hash(), pct: pct}
+
+	gpo.cacheLock.RLock()
+	cached, ok := gpo.percentileCache[key]
+	fallback := gpo.lastPrice
+	gpo.cacheLock.RUnlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.price, nil
+	}
+	gpo.fetchLock.Lock()
+	defer gpo.fetchLock.Unlock()
+
+	blockPrices, err := gpo.sampleBlockPrices(ctx, head)
+	if err != nil {
+		return fallback, err
+	}
+	price := percentilePrice(blockPrices, pct, fallback)
+
+	gpo.cacheLock.Lock()
+	defer gpo.cacheLock.Unlock()
+
+	now := time.Now()
+	for key, cached := range gpo.percentileCache {
+		if !now.Before(cached.expires) {
+			delete(gpo.percentileCache, key)
+		}
+	}
+	gpo.percentileCache[key] = percentileEntry{price: price, expires: now.Add(percentileCacheTTL)}
+	return price, nil
+}
+
+// sampleBlockPrices collects the lowest gas price paid in each of the recent
+// blocks up to the given head, skipping a limited number of empty ones. The
+// prices are returned in ascending order.
+func (gpo *Oracle) sampleBlockPrices(ctx context.Context, head *types.Header) ([]*big.Int, error) {
 	blockNum := head.Number.Uint64()
 	ch := make(chan getBlockPricesResult, gpo.checkBlocks)
 	sent := 0
@@ -113,7 +203,7 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	for exp > 0 {
 		res := <-ch
 		if res.err != nil {
-			return lastPrice, res.err
+			return nil, res.err
 		}
 		exp--
 		if res.price != nil {
@@ -131,20 +221,127 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 			blockNum--
 		}
 	}
-	price := lastPrice
-	if len(blockPrices) > 0 {
-		sort.Sort(bigIntArray(blockPrices))
-		price = blockPrices[(len(blockPrices)-1)*gpo.percentile/100]
+	sort.Sort(bigIntArray(blockPrices))
+	return blockPrices, nil
+}
+
+// percentilePrice picks the price at the given percentile of the sorted prices,
+// capped at the maximum price, or the fallback if there are none.
+func percentilePrice(prices []*big.Int, pct int, fallback *big.Int) *big.Int {
+	price := fallback
+	if len(prices) > 0 {
+		price = prices[(len(prices)-1)*pct/100]
 	}
 	if price.Cmp(maxPrice) > 0 {
 		price = new(big.Int).Set(maxPrice)
 	}
+	return price
+}
 
-	gpo.cacheLock.Lock()
-	gpo.lastHead = headHash
-	gpo.lastPrice = price
-	gpo.cacheLock.Unlock()
-	return price, nil
+// maxFeeHistory is the maximum number of blocks a fee history may span.
+const maxFeeHistory = 1024
+
+// FeeHistory is the gas usage and the gas prices paid in a range of consecutive
+// blocks, with the entries aligned by block starting at the oldest one.
+type FeeHistory struct {
+	OldestBlock  uint64       // Number of the first block in the range
+	GasUsedRatio []float64    // Fraction of the gas limit used by each block
+	Reward       [][]*big.Int // Gas price paid at each requested percentile, per block
+}
+
+// FeeHistory returns the gas used ratio and the gas prices paid at the requested
+// percentiles of the gas used in each of the given number of blocks ending with
+// lastBlock. Percentiles must be within [0, 100] and in ascending order. Empty
+// blocks report zero prices.
+func (gpo *Oracle) FeeHistory(ctx context.Context, blocks int, lastBlock rpc.BlockNumber, percentiles []float64) (*FeeHistory, error) {
+	if blocks < 1 || blocks > maxFeeHistory {
+		return nil, fmt.Errorf("invalid block count %d, must be within [1, %d]", blocks, maxFeeHistory)
+	}
+	for i, p := range percentiles {
+		if p < 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile %v", p)
+		}
+		if i > 0 && p < percentiles[i-1] {
+			return nil, fmt.Errorf("percentiles not in ascending order: %v after %v", p, percentiles[i-1])
+		}
+	}
+	if lastBlock == rpc.PendingBlockNumber {
+		lastBlock = rpc.LatestBlockNumber
+	}
+	head, err := gpo.backend.HeaderByNumber(ctx, lastBlock)
+	if head == nil {
+		if err == nil {
+			err = fmt.Errorf("block %d not found", lastBlock)
+		}
+		return nil, err
+	}
+	last := head.Number.Uint64()
+	if uint64(blocks) > last+1 {
+		blocks = int(last + 1)
+	}
+	history := &FeeHistory{
+		OldestBlock:  last + 1 - uint64(blocks),
+		GasUsedRatio: make([]float64, blocks),
+		Reward:       make([][]*big.Int, blocks),
+	}
+	for i := 0; i < blocks; i++ {
+		block, err := gpo.backend.BlockByNumber(ctx, rpc.BlockNumber(history.OldestBlock+uint64(i)))
+		if block == nil {
+			if err == nil {
+				err = fmt.Errorf("block %d not found", history.OldestBlock+uint64(i))
+			}
+			return nil, err
+		}
+		if limit := block.GasLimit(); limit > 0 {
+			history.GasUsedRatio[i] = float64(block.GasUsed()) / float64(limit)
+		}
+		if history.Reward[i], err = gpo.blockRewards(ctx, block, percentiles); err != nil {
+			return nil, err
+		}
+	}
+	return history, nil
+}
+
+// blockRewards returns the gas prices paid at the given percentiles of the gas
+// used by the transactions of a block.
+func (gpo *Oracle) blockRewards(ctx context.Context, block *types.Block, percentiles []float64) ([]*big.Int, error) {
+	rewards := make([]*big.Int, len(percentiles))
+	txs := block.Transactions()
+	if len(txs) == 0 || len(percentiles) == 0 {
+		for i := range rewards {
+			rewards[i] = new(big.Int)
+		}
+		return rewards, nil
+	}
+	receipts, err := gpo.backend.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) != len(txs) {
+		return nil, fmt.Errorf("receipts of block %d unavailable", block.NumberU64())
+	}
+	// Sort the transactions by price, walking them until the gas used reaches
+	// each percentile of the block's total
+	sorted := make([]int, len(txs))
+	for i := range sorted {
+		sorted[i] = i
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return txs[sorted[i]].GasPrice().Cmp(txs[sorted[j]].GasPrice()) < 0
+	})
+	var (
+		idx     = 0
+		sumUsed = receipts[sorted[0]].GasUsed
+	)
+	for i, p := range percentiles {
+		threshold := uint64(float64(block.GasUsed()) * p / 100)
+		for sumUsed < threshold && idx < len(sorted)-1 {
+			idx++
+			sumUsed += receipts[sorted[idx]].GasUsed
+		}
+		rewards[i] = new(big.Int).Set(txs[sorted[idx]].GasPrice())
+	}
+	return rewards, nil
 }
 
 type getBlockPricesResult struct {
@@ -4,6 +4,7 @@ package eai
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/ethereumai/go-ethereumai/common"
 	"github.com/ethereumai/go-ethereumai/common/hexutil"
@@ -17,30 +18,51 @@ var _ = (*configMarshaling)(nil)
 
 func (c Config) MarshalTOML() (interface{}, error) {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               uint64
-		SyncMode                downloader.SyncMode
-		LightServ               int  `toml:",omitempty"`
-		LightPeers              int  `toml:",omitempty"`
-		SkipBcVersionCheck      bool `toml:"-"`
-		DatabaseHandles         int  `toml:"-"`
-		DatabaseCache           int
-		EtherAIbase               common.Address `toml:",omitempty"`
-		MinerThreads            int            `toml:",omitempty"`
-		ExtraData               hexutil.Bytes  `toml:",omitempty"`
-		GasPrice                *big.Int
-		Eaiash                  eaiash.Config
-		TxPool                  core.TxPoolConfig
-		GPO                     gasprice.Config
-		EnablePreimageRecording bool
-		DocRoot                 string `toml:"-"`
+		Genesis                    *core.Genesis `toml:",omitempty"`
+		NetworkId                  uint64
+		SyncMode                   downloader.SyncMode
+		BroadcastRetries           int
+		FetcherBodyCacheSize       int
+		PreferGossipImport         bool
+		LightServ                  int           `toml:",omitempty"`
+		LightPeers                 int           `toml:",omitempty"`
+		LightServMaxResponseSize   int           `toml:",omitempty"`
+		OdrTimeout                 time.Duration `toml:",omitempty"`
+		SkipBcVersionCheck         bool          `toml:"-"`
+		DatabaseHandles            int           `toml:"-"`
+		DatabaseCache              int
+		EtherAIbase                common.Address `toml:",omitempty"`
+		MinerThreads               int            `toml:",omitempty"`
+		ExtraData                  hexutil.Bytes  `toml:",omitempty"`
+		GasPrice                   *big.Int
+		MinerWatchdogTimeout       time.Duration `toml:",omitempty"`
+		AllowAutoEtherAIbase       bool          `toml:",omitempty"`
+		Eaiash                     eaiash.Config
+		TxPool                     core.TxPoolConfig
+		GPO                        gasprice.Config
+		EnablePreimageRecording    bool
+		EVMCallMaxDepth            int           `toml:",omitempty"`
+		EVMCallMaxMemory           uint64        `toml:",omitempty"`
+		RPCGasCap                  *big.Int      `toml:",omitempty"`
+		MaxConcurrentTraces        int           `toml:",omitempty"`
+		FutureBlockTolerance       time.Duration `toml:",omitempty"`
+		StateExportInterval        time.Duration `toml:",omitempty"`
+		StateExportDir             string        `toml:",omitempty"`
+		StorageGrowthWindow        uint64        `toml:",omitempty"`
+		DifficultyAnomalyThreshold float64       `toml:",omitempty"`
+		DocRoot                    string        `toml:"-"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
 	enc.NetworkId = c.NetworkId
 	enc.SyncMode = c.SyncMode
+	enc.BroadcastRetries = c.BroadcastRetries
+	enc.FetcherBodyCacheSize = c.FetcherBodyCacheSize
+	enc.PreferGossipImport = c.PreferGossipImport
 	enc.LightServ = c.LightServ
 	enc.LightPeers = c.LightPeers
+	enc.LightServMaxResponseSize = c.LightServMaxResponseSize
+	enc.OdrTimeout = c.OdrTimeout
 	enc.SkipBcVersionCheck = c.SkipBcVersionCheck
 	enc.DatabaseHandles = c.DatabaseHandles
 	enc.DatabaseCache = c.DatabaseCache
@@ -48,33 +70,60 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.MinerThreads = c.MinerThreads
 	enc.ExtraData = c.ExtraData
 	enc.GasPrice = c.GasPrice
+	enc.MinerWatchdogTimeout = c.MinerWatchdogTimeout
+	enc.AllowAutoEtherAIbase = c.AllowAutoEtherAIbase
 	enc.Eaiash = c.Eaiash
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
+	enc.EVMCallMaxDepth = c.EVMCallMaxDepth
+	enc.EVMCallMaxMemory = c.EVMCallMaxMemory
+	enc.RPCGasCap = c.RPCGasCap
+	enc.MaxConcurrentTraces = c.MaxConcurrentTraces
+	enc.FutureBlockTolerance = c.FutureBlockTolerance
+	enc.StateExportInterval = c.StateExportInterval
+	enc.StateExportDir = c.StateExportDir
+	enc.StorageGrowthWindow = c.StorageGrowthWindow
+	enc.DifficultyAnomalyThreshold = c.DifficultyAnomalyThreshold
 	enc.DocRoot = c.DocRoot
 	return &enc, nil
 }
 
 func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               *uint64
-		SyncMode                *downloader.SyncMode
-		LightServ               *int  `toml:",omitempty"`
-		LightPeers              *int  `toml:",omitempty"`
-		SkipBcVersionCheck      *bool `toml:"-"`
-		DatabaseHandles         *int  `toml:"-"`
-		DatabaseCache           *int
-		EtherAIbase               *common.Address `toml:",omitempty"`
-		MinerThreads            *int            `toml:",omitempty"`
-		ExtraData               *hexutil.Bytes  `toml:",omitempty"`
-		GasPrice                *big.Int
-		Eaiash                  *eaiash.Config
-		TxPool                  *core.TxPoolConfig
-		GPO                     *gasprice.Config
-		EnablePreimageRecording *bool
-		DocRoot                 *string `toml:"-"`
+		Genesis                    *core.Genesis `toml:",omitempty"`
+		NetworkId                  *uint64
+		SyncMode                   *downloader.SyncMode
+		BroadcastRetries           *int
+		FetcherBodyCacheSize       *int
+		PreferGossipImport         *bool
+		LightServ                  *int           `toml:",omitempty"`
+		LightPeers                 *int           `toml:",omitempty"`
+		LightServMaxResponseSize   *int           `toml:",omitempty"`
+		OdrTimeout                 *time.Duration `toml:",omitempty"`
+		SkipBcVersionCheck         *bool          `toml:"-"`
+		DatabaseHandles            *int           `toml:"-"`
+		DatabaseCache              *int
+		EtherAIbase                *common.Address `toml:",omitempty"`
+		MinerThreads               *int            `toml:",omitempty"`
+		ExtraData                  *hexutil.Bytes  `toml:",omitempty"`
+		GasPrice                   *big.Int
+		MinerWatchdogTimeout       *time.Duration `toml:",omitempty"`
+		AllowAutoEtherAIbase       *bool          `toml:",omitempty"`
+		Eaiash                     *eaiash.Config
+		TxPool                     *core.TxPoolConfig
+		GPO                        *gasprice.Config
+		EnablePreimageRecording    *bool
+		EVMCallMaxDepth            *int           `toml:",omitempty"`
+		EVMCallMaxMemory           *uint64        `toml:",omitempty"`
+		RPCGasCap                  *big.Int       `toml:",omitempty"`
+		MaxConcurrentTraces        *int           `toml:",omitempty"`
+		FutureBlockTolerance       *time.Duration `toml:",omitempty"`
+		StateExportInterval        *time.Duration `toml:",omitempty"`
+		StateExportDir             *string        `toml:",omitempty"`
+		StorageGrowthWindow        *uint64        `toml:",omitempty"`
+		DifficultyAnomalyThreshold *float64       `toml:",omitempty"`
+		DocRoot                    *string        `toml:"-"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -89,12 +138,27 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.SyncMode != nil {
 		c.SyncMode = *dec.SyncMode
 	}
+	if dec.BroadcastRetries != nil {
+		c.BroadcastRetries = *dec.BroadcastRetries
+	}
+	if dec.FetcherBodyCacheSize != nil {
+		c.FetcherBodyCacheSize = *dec.FetcherBodyCacheSize
+	}
+	if dec.PreferGossipImport != nil {
+		c.PreferGossipImport = *dec.PreferGossipImport
+	}
 	if dec.LightServ != nil {
 		c.LightServ = *dec.LightServ
 	}
 	if dec.LightPeers != nil {
 		c.LightPeers = *dec.LightPeers
 	}
+	if dec.LightServMaxResponseSize != nil {
+		c.LightServMaxResponseSize = *dec.LightServMaxResponseSize
+	}
+	if dec.OdrTimeout != nil {
+		c.OdrTimeout = *dec.OdrTimeout
+	}
 	if dec.SkipBcVersionCheck != nil {
 		c.SkipBcVersionCheck = *dec.SkipBcVersionCheck
 	}
@@ -116,6 +180,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.GasPrice != nil {
 		c.GasPrice = dec.GasPrice
 	}
+	if dec.MinerWatchdogTimeout != nil {
+		c.MinerWatchdogTimeout = *dec.MinerWatchdogTimeout
+	}
+	if dec.AllowAutoEtherAIbase != nil {
+		c.AllowAutoEtherAIbase = *dec.AllowAutoEtherAIbase
+	}
 	if dec.Eaiash != nil {
 		c.Eaiash = *dec.Eaiash
 	}
@@ -128,6 +198,33 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
+	if dec.EVMCallMaxDepth != nil {
+		c.EVMCallMaxDepth = *dec.EVMCallMaxDepth
+	}
+	if dec.EVMCallMaxMemory != nil {
+		c.EVMCallMaxMemory = *dec.EVMCallMaxMemory
+	}
+	if dec.RPCGasCap != nil {
+		c.RPCGasCap = dec.RPCGasCap
+	}
+	if dec.MaxConcurrentTraces != nil {
+		c.MaxConcurrentTraces = *dec.MaxConcurrentTraces
+	}
+	if dec.FutureBlockTolerance != nil {
+		c.FutureBlockTolerance = *dec.FutureBlockTolerance
+	}
+	if dec.StateExportInterval != nil {
+		c.StateExportInterval = *dec.StateExportInterval
+	}
+	if dec.StateExportDir != nil {
+		c.StateExportDir = *dec.StateExportDir
+	}
+	if dec.StorageGrowthWindow != nil {
+		c.StorageGrowthWindow = *dec.StorageGrowthWindow
+	}
+	if dec.DifficultyAnomalyThreshold != nil {
+		c.DifficultyAnomalyThreshold = *dec.DifficultyAnomalyThreshold
+	}
 	if dec.DocRoot != nil {
 		c.DocRoot = *dec.DocRoot
 	}
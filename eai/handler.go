@@ -74,9 +74,12 @@ type ProtocolManager struct {
 	chainconfig *params.ChainConfig
 	maxPeers    int
 
-	downloader *downloader.Downloader
-	fetcher    *fetcher.Fetcher
-	peers      *peerSet
+	broadcastRetries int // Number of times a failed block propagation is retried per peer
+
+	downloader     *downloader.Downloader
+	fetcher        *fetcher.Fetcher
+	importPriority *importPriority // Arbiter between propagated and downloaded block imports
+	peers          *peerSet
 
 	SubProtocols []p2p.Protocol
 
@@ -85,6 +88,9 @@ type ProtocolManager struct {
 	txSub         event.Subscription
 	minedBlockSub *event.TypeMuxSubscription
 
+	minedSink     chan<- *types.Block // Optional external consumer of locally mined blocks
+	minedSinkLock sync.Mutex          // Protects the mined block sink
+
 	// channels for fetcher, syncer, txsyncLoop
 	newPeerCh   chan *peer
 	txsyncCh    chan *txsync
@@ -101,16 +107,17 @@ type ProtocolManager struct {
 func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, networkId uint64, mux *event.TypeMux, txpool txPool, engine consensus.Engine, blockchain *core.BlockChain, chaindb eaidb.Database) (*ProtocolManager, error) {
 	// Create the protocol manager with the base fields
 	manager := &ProtocolManager{
-		networkId:   networkId,
-		eventMux:    mux,
-		txpool:      txpool,
-		blockchain:  blockchain,
-		chainconfig: config,
-		peers:       newPeerSet(),
-		newPeerCh:   make(chan *peer),
-		noMorePeers: make(chan struct{}),
-		txsyncCh:    make(chan *txsync),
-		quitSync:    make(chan struct{}),
+		networkId:      networkId,
+		eventMux:       mux,
+		txpool:         txpool,
+		blockchain:     blockchain,
+		chainconfig:    config,
+		peers:          newPeerSet(),
+		importPriority: newImportPriority(),
+		newPeerCh:      make(chan *peer),
+		noMorePeers:    make(chan struct{}),
+		txsyncCh:       make(chan *txsync),
+		quitSync:       make(chan struct{}),
 	}
 	// Figure out whether to allow fast sync or not
 	if mode == downloader.FastSync && blockchain.CurrentBlock().NumberU64() > 0 {
@@ -159,7 +166,7 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		return nil, errIncompatibleConfig
 	}
 	// Construct the different synchronisation mechanisms
-	manager.downloader = downloader.New(mode, chaindb, manager.eventMux, blockchain, nil, manager.removePeer)
+	manager.downloader = downloader.New(mode, chaindb, manager.eventMux, &prioritisedChain{blockchain, manager.importPriority}, nil, manager.removePeer)
 
 	validator := func(header *types.Header) error {
 		return engine.VerifyHeader(blockchain, header, true)
@@ -174,6 +181,10 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 			return 0, nil
 		}
 		atomic.StoreUint32(&manager.acceptTxs, 1) // Mark initial sync done on any fetcher import
+
+		manager.importPriority.gossipStarted()
+		defer manager.importPriority.gossipDone()
+
 		return manager.blockchain.InsertChain(blocks)
 	}
 	manager.fetcher = fetcher.New(blockchain.GetBlockByHash, validator, manager.BroadcastBlock, heighter, inserter, manager.removePeer)
@@ -194,6 +205,9 @@ func (pm *ProtocolManager) removePeer(id string) {
 	if err := pm.peers.Unregister(id); err != nil {
 		log.Error("Peer removal failed", "peer", id, "err", err)
 	}
+	// Stop any message tracing, it is not carried over to a reconnection
+	peer.SetTracing(false)
+
 	// Hard disconnect at the networking layer
 	if peer != nil {
 		peer.Peer.Disconnect(p2p.DiscUselessPeer)
@@ -243,7 +257,11 @@ func (pm *ProtocolManager) Stop() {
 }
 
 func (pm *ProtocolManager) newPeer(pv int, p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
-	return newPeer(pv, p, newMeteredMsgWriter(rw))
+	tracer := &tracingMsgReadWriter{MsgReadWriter: rw}
+
+	peer := newPeer(pv, p, newMeteredMsgWriter(tracer))
+	tracer.peer, peer.tracer = peer.id, tracer
+	return peer
 }
 
 // handle is the callback invoked to manage the life cycle of an eai peer. When
@@ -633,6 +651,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 
 		// Mark the peer as owning the block and schedule it for import
 		p.MarkBlock(request.Block.Hash())
+		p.MarkBlockArrival(request.Block, msg.ReceivedAt)
 		pm.fetcher.Enqueue(p.id, request.Block)
 
 		// Assuming the block is importable by the peer, but possibly not yet done so,
@@ -698,7 +717,7 @@ func (pm *ProtocolManager) BroadcastBlock(block *types.Block, propagate bool) {
 		// Send the block to a subset of our peers
 		transfer := peers[:int(math.Sqrt(float64(len(peers))))]
 		for _, peer := range transfer {
-			peer.SendNewBlock(block, td)
+			pm.sendNewBlock(peer, block, td)
 		}
 		log.Trace("Propagated block", "hash", hash, "recipients", len(transfer), "duration", common.PrettyDuration(time.Since(block.ReceivedAt)))
 		return
@@ -712,6 +731,23 @@ func (pm *ProtocolManager) BroadcastBlock(block *types.Block, propagate bool) {
 	}
 }
 
+// sendNewBlock propagates a block to a single peer, retrying failed writes up to
+// the configured number of times before giving up on the peer.
+func (pm *ProtocolManager) sendNewBlock(p *peer, block *types.Block, td *big.Int) {
+	for attempt := 0; ; attempt++ {
+		err := p.SendNewBlock(block, td)
+		if err == nil {
+			return
+		}
+		if attempt >= pm.broadcastRetries {
+			propBlockFailMeter.Mark(1)
+			p.Log().Debug("Failed to propagate block", "number", block.Number(), "hash", block.Hash(), "err", err)
+			return
+		}
+		propBlockRetryMeter.Mark(1)
+	}
+}
+
 // BroadcastTx will propagate a transaction to all peers which are not known to
 // already have the given transaction.
 func (pm *ProtocolManager) BroadcastTx(hash common.Hash, tx *types.Transaction) {
@@ -730,12 +766,39 @@ func (pm *ProtocolManager) minedBroadcastLoop() {
 	for obj := range pm.minedBlockSub.Chan() {
 		switch ev := obj.Data.(type) {
 		case core.NewMinedBlockEvent:
+			pm.feedMinedSink(ev.Block)
 			pm.BroadcastBlock(ev.Block, true)  // First propagate block to peers
 			pm.BroadcastBlock(ev.Block, false) // Only then announce to the rest
 		}
 	}
 }
 
+// setMinedSink installs the channel receiving every locally mined block, or
+// removes the current one if ch is nil.
+func (pm *ProtocolManager) setMinedSink(ch chan<- *types.Block) {
+	pm.minedSinkLock.Lock()
+	defer pm.minedSinkLock.Unlock()
+
+	pm.minedSink = ch
+}
+
+// feedMinedSink delivers a freshly mined block to the external sink, if any. A
+// consumer not keeping up has the block dropped instead of stalling the miner.
+func (pm *ProtocolManager) feedMinedSink(block *types.Block) {
+	pm.minedSinkLock.Lock()
+	defer pm.minedSinkLock.Unlock()
+
+	if pm.minedSink == nil {
+		return
+	}
+	select {
+	case pm.minedSink <- block:
+	default:
+		minedSinkDropMeter.Mark(1)
+		log.Debug("Mined block sink full, dropping block", "number", block.Number(), "hash", block.Hash())
+	}
+}
+
 func (pm *ProtocolManager) txBroadcastLoop() {
 	for {
 		select {
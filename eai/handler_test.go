@@ -17,9 +17,12 @@
 package eai
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"math/rand"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -34,6 +37,7 @@ import (
 	"github.com/ethereumai/go-ethereumai/eaidb"
 	"github.com/ethereumai/go-ethereumai/event"
 	"github.com/ethereumai/go-ethereumai/p2p"
+	"github.com/ethereumai/go-ethereumai/p2p/discover"
 	"github.com/ethereumai/go-ethereumai/params"
 )
 
@@ -520,3 +524,134 @@ func testDAOChallenge(t *testing.T, localForked, remoteForked bool, timeout bool
 		}
 	}
 }
+
+// Tests that locally mined blocks are delivered to the registered sink and that
+// a consumer not keeping up gets blocks dropped instead of stalling delivery.
+func TestMinedBlockSink(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 2, nil, nil)
+	defer pm.Stop()
+
+	sink := make(chan *types.Block, 1)
+	pm.setMinedSink(sink)
+
+	first, second := pm.blockchain.GetBlockByNumber(1), pm.blockchain.GetBlockByNumber(2)
+	pm.feedMinedSink(first)
+	pm.feedMinedSink(second) // sink full, must not block
+
+	if block := <-sink; block.Hash() != first.Hash() {
+		t.Fatalf("sink block mismatch: have %x, want %x", block.Hash(), first.Hash())
+	}
+	select {
+	case block := <-sink:
+		t.Fatalf("unexpected block delivered: %x", block.Hash())
+	default:
+	}
+	// Removing the sink should stop any further deliveries
+	pm.setMinedSink(nil)
+	pm.feedMinedSink(first)
+	if len(sink) != 0 {
+		t.Fatalf("block delivered after sink removal")
+	}
+}
+
+// Tests that the clock skew of a peer is estimated from the quickest arrival of
+// its recent blocks, only retaining a limited number of them.
+func TestPeerClockSkew(t *testing.T) {
+	p := &peer{}
+	if _, ok := p.ClockSkew(); ok {
+		t.Fatalf("clock skew estimated without blocks")
+	}
+	stamp := time.Unix(1000000, 0)
+	block := types.NewBlockWithHeader(&types.Header{Time: big.NewInt(stamp.Unix())})
+
+	p.MarkBlockArrival(block, stamp.Add(-2*time.Second))
+	for i := 0; i < maxBlockDelays; i++ {
+		p.MarkBlockArrival(block, stamp.Add(3*time.Second+time.Duration(i)*time.Millisecond))
+	}
+	// The early arrival was dropped, the quickest retained one counts
+	if skew, ok := p.ClockSkew(); !ok || skew != 3*time.Second {
+		t.Errorf("clock skew mismatch: have %v, want %v", skew, 3*time.Second)
+	}
+}
+
+// failingMsgWriter is a message pipe failing a given number of writes before
+// accepting any, counting the attempts.
+type failingMsgWriter struct {
+	fails  int
+	writes int
+}
+
+func (rw *failingMsgWriter) ReadMsg() (p2p.Msg, error) { return p2p.Msg{}, errors.New("not readable") }
+
+func (rw *failingMsgWriter) WriteMsg(msg p2p.Msg) error {
+	rw.writes++
+	if rw.writes <= rw.fails {
+		return errors.New("write failed")
+	}
+	return nil
+}
+
+// Tests that failed block propagations are retried the configured number of times.
+func TestBlockPropagationRetries(t *testing.T) {
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1)})
+
+	tests := []struct {
+		retries int
+		fails   int
+		writes  int
+	}{
+		{0, 0, 1}, // Successful write needs no retries
+		{0, 1, 1}, // Disabled retries give up on the first failure
+		{2, 1, 2}, // Transient failure is recovered from
+		{2, 5, 3}, // Permanent failure is given up after the retries
+	}
+	for i, tt := range tests {
+		rw := &failingMsgWriter{fails: tt.fails}
+		pm := &ProtocolManager{broadcastRetries: tt.retries}
+		pm.sendNewBlock(newPeer(63, p2p.NewPeer(discover.NodeID{}, "test", nil), rw), block, big.NewInt(1))
+
+		if rw.writes != tt.writes {
+			t.Errorf("test %d: write attempts mismatch: have %d, want %d", i, rw.writes, tt.writes)
+		}
+	}
+}
+
+// Tests that peers are looked up by their full node ID, distinguishing peers that
+// share the short identifier prefix, and that tracing can be toggled on them.
+func TestPeerByNodeID(t *testing.T) {
+	var id1, id2 discover.NodeID
+	id1[0], id2[0] = 0x01, 0x01
+	id2[len(id2)-1] = 0x02
+
+	ps := newPeerSet()
+	p1 := newPeer(63, p2p.NewPeer(id1, "peer1", nil), nil)
+	p2 := newPeer(63, p2p.NewPeer(id2, "peer2", nil), nil)
+	p2.id = "other" // Short identifiers collide, register under distinct keys
+	for _, p := range []*peer{p1, p2} {
+		if err := ps.Register(p); err != nil {
+			t.Fatalf("failed to register peer: %v", err)
+		}
+	}
+	if p := ps.PeerByNodeID(fmt.Sprintf("%x", id2[:])); p != p2 {
+		t.Errorf("full ID lookup mismatch: have %v, want %v", p, p2)
+	}
+	if p := ps.PeerByNodeID(p1.id); p != p1 {
+		t.Errorf("short ID lookup mismatch: have %v, want %v", p, p1)
+	}
+	unknown := fmt.Sprintf("%x", id1[:8]) + "00"
+	if p := ps.PeerByNodeID(unknown); p != nil {
+		t.Errorf("unknown ID resolved to %v", p)
+	}
+	// Tracing is only available on peers with a tracer
+	if err := p1.SetTracing(true); err == nil {
+		t.Errorf("tracing enabled on an untraceable peer")
+	}
+	pm := &ProtocolManager{}
+	traced := pm.newPeer(63, p2p.NewPeer(id1, "traced", nil), new(failingMsgWriter))
+	if err := traced.SetTracing(true); err != nil {
+		t.Fatalf("failed to enable tracing: %v", err)
+	}
+	if atomic.LoadInt32(&traced.tracer.enabled) != 1 {
+		t.Errorf("tracing not enabled")
+	}
+}
@@ -86,6 +86,73 @@ func newTestProtocolManagerMust(t *testing.T, mode downloader.SyncMode, blocks i
 	return pm, db
 }
 
+// testTxPoolConfig is a transaction pool configuration without stateful disk
+// sideeffects used during testing.
+var testTxPoolConfig core.TxPoolConfig
+
+func init() {
+	testTxPoolConfig = core.DefaultTxPoolConfig
+	testTxPoolConfig.Journal = ""
+}
+
+// newTestBackend creates an API backend on top of a fresh in-memory chain whose
+// genesis block allocates the given accounts, sealed by a faker engine. The
+// caller is responsible for stopping the backend's chain.
+func newTestBackend(t *testing.T, alloc core.GenesisAlloc) *EaiAPIBackend {
+	return newTestBackendWithGenesis(t, &core.Genesis{Config: params.TestChainConfig, Alloc: alloc}, nil)
+}
+
+// newTestBackendWithGenesis creates an API backend on top of a fresh in-memory
+// chain starting with the given genesis block, using the given cache config.
+func newTestBackendWithGenesis(t *testing.T, gspec *core.Genesis, cacheConfig *core.CacheConfig) *EaiAPIBackend {
+	db := eaidb.NewMemDatabase()
+	gspec.MustCommit(db)
+	return openTestBackend(t, db, gspec.Config, cacheConfig)
+}
+
+// openTestBackend creates an API backend on top of the chain already committed
+// to the given database.
+func openTestBackend(t *testing.T, db eaidb.Database, config *params.ChainConfig, cacheConfig *core.CacheConfig) *EaiAPIBackend {
+	engine := eaiash.NewFaker()
+	blockchain, err := core.NewBlockChain(db, cacheConfig, config, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	eai := &EthereumAI{
+		config:      &Config{},
+		chainDb:     db,
+		blockchain:  blockchain,
+		chainConfig: config,
+		engine:      engine,
+	}
+	eai.APIBackend = &EaiAPIBackend{eai: eai}
+	return eai.APIBackend
+}
+
+// newTestTxPool attaches a fresh transaction pool to the backend's chain. The
+// caller is responsible for stopping the pool.
+func newTestTxPool(b *EaiAPIBackend) *core.TxPool {
+	b.eai.txPool = core.NewTxPool(testTxPoolConfig, b.eai.chainConfig, b.eai.blockchain)
+	return b.eai.txPool
+}
+
+// generateTestChain generates n blocks on top of the backend's head using the
+// given generator, without importing them.
+func generateTestChain(b *EaiAPIBackend, n int, gen func(int, *core.BlockGen)) []*types.Block {
+	chain, _ := core.GenerateChain(b.eai.chainConfig, b.eai.blockchain.CurrentBlock(), b.eai.engine, b.eai.chainDb, n, gen)
+	return chain
+}
+
+// insertTestChain generates n blocks on top of the backend's head using the
+// given generator and imports them into its chain.
+func insertTestChain(t *testing.T, b *EaiAPIBackend, n int, gen func(int, *core.BlockGen)) []*types.Block {
+	chain := generateTestChain(b, n, gen)
+	if _, err := b.eai.blockchain.InsertChain(chain); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return chain
+}
+
 // testTxPool is a fake, helper transaction pool for testing purposes
 type testTxPool struct {
 	txFeed event.Feed
@@ -0,0 +1,117 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereumai/go-ethereumai/core"
+	"github.com/ethereumai/go-ethereumai/core/types"
+)
+
+// gossipYieldBatch is the number of downloaded blocks imported at once before
+// yielding to propagated blocks waiting for import, if those are preferred.
+const gossipYieldBatch = 64
+
+// importPriority arbitrates between the block imports of the fetcher and the
+// downloader, optionally letting propagated blocks near the chain tip jump ahead
+// of the downloader's backfill.
+type importPriority struct {
+	preferred uint32 // Flag whether propagated blocks are imported first (atomic)
+
+	pending int        // Number of propagated block batches being imported
+	lock    sync.Mutex // Protects the pending counter
+	done    *sync.Cond // Signals the completion of all propagated imports
+}
+
+// newImportPriority creates an import arbiter, not preferring any source.
+func newImportPriority() *importPriority {
+	prio := new(importPriority)
+	prio.done = sync.NewCond(&prio.lock)
+	return prio
+}
+
+// setPreferred sets whether propagated blocks are imported ahead of the
+// downloaded ones.
+func (prio *importPriority) setPreferred(prefer bool) {
+	if prefer {
+		atomic.StoreUint32(&prio.preferred, 1)
+		importGossipPreferredGauge.Update(1)
+	} else {
+		atomic.StoreUint32(&prio.preferred, 0)
+		importGossipPreferredGauge.Update(0)
+	}
+}
+
+// gossipStarted marks the start of importing propagated blocks.
+func (prio *importPriority) gossipStarted() {
+	prio.lock.Lock()
+	defer prio.lock.Unlock()
+
+	prio.pending++
+}
+
+// gossipDone marks the end of importing propagated blocks, releasing any waiting
+// downloader imports once none are left.
+func (prio *importPriority) gossipDone() {
+	prio.lock.Lock()
+	defer prio.lock.Unlock()
+
+	if prio.pending--; prio.pending == 0 {
+		prio.done.Broadcast()
+	}
+}
+
+// waitGossip blocks until all pending propagated block imports are done.
+func (prio *importPriority) waitGossip() {
+	prio.lock.Lock()
+	defer prio.lock.Unlock()
+
+	if prio.pending > 0 {
+		importGossipYieldMeter.Mark(1)
+	}
+	for prio.pending > 0 {
+		prio.done.Wait()
+	}
+}
+
+// prioritisedChain is the blockchain as seen by the downloader, importing the
+// downloaded blocks in small batches yielding to propagated ones if preferred.
+type prioritisedChain struct {
+	*core.BlockChain
+	prio *importPriority
+}
+
+// InsertChain inserts a batch of downloaded blocks into the chain, letting any
+// pending propagated blocks go first in between smaller chunks if preferred.
+func (c *prioritisedChain) InsertChain(blocks types.Blocks) (int, error) {
+	if atomic.LoadUint32(&c.prio.preferred) == 0 {
+		return c.BlockChain.InsertChain(blocks)
+	}
+	for start := 0; start < len(blocks); start += gossipYieldBatch {
+		end := start + gossipYieldBatch
+		if end > len(blocks) {
+			end = len(blocks)
+		}
+		c.prio.waitGossip()
+		if index, err := c.BlockChain.InsertChain(blocks[start:end]); err != nil {
+			return start + index, err
+		}
+	}
+	return 0, nil
+}
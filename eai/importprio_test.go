@@ -0,0 +1,65 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereumai/go-ethereumai/consensus/eaiash"
+	"github.com/ethereumai/go-ethereumai/core"
+	"github.com/ethereumai/go-ethereumai/core/vm"
+	"github.com/ethereumai/go-ethereumai/eaidb"
+	"github.com/ethereumai/go-ethereumai/params"
+)
+
+// Tests that downloaded blocks wait for pending propagated imports if those are
+// preferred, and are still imported fully in smaller chunks.
+func TestPrioritisedChainInsert(t *testing.T) {
+	var (
+		db      = eaidb.NewMemDatabase()
+		gspec   = &core.Genesis{Config: params.TestChainConfig}
+		genesis = gspec.MustCommit(db)
+	)
+	blockchain, _ := core.NewBlockChain(db, nil, gspec.Config, eaiash.NewFaker(), vm.Config{})
+	defer blockchain.Stop()
+
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, eaiash.NewFaker(), db, 2*gossipYieldBatch+1, nil)
+
+	prio := newImportPriority()
+	prio.setPreferred(true)
+	prio.gossipStarted()
+
+	done := make(chan error)
+	go func() {
+		_, err := (&prioritisedChain{blockchain, prio}).InsertChain(blocks)
+		done <- err
+	}()
+	select {
+	case <-done:
+		t.Fatalf("downloaded blocks imported ahead of pending propagated ones")
+	case <-time.After(50 * time.Millisecond):
+	}
+	prio.gossipDone()
+
+	if err := <-done; err != nil {
+		t.Fatalf("failed to insert blocks: %v", err)
+	}
+	if head := blockchain.CurrentBlock().NumberU64(); head != uint64(len(blocks)) {
+		t.Errorf("head mismatch: have %d, want %d", head, len(blocks))
+	}
+}
@@ -21,10 +21,12 @@ import (
 	"fmt"
 	"math/big"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereumai/go-ethereumai/common"
 	"github.com/ethereumai/go-ethereumai/core/types"
+	"github.com/ethereumai/go-ethereumai/log"
 	"github.com/ethereumai/go-ethereumai/p2p"
 	"github.com/ethereumai/go-ethereumai/rlp"
 	"gopkg.in/fatih/set.v0"
@@ -39,6 +41,7 @@ var (
 const (
 	maxKnownTxs      = 32768 // Maximum transactions hashes to keep in the known list (prevent DOS)
 	maxKnownBlocks   = 1024  // Maximum block hashes to keep in the known list (prevent DOS)
+	maxBlockDelays   = 32    // Maximum block arrival delays to keep for clock skew estimates
 	handshakeTimeout = 5 * time.Second
 )
 
@@ -54,14 +57,16 @@ type peer struct {
 	id string
 
 	*p2p.Peer
-	rw p2p.MsgReadWriter
+	rw     p2p.MsgReadWriter
+	tracer *tracingMsgReadWriter // Message stream tracer, nil if not traceable
 
 	version  int         // Protocol version negotiated
 	forkDrop *time.Timer // Timed connection dropper if forks aren't validated in time
 
-	head common.Hash
-	td   *big.Int
-	lock sync.RWMutex
+	head   common.Hash
+	td     *big.Int
+	delays []time.Duration // Arrival delays of recently propagated blocks
+	lock   sync.RWMutex
 
 	knownTxs    *set.Set // Set of transaction hashes known to be known by this peer
 	knownBlocks *set.Set // Set of block hashes known to be known by this peer
@@ -80,6 +85,16 @@ func newPeer(version int, p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
 	}
 }
 
+// SetTracing enables or disables logging every protocol message exchanged with
+// the peer.
+func (p *peer) SetTracing(enable bool) error {
+	if p.tracer == nil {
+		return errors.New("peer does not support message tracing")
+	}
+	p.tracer.enable(enable)
+	return nil
+}
+
 // Info gathers and returns a collection of metadata known about a peer.
 func (p *peer) Info() *PeerInfo {
 	hash, td := p.Head()
@@ -110,6 +125,37 @@ func (p *peer) SetHead(hash common.Hash, td *big.Int) {
 	p.td.Set(td)
 }
 
+// MarkBlockArrival records the delay between the timestamp of a block propagated
+// by the peer and its local arrival time.
+func (p *peer) MarkBlockArrival(block *types.Block, arrival time.Time) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if len(p.delays) >= maxBlockDelays {
+		p.delays = p.delays[1:]
+	}
+	p.delays = append(p.delays, arrival.Sub(time.Unix(block.Time().Int64(), 0)))
+}
+
+// ClockSkew estimates how far the local clock is ahead of the clocks behind the
+// blocks propagated by the peer (negative if behind). Propagation latency only
+// ever adds to the arrival delays, so the smallest one is the best estimate.
+func (p *peer) ClockSkew() (time.Duration, bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if len(p.delays) == 0 {
+		return 0, false
+	}
+	skew := p.delays[0]
+	for _, delay := range p.delays[1:] {
+		if delay < skew {
+			skew = delay
+		}
+	}
+	return skew, true
+}
+
 // MarkBlock marks a block as known for the peer, ensuring that the block will
 // never be propagated to this particular peer.
 func (p *peer) MarkBlock(hash common.Hash) {
@@ -297,6 +343,38 @@ func (p *peer) String() string {
 	)
 }
 
+// tracingMsgReadWriter is a wrapper around a p2p.MsgReadWriter, logging the code
+// and size of every message passing through it while tracing is enabled.
+type tracingMsgReadWriter struct {
+	p2p.MsgReadWriter        // Wrapped message stream to trace
+	peer              string // Identifier of the remote peer
+	enabled           int32  // Flag whether tracing is enabled (atomic)
+}
+
+// enable switches message tracing on or off.
+func (rw *tracingMsgReadWriter) enable(on bool) {
+	if on {
+		atomic.StoreInt32(&rw.enabled, 1)
+	} else {
+		atomic.StoreInt32(&rw.enabled, 0)
+	}
+}
+
+func (rw *tracingMsgReadWriter) ReadMsg() (p2p.Msg, error) {
+	msg, err := rw.MsgReadWriter.ReadMsg()
+	if err == nil && atomic.LoadInt32(&rw.enabled) == 1 {
+		log.Debug("Received eai message", "peer", rw.peer, "code", msg.Code, "size", msg.Size)
+	}
+	return msg, err
+}
+
+func (rw *tracingMsgReadWriter) WriteMsg(msg p2p.Msg) error {
+	if atomic.LoadInt32(&rw.enabled) == 1 {
+		log.Debug("Sending eai message", "peer", rw.peer, "code", msg.Code, "size", msg.Size)
+	}
+	return rw.MsgReadWriter.WriteMsg(msg)
+}
+
 // peerSet represents the collection of active peers currently participating in
 // the EthereumAI sub-protocol.
 type peerSet struct {
@@ -349,6 +427,20 @@ func (ps *peerSet) Peer(id string) *peer {
 	return ps.peers[id]
 }
 
+// PeerByNodeID retrieves the registered peer with the given full node ID, or if
+// none matches, the one registered under the given short identifier.
+func (ps *peerSet) PeerByNodeID(id string) *peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	for _, p := range ps.peers {
+		if nodeID := p.ID(); fmt.Sprintf("%x", nodeID[:]) == id {
+			return p
+		}
+	}
+	return ps.peers[id]
+}
+
 // Len returns if the current number of peers in the set.
 func (ps *peerSet) Len() int {
 	ps.lock.RLock()
@@ -387,6 +479,21 @@ func (ps *peerSet) PeersWithoutTx(hash common.Hash) []*peer {
 	return list
 }
 
+// ClockSkews retrieves the clock skew estimates of all the peers that propagated
+// blocks to us, keyed by peer id.
+func (ps *peerSet) ClockSkews() map[string]time.Duration {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	skews := make(map[string]time.Duration)
+	for id, p := range ps.peers {
+		if skew, ok := p.ClockSkew(); ok {
+			skews[id] = skew
+		}
+	}
+	return skews
+}
+
 // BestPeer retrieves the known peer with the currently highest total difficulty.
 func (ps *peerSet) BestPeer() *peer {
 	ps.lock.RLock()
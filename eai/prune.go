@@ -0,0 +1,41 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"github.com/ethereumai/go-ethereumai/common"
+)
+
+// PruneAccountHistory removes the historical storage of an account that is only
+// reachable from states before beforeBlock, while keeping its storage at that
+// block and all later ones.
+//
+// A few constraints apply:
+//   - Only archive nodes (--gcmode=archive) are supported, pruning nodes never
+//     persist these historical states in the first place.
+//   - Only storage is pruned. The account's leaf in the account trie is part of
+//     the state root of every block, so it is retained and all state roots stay
+//     verifiable.
+//   - Storage lookups and proofs of the account at blocks before beforeBlock fail
+//     with missing trie node errors afterwards, proofs that are still needed must
+//     be recomputed against a retained state.
+//   - Every canonical state is walked to find the nodes shared with other accounts
+//     and blocks, and block imports are suspended meanwhile.
+func (s *EthereumAI) PruneAccountHistory(addr common.Address, beforeBlock uint64) error {
+	_, err := s.blockchain.PruneStorageHistory(addr, beforeBlock)
+	return err
+}
@@ -0,0 +1,169 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereumai/go-ethereumai/core"
+	"github.com/ethereumai/go-ethereumai/eaidb"
+	"github.com/ethereumai/go-ethereumai/log"
+)
+
+const (
+	// storageSampleInterval is the minimum number of blocks between two samples of
+	// the chain data size.
+	storageSampleInterval = 16
+
+	// defaultStorageGrowthWindow is the number of blocks the chain data growth is
+	// averaged over if not configured otherwise.
+	defaultStorageGrowthWindow = 1024
+)
+
+// errNoStorageSamples is returned if the chain data growth is queried before
+// enough blocks were imported to measure it.
+var errNoStorageSamples = errors.New("not enough chain data samples yet")
+
+// storageSample is the size of the chain data directory at a given block.
+type storageSample struct {
+	number uint64 // Number of the head block when sampled
+	time   uint64 // Timestamp of the head block when sampled
+	size   uint64 // Total size of the chain data in bytes
+}
+
+// StorageGrowth is a projection of the disk usage of the chain data.
+type StorageGrowth struct {
+	BytesPerBlock float64 `json:"bytesPerBlock"` // Average chain data growth per block
+	BlockTime     float64 `json:"blockTime"`     // Average seconds between blocks over the window
+	FreeBytes     uint64  `json:"freeBytes"`     // Free space on the disk holding the chain data
+	DaysUntilFull float64 `json:"daysUntilFull"` // Days until the disk fills up (-1 = not growing)
+}
+
+// sampleStorage records the size of the chain data directory as new blocks are
+// imported, until the blockchain is stopped.
+func (s *EthereumAI) sampleStorage(dir string) {
+	heads := make(chan core.ChainHeadEvent, 16)
+	sub := s.blockchain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-heads:
+			header := ev.Block.Header()
+
+			s.storageLock.Lock()
+			due := len(s.storageSamples) == 0 || header.Number.Uint64() >= s.storageSamples[len(s.storageSamples)-1].number+storageSampleInterval
+			s.storageLock.Unlock()
+			if !due {
+				continue
+			}
+			size, err := dirSize(dir)
+			if err != nil {
+				log.Debug("Failed to measure chain data size", "dir", dir, "err", err)
+				continue
+			}
+			s.addStorageSample(storageSample{number: header.Number.Uint64(), time: header.Time.Uint64(), size: size})
+
+		case <-sub.Err():
+			return
+		}
+	}
+}
+
+// addStorageSample appends a new chain data size sample, dropping the ones that
+// fell out of the configured window.
+func (s *EthereumAI) addStorageSample(sample storageSample) {
+	window := s.config.StorageGrowthWindow
+	if window == 0 {
+		window = defaultStorageGrowthWindow
+	}
+	s.storageLock.Lock()
+	defer s.storageLock.Unlock()
+
+	s.storageSamples = append(s.storageSamples, sample)
+	for len(s.storageSamples) > 2 && s.storageSamples[1].number+window <= sample.number {
+		s.storageSamples = s.storageSamples[1:]
+	}
+}
+
+// StorageGrowthRate returns the average growth of the chain data in bytes per
+// block, measured over the recent blocks of the configured window.
+func (s *EthereumAI) StorageGrowthRate() (bytesPerBlock float64, err error) {
+	rate, _, err := s.storageGrowth()
+	return rate, err
+}
+
+// storageGrowth returns the average chain data growth per block and the average
+// block time over the sampled window.
+func (s *EthereumAI) storageGrowth() (bytesPerBlock float64, blockTime float64, err error) {
+	s.storageLock.Lock()
+	defer s.storageLock.Unlock()
+
+	if len(s.storageSamples) < 2 {
+		return 0, 0, errNoStorageSamples
+	}
+	first, last := s.storageSamples[0], s.storageSamples[len(s.storageSamples)-1]
+	if last.number <= first.number {
+		return 0, 0, errNoStorageSamples
+	}
+	blocks := float64(last.number - first.number)
+	return float64(int64(last.size)-int64(first.size)) / blocks, float64(int64(last.time)-int64(first.time)) / blocks, nil
+}
+
+// StorageGrowth projects the disk usage of the chain data, estimating the time
+// left until the disk holding it runs full at the current growth rate.
+func (s *EthereumAI) StorageGrowth() (*StorageGrowth, error) {
+	db, ok := s.chainDb.(*eaidb.LDBDatabase)
+	if !ok {
+		return nil, errors.New("chain data not stored on disk")
+	}
+	rate, blockTime, err := s.storageGrowth()
+	if err != nil {
+		return nil, err
+	}
+	free, err := freeDiskSpace(db.Path())
+	if err != nil {
+		return nil, err
+	}
+	growth := &StorageGrowth{
+		BytesPerBlock: rate,
+		BlockTime:     blockTime,
+		FreeBytes:     free,
+		DaysUntilFull: -1,
+	}
+	if rate > 0 && blockTime > 0 {
+		growth.DaysUntilFull = float64(free) / rate * blockTime / (24 * 60 * 60)
+	}
+	return growth, nil
+}
+
+// dirSize returns the total size of all the files within a directory.
+func dirSize(dir string) (uint64, error) {
+	var size uint64
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,47 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import "testing"
+
+// Tests that the chain data growth is averaged over the configured window only.
+func TestStorageGrowthRate(t *testing.T) {
+	s := &EthereumAI{config: &Config{StorageGrowthWindow: 100}}
+
+	if _, err := s.StorageGrowthRate(); err != errNoStorageSamples {
+		t.Fatalf("growth without samples: have %v, want %v", err, errNoStorageSamples)
+	}
+	// Feed a slow start followed by a steady growth of 10 bytes per block
+	s.addStorageSample(storageSample{number: 0, time: 0, size: 0})
+	s.addStorageSample(storageSample{number: 50, time: 500, size: 5000})
+	for number := uint64(100); number <= 300; number += 50 {
+		s.addStorageSample(storageSample{number: number, time: number * 10, size: 5000 + (number-50)*10})
+	}
+	rate, blockTime, err := s.storageGrowth()
+	if err != nil {
+		t.Fatalf("failed to compute growth: %v", err)
+	}
+	if rate != 10 {
+		t.Errorf("growth rate mismatch: have %v, want 10", rate)
+	}
+	if blockTime != 10 {
+		t.Errorf("block time mismatch: have %v, want 10", blockTime)
+	}
+	if first := s.storageSamples[0].number; first != 200 {
+		t.Errorf("window start mismatch: have %d, want 200", first)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+
+	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/core/rawdb"
+	"github.com/ethereumai/go-ethereumai/core/state"
+	"github.com/ethereumai/go-ethereumai/core/types"
+	"github.com/ethereumai/go-ethereumai/crypto"
+	"github.com/ethereumai/go-ethereumai/eaidb"
+	"github.com/ethereumai/go-ethereumai/log"
+	"github.com/ethereumai/go-ethereumai/rlp"
+)
+
+const (
+	syncSnapshotVersion = 1   // Version of the fast sync snapshot file format
+	syncSnapshotBlocks  = 128 // Number of recent blocks included in a snapshot
+)
+
+// syncSnapshotMeta is the leading item of a fast sync snapshot, followed by the
+// recent blocks ending with the pivot, followed by the raw state trie nodes and
+// contract codes of the pivot state until the end of the file.
+type syncSnapshotMeta struct {
+	Version uint64
+	Genesis common.Hash
+	Blocks  uint64
+}
+
+// syncSnapshotBlock is a block of a fast sync snapshot along with the data a
+// fast sync would retrieve or compute for it.
+type syncSnapshotBlock struct {
+	Block    *types.Block
+	Receipts []*types.ReceiptForStorage
+	Td       *big.Int
+	TxCount  uint64
+}
+
+// ExportFastSyncSnapshot writes the state of the current head block, along with
+// the most recent blocks and their receipts, to a file from which another node
+// can be bootstrapped via ImportFastSyncSnapshot, skipping most of a fast sync.
+func (s *EthereumAI) ExportFastSyncSnapshot(path string) error {
+	pivot := s.blockchain.CurrentBlock()
+
+	release, err := s.blockchain.PinState(pivot.Root())
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	statedb, err := s.blockchain.StateAt(pivot.Root())
+	if err != nil {
+		return err
+	}
+	first := uint64(1)
+	if pivot.NumberU64() >= syncSnapshotBlocks {
+		first = pivot.NumberU64() - syncSnapshotBlocks + 1
+	}
+	if pivot.NumberU64() < first {
+		return errors.New("no blocks to snapshot beyond genesis")
+	}
+	temp := path + ".tmp"
+	out, err := os.OpenFile(temp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(out)
+	if err := s.writeFastSyncSnapshot(w, pivot, first, statedb); err != nil {
+		out.Close()
+		os.Remove(temp)
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		out.Close()
+		os.Remove(temp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	if err := os.Rename(temp, path); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	log.Info("Exported fast sync snapshot", "number", pivot.NumberU64(), "hash", pivot.Hash(), "blocks", pivot.NumberU64()-first+1, "file", path)
+	return nil
+}
+
+// writeFastSyncSnapshot streams the snapshot of the blocks [first, pivot] and the
+// pivot state into w.
+func (s *EthereumAI) writeFastSyncSnapshot(w io.Writer, pivot *types.Block, first uint64, statedb *state.StateDB) error {
+	meta := &syncSnapshotMeta{
+		Version: syncSnapshotVersion,
+		Genesis: s.blockchain.Genesis().Hash(),
+		Blocks:  pivot.NumberU64() - first + 1,
+	}
+	if err := rlp.Encode(w, meta); err != nil {
+		return err
+	}
+	// Walk the blocks upwards from the first one, ending with the pinned pivot
+	blocks := make([]*types.Block, meta.Blocks)
+	blocks[len(blocks)-1] = pivot
+	for i := len(blocks) - 2; i >= 0; i-- {
+		parent := s.blockchain.GetBlock(blocks[i+1].ParentHash(), blocks[i+1].NumberU64()-1)
+		if parent == nil {
+			return fmt.Errorf("block #%d not found", blocks[i+1].NumberU64()-1)
+		}
+		blocks[i] = parent
+	}
+	for _, block := range blocks {
+		td := s.blockchain.GetTd(block.Hash(), block.NumberU64())
+		if td == nil {
+			return fmt.Errorf("total difficulty of block #%d not found", block.NumberU64())
+		}
+		count, err := s.blockchain.GetTxCount(block.Hash(), block.NumberU64())
+		if err != nil {
+			return err
+		}
+		receipts := s.blockchain.GetReceiptsByHash(block.Hash())
+		if len(receipts) != len(block.Transactions()) {
+			return fmt.Errorf("receipts of block #%d not found", block.NumberU64())
+		}
+		storage := make([]*types.ReceiptForStorage, len(receipts))
+		for i, receipt := range receipts {
+			storage[i] = (*types.ReceiptForStorage)(receipt)
+		}
+		if err := rlp.Encode(w, &syncSnapshotBlock{Block: block, Receipts: storage, Td: td, TxCount: count}); err != nil {
+			return err
+		}
+	}
+	// Dump every standalone state trie node and contract code of the pivot
+	it := state.NewNodeIterator(statedb)
+	for it.Next() {
+		if it.Hash == (common.Hash{}) {
+			continue
+		}
+		blob, err := s.blockchain.TrieNode(it.Hash)
+		if err != nil {
+			return err
+		}
+		if err := rlp.Encode(w, blob); err != nil {
+			return err
+		}
+	}
+	return it.Error
+}
+
+// ImportFastSyncSnapshot bootstraps the local chain from a snapshot written by
+// ExportFastSyncSnapshot on a trusted node, setting its pivot block as the new
+// head. The snapshot is verified to be self-consistent: the blocks must link up
+// and match their headers, and the pivot state must be complete. Blocks before
+// the ones included in the snapshot are not available locally afterwards.
+func (s *EthereumAI) ImportFastSyncSnapshot(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	stream := rlp.NewStream(bufio.NewReader(in), 0)
+
+	var meta syncSnapshotMeta
+	if err := stream.Decode(&meta); err != nil {
+		return fmt.Errorf("invalid snapshot header: %v", err)
+	}
+	if meta.Version != syncSnapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", meta.Version)
+	}
+	if genesis := s.blockchain.Genesis().Hash(); meta.Genesis != genesis {
+		return fmt.Errorf("snapshot of a different chain: genesis %x, local %x", meta.Genesis, genesis)
+	}
+	if meta.Blocks == 0 || meta.Blocks > syncSnapshotBlocks {
+		return fmt.Errorf("invalid snapshot block count %d", meta.Blocks)
+	}
+	// Decode and verify the blocks before touching the database
+	blocks := make([]*syncSnapshotBlock, meta.Blocks)
+	for i := range blocks {
+		blocks[i] = new(syncSnapshotBlock)
+		if err := stream.Decode(blocks[i]); err != nil {
+			return fmt.Errorf("invalid snapshot block %d: %v", i, err)
+		}
+		if err := verifySnapshotBlock(blocks[i]); err != nil {
+			return err
+		}
+		if i > 0 && blocks[i].Block.ParentHash() != blocks[i-1].Block.Hash() {
+			return fmt.Errorf("snapshot block #%d not linked to its parent", blocks[i].Block.NumberU64())
+		}
+	}
+	pivot := blocks[len(blocks)-1].Block
+	if head := s.blockchain.CurrentBlock().NumberU64(); head >= pivot.NumberU64() {
+		return fmt.Errorf("local chain at #%d already past snapshot pivot #%d", head, pivot.NumberU64())
+	}
+	// Write the state nodes, keyed by their hashes, and ensure the pivot state is complete
+	batch := s.chainDb.NewBatch()
+	for {
+		var blob []byte
+		if err := stream.Decode(&blob); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("invalid snapshot state node: %v", err)
+		}
+		if err := batch.Put(crypto.Keccak256(blob), blob); err != nil {
+			return err
+		}
+		if batch.ValueSize() >= eaidb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	statedb, err := state.New(pivot.Root(), state.NewDatabase(s.chainDb))
+	if err != nil {
+		return fmt.Errorf("snapshot state incomplete: %v", err)
+	}
+	it := state.NewNodeIterator(statedb)
+	for it.Next() {
+	}
+	if it.Error != nil {
+		return fmt.Errorf("snapshot state incomplete: %v", it.Error)
+	}
+	// Write the blocks as the canonical chain and make the pivot the head
+	batch = s.chainDb.NewBatch()
+	for _, entry := range blocks {
+		block := entry.Block
+		receipts := make(types.Receipts, len(entry.Receipts))
+		for i, receipt := range entry.Receipts {
+			receipts[i] = (*types.Receipt)(receipt)
+		}
+		rawdb.WriteBlock(batch, block)
+		rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
+		rawdb.WriteTd(batch, block.Hash(), block.NumberU64(), entry.Td)
+		rawdb.WriteTxCount(batch, block.Hash(), block.NumberU64(), entry.TxCount)
+		rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
+		rawdb.WriteTxLookupEntries(batch, block)
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if err := s.blockchain.CommitSnapshotHead(pivot.Hash()); err != nil {
+		return err
+	}
+	log.Info("Imported fast sync snapshot", "number", pivot.NumberU64(), "hash", pivot.Hash(), "blocks", len(blocks), "file", path)
+	return nil
+}
+
+// verifySnapshotBlock checks that the body and receipts of a snapshot block match
+// the roots committed to in its header.
+func verifySnapshotBlock(entry *syncSnapshotBlock) error {
+	block := entry.Block
+	if entry.Td == nil || entry.Td.Cmp(block.Difficulty()) < 0 {
+		return fmt.Errorf("invalid total difficulty of snapshot block #%d", block.NumberU64())
+	}
+	if hash := types.DeriveSha(block.Transactions()); hash != block.TxHash() {
+		return fmt.Errorf("transaction root mismatch in snapshot block #%d", block.NumberU64())
+	}
+	if hash := types.CalcUncleHash(block.Uncles()); hash != block.UncleHash() {
+		return fmt.Errorf("uncle root mismatch in snapshot block #%d", block.NumberU64())
+	}
+	receipts := make(types.Receipts, len(entry.Receipts))
+	for i, receipt := range entry.Receipts {
+		receipts[i] = (*types.Receipt)(receipt)
+	}
+	if hash := types.DeriveSha(receipts); hash != block.ReceiptHash() {
+		return fmt.Errorf("receipt root mismatch in snapshot block #%d", block.NumberU64())
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/consensus/eaiash"
+	"github.com/ethereumai/go-ethereumai/core"
+	"github.com/ethereumai/go-ethereumai/core/types"
+	"github.com/ethereumai/go-ethereumai/core/vm"
+	"github.com/ethereumai/go-ethereumai/eaidb"
+	"github.com/ethereumai/go-ethereumai/params"
+)
+
+// Tests that a fast sync snapshot exported from one node bootstraps another one
+// to the same head, state and receipts, and that the chain can be extended from
+// there on.
+func TestFastSyncSnapshot(t *testing.T) {
+	var (
+		contract = common.Address{0x0a}
+		gspec    = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				testBank: {Balance: big.NewInt(1000000000)},
+				contract: {Balance: new(big.Int), Code: []byte{0x00}, Storage: map[common.Hash]common.Hash{{0x01}: {0x02}}},
+			},
+		}
+		srcDb   = eaidb.NewMemDatabase()
+		genesis = gspec.MustCommit(srcDb)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainId)
+	)
+	source, _ := core.NewBlockChain(srcDb, nil, gspec.Config, eaiash.NewFaker(), vm.Config{})
+	defer source.Stop()
+
+	chain, _ := core.GenerateChain(gspec.Config, genesis, eaiash.NewFaker(), srcDb, 6, func(i int, block *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testBank), common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, testBankKey)
+		block.AddTx(tx)
+	})
+	if _, err := source.InsertChain(chain[:5]); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	dir, err := ioutil.TempDir("", "fast-sync-snapshot-test")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "snapshot.rlp")
+	if err := (&EthereumAI{blockchain: source}).ExportFastSyncSnapshot(path); err != nil {
+		t.Fatalf("failed to export snapshot: %v", err)
+	}
+	// Import the snapshot into a fresh node and check its head and state
+	dstDb := eaidb.NewMemDatabase()
+	gspec.MustCommit(dstDb)
+
+	dest, _ := core.NewBlockChain(dstDb, nil, gspec.Config, eaiash.NewFaker(), vm.Config{})
+	defer dest.Stop()
+
+	s := &EthereumAI{blockchain: dest, chainDb: dstDb}
+
+	// A truncated snapshot must be rejected without touching the chain
+	blob, _ := ioutil.ReadFile(path)
+	truncated := filepath.Join(dir, "truncated.rlp")
+	ioutil.WriteFile(truncated, blob[:len(blob)-8], 0644)
+	if err := s.ImportFastSyncSnapshot(truncated); err == nil {
+		t.Fatalf("truncated snapshot imported")
+	}
+	if head := dest.CurrentBlock().NumberU64(); head != 0 {
+		t.Fatalf("head moved by failed import: have #%d", head)
+	}
+	if err := s.ImportFastSyncSnapshot(path); err != nil {
+		t.Fatalf("failed to import snapshot: %v", err)
+	}
+	if head := dest.CurrentBlock().Hash(); head != chain[4].Hash() {
+		t.Fatalf("head block mismatch: have %x, want %x", head, chain[4].Hash())
+	}
+	if head := dest.CurrentHeader().Hash(); head != chain[4].Hash() {
+		t.Fatalf("head header mismatch: have %x, want %x", head, chain[4].Hash())
+	}
+	statedb, err := dest.State()
+	if err != nil {
+		t.Fatalf("failed to open imported state: %v", err)
+	}
+	if nonce := statedb.GetNonce(testBank); nonce != 5 {
+		t.Errorf("nonce mismatch: have %d, want %d", nonce, 5)
+	}
+	if value := statedb.GetState(contract, common.Hash{0x01}); value != (common.Hash{0x02}) {
+		t.Errorf("storage mismatch: have %x, want %x", value, common.Hash{0x02})
+	}
+	if receipts := dest.GetReceiptsByHash(chain[4].Hash()); len(receipts) != 1 {
+		t.Errorf("receipt count mismatch: have %d, want %d", len(receipts), 1)
+	}
+	// The imported chain must be extendable and refuse a second import
+	if _, err := dest.InsertChain(chain[5:]); err != nil {
+		t.Fatalf("failed to extend imported chain: %v", err)
+	}
+	if err := s.ImportFastSyncSnapshot(path); err == nil {
+		t.Errorf("snapshot imported behind the local head")
+	}
+}
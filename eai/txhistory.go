@@ -0,0 +1,177 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"fmt"
+
+	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/common/hexutil"
+	"github.com/ethereumai/go-ethereumai/core"
+	"github.com/ethereumai/go-ethereumai/core/rawdb"
+	"github.com/ethereumai/go-ethereumai/core/types"
+)
+
+// txHistoryLimit is the number of transactions affected by reorgs whose block
+// inclusion history is retained.
+const txHistoryLimit = 4096
+
+// reorgedTxLimit is the number of transactions dropped from the canonical chain
+// by reorgs that are retained, most recent ones first.
+const reorgedTxLimit = 1024
+
+// TxLocation is a block that included a transaction.
+type TxLocation struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	Canonical   bool           `json:"canonical"` // Whether the block is currently canonical
+}
+
+// ReorgedTx is a transaction whose block was dropped from the canonical chain by
+// a reorg, flagged with its fate since.
+type ReorgedTx struct {
+	Hash    common.Hash `json:"hash"`
+	Pending bool        `json:"pending"` // Whether the transaction re-entered the pool
+	Remined bool        `json:"remined"` // Whether the transaction was included again
+}
+
+// txInclusion is a block that included a transaction, as retained in the history.
+type txInclusion struct {
+	number uint64
+	hash   common.Hash
+}
+
+// trackTxReorgs records the blocks that included the transactions affected by
+// chain reorgs, until the blockchain is stopped.
+func (s *EthereumAI) trackTxReorgs() {
+	reorgs := make(chan core.ReorgEvent, 16)
+	sub := s.blockchain.SubscribeReorgEvent(reorgs)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case ev := <-reorgs:
+			s.recordReorg(ev)
+		case <-sub.Err():
+			return
+		}
+	}
+}
+
+// recordReorg adds the blocks of a reorg to the inclusion history of all their
+// transactions, dropped blocks first.
+func (s *EthereumAI) recordReorg(ev core.ReorgEvent) {
+	for _, blocks := range [][]*types.Block{ev.Old, ev.New} {
+		for _, block := range blocks {
+			for _, tx := range block.Transactions() {
+				s.recordInclusion(tx.Hash(), txInclusion{number: block.NumberU64(), hash: block.Hash()})
+			}
+		}
+	}
+	s.recordReorgedOut(ev)
+}
+
+// recordReorgedOut retains all the transactions of the dropped blocks of a reorg,
+// whether or not the new blocks include them again, evicting the oldest ones
+// beyond the limit.
+func (s *EthereumAI) recordReorgedOut(ev core.ReorgEvent) {
+	s.reorgedLock.Lock()
+	defer s.reorgedLock.Unlock()
+
+	for _, block := range ev.Old {
+		for _, tx := range block.Transactions() {
+			s.reorgedTxs = append(s.reorgedTxs, tx.Hash())
+		}
+	}
+	if overflow := len(s.reorgedTxs) - reorgedTxLimit; overflow > 0 {
+		s.reorgedTxs = append([]common.Hash(nil), s.reorgedTxs[overflow:]...)
+	}
+}
+
+// recordInclusion appends a block to the inclusion history of a transaction. The
+// history is copied on write, so readers never see it modified.
+func (s *EthereumAI) recordInclusion(hash common.Hash, inclusion txInclusion) {
+	var history []txInclusion
+	if cached, ok := s.txHistory.Get(hash); ok {
+		history = cached.([]txInclusion)
+	}
+	for _, known := range history {
+		if known.hash == inclusion.hash {
+			return
+		}
+	}
+	s.txHistory.Add(hash, append(append([]txInclusion(nil), history...), inclusion))
+}
+
+// TxBlockHistory returns the blocks that included the given transaction, in the
+// order they were observed, each flagged whether it's currently canonical. Only
+// transactions affected by recent reorgs have more than one location, others
+// report their current block only, or none if not included.
+func (b *EaiAPIBackend) TxBlockHistory(hash common.Hash) ([]TxLocation, error) {
+	var history []txInclusion
+	if cached, ok := b.eai.txHistory.Get(hash); ok {
+		history = cached.([]txInclusion)
+	}
+	// The transaction may have been included again since, add its current block
+	if blockHash, number, _ := rawdb.ReadTxLookupEntry(b.eai.chainDb, hash); blockHash != (common.Hash{}) {
+		known := false
+		for _, inclusion := range history {
+			known = known || inclusion.hash == blockHash
+		}
+		if !known {
+			history = append(history, txInclusion{number: number, hash: blockHash})
+		}
+	}
+	locations := make([]TxLocation, len(history))
+	for i, inclusion := range history {
+		locations[i] = TxLocation{
+			BlockNumber: hexutil.Uint64(inclusion.number),
+			BlockHash:   inclusion.hash,
+			Canonical:   rawdb.ReadCanonicalHash(b.eai.chainDb, inclusion.number) == inclusion.hash,
+		}
+	}
+	return locations, nil
+}
+
+// ReorgedOutTransactions returns the n most recent transactions dropped from the
+// canonical chain by reorgs, newest first, each flagged whether it re-entered the
+// pool or was included in a block again.
+func (b *EaiAPIBackend) ReorgedOutTransactions(n int) ([]ReorgedTx, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("invalid transaction count %d", n)
+	}
+	b.eai.reorgedLock.Lock()
+	if n > len(b.eai.reorgedTxs) {
+		n = len(b.eai.reorgedTxs)
+	}
+	hashes := make([]common.Hash, n)
+	for i := range hashes {
+		hashes[i] = b.eai.reorgedTxs[len(b.eai.reorgedTxs)-1-i]
+	}
+	b.eai.reorgedLock.Unlock()
+
+	txs := make([]ReorgedTx, len(hashes))
+	for i, hash := range hashes {
+		blockHash, _, _ := rawdb.ReadTxLookupEntry(b.eai.chainDb, hash)
+		txs[i] = ReorgedTx{
+			Hash:    hash,
+			Pending: b.eai.txPool.Get(hash) != nil,
+			Remined: blockHash != (common.Hash{}),
+		}
+	}
+	return txs, nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eai
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/core"
+	"github.com/ethereumai/go-ethereumai/core/types"
+	"github.com/ethereumai/go-ethereumai/params"
+	"github.com/hashicorp/golang-lru"
+)
+
+// Tests that the blocks including a transaction are tracked across reorgs.
+func TestTxBlockHistory(t *testing.T) {
+	var (
+		backend = newTestBackend(t, core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}})
+		signer  = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+		tx, _   = types.SignTx(types.NewTransaction(0, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, testBankKey)
+		s       = backend.eai
+	)
+	defer s.blockchain.Stop()
+
+	s.txHistory, _ = lru.New(txHistoryLimit)
+	go s.trackTxReorgs()
+
+	// Include the transaction in the first block, then reorg it into the second
+	chain := insertTestChain(t, backend, 2, func(i int, block *core.BlockGen) {
+		if i == 0 {
+			block.AddTx(tx)
+		}
+	})
+	if history, _ := backend.TxBlockHistory(tx.Hash()); len(history) != 1 || history[0].BlockHash != chain[0].Hash() || !history[0].Canonical {
+		t.Fatalf("history before reorg mismatch: %v", history)
+	}
+	fork, _ := core.GenerateChain(s.chainConfig, s.blockchain.Genesis(), s.engine, s.chainDb, 3, func(i int, block *core.BlockGen) {
+		block.SetCoinbase(common.Address{0x02})
+		if i == 1 {
+			block.AddTx(tx)
+		}
+	})
+	if _, err := s.blockchain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	// Wait for the reorg to be recorded
+	for i := 0; i < 100 && s.txHistory.Len() == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	history, err := backend.TxBlockHistory(tx.Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve history: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("history length mismatch: have %d, want 2", len(history))
+	}
+	if history[0].BlockHash != chain[0].Hash() || history[0].Canonical {
+		t.Errorf("dropped inclusion mismatch: %+v", history[0])
+	}
+	if history[1].BlockHash != fork[1].Hash() || !history[1].Canonical {
+		t.Errorf("canonical inclusion mismatch: %+v", history[1])
+	}
+}
+
+// Tests that all the transactions of dropped blocks are retained, flagged with
+// whether they were included again or re-entered the pool.
+func TestReorgedOutTransactions(t *testing.T) {
+	var (
+		backend    = newTestBackend(t, core.GenesisAlloc{testBank: {Balance: big.NewInt(1000000000)}})
+		signer     = types.NewEIP155Signer(params.TestChainConfig.ChainId)
+		remined, _ = types.SignTx(types.NewTransaction(0, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, testBankKey)
+		dropped, _ = types.SignTx(types.NewTransaction(1, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, testBankKey)
+		s          = backend.eai
+	)
+	defer s.blockchain.Stop()
+
+	pool := newTestTxPool(backend)
+	defer pool.Stop()
+
+	s.txHistory, _ = lru.New(txHistoryLimit)
+
+	reorgs := make(chan core.ReorgEvent, 1)
+	sub := s.blockchain.SubscribeReorgEvent(reorgs)
+	defer sub.Unsubscribe()
+
+	reinjected := make(chan core.TxPreEvent, 1)
+	poolSub := pool.SubscribeTxPreEvent(reinjected)
+	defer poolSub.Unsubscribe()
+
+	// Include both transactions in the first block, then reorg only one back in
+	insertTestChain(t, backend, 2, func(i int, block *core.BlockGen) {
+		if i == 0 {
+			block.AddTx(remined)
+			block.AddTx(dropped)
+		}
+	})
+	fork, _ := core.GenerateChain(s.chainConfig, s.blockchain.Genesis(), s.engine, s.chainDb, 3, func(i int, block *core.BlockGen) {
+		block.SetCoinbase(common.Address{0x02})
+		if i == 1 {
+			block.AddTx(remined)
+		}
+	})
+	if _, err := s.blockchain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	select {
+	case ev := <-reorgs:
+		s.recordReorg(ev)
+	case <-time.After(time.Second):
+		t.Fatalf("reorg event not delivered")
+	}
+	// The dropped transaction is still valid, wait for the pool to reinject it
+	select {
+	case ev := <-reinjected:
+		if ev.Tx.Hash() != dropped.Hash() {
+			t.Fatalf("reinjected transaction mismatch: have %x, want %x", ev.Tx.Hash(), dropped.Hash())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("dropped transaction not reinjected")
+	}
+
+	txs, err := backend.ReorgedOutTransactions(10)
+	if err != nil {
+		t.Fatalf("failed to retrieve reorged transactions: %v", err)
+	}
+	want := []ReorgedTx{
+		{Hash: dropped.Hash(), Pending: true, Remined: false},
+		{Hash: remined.Hash(), Pending: false, Remined: true},
+	}
+	if !reflect.DeepEqual(txs, want) {
+		t.Errorf("reorged transactions mismatch: have %+v, want %+v", txs, want)
+	}
+	if txs, _ := backend.ReorgedOutTransactions(1); len(txs) != 1 || txs[0].Hash != dropped.Hash() {
+		t.Errorf("limited reorged transactions mismatch: have %+v, want %x only", txs, dropped.Hash())
+	}
+}
@@ -201,6 +201,44 @@ func (ec *Client) TransactionByHash(ctx context.Context, hash common.Hash) (tx *
 	return json.tx, json.BlockNumber == nil, nil
 }
 
+// TxStatus is the inclusion status of a transaction as seen by the remote node.
+type TxStatus int
+
+const (
+	TxStatusUnknown TxStatus = iota // Not known to the node, never seen or dropped
+	TxStatusPending                 // Waiting in the transaction pool of the node
+	TxStatusMined                   // Included in a block of the chain
+)
+
+// TransactionByHashStatus returns the transaction with the given hash along with
+// its inclusion status. A transaction reported without a containing block is
+// double checked for a receipt, as it may have been mined in the meantime. An
+// unknown transaction is reported with a nil transaction and no error.
+func (ec *Client) TransactionByHashStatus(ctx context.Context, hash common.Hash) (*types.Transaction, TxStatus, error) {
+	var json *rpcTransaction
+	if err := ec.c.CallContext(ctx, &json, "eai_getTransactionByHash", hash); err != nil {
+		return nil, TxStatusUnknown, err
+	}
+	if json == nil {
+		return nil, TxStatusUnknown, nil
+	}
+	if _, r, _ := json.tx.RawSignatureValues(); r == nil {
+		return nil, TxStatusUnknown, fmt.Errorf("server returned transaction without signature")
+	}
+	if json.BlockNumber != nil && json.BlockHash != (common.Hash{}) {
+		setSenderFromServer(json.tx, json.From, json.BlockHash)
+		return json.tx, TxStatusMined, nil
+	}
+	var receipt *types.Receipt
+	if err := ec.c.CallContext(ctx, &receipt, "eai_getTransactionReceipt", hash); err != nil {
+		return nil, TxStatusUnknown, err
+	}
+	if receipt != nil {
+		return json.tx, TxStatusMined, nil
+	}
+	return json.tx, TxStatusPending, nil
+}
+
 // TransactionSender returns the sender address of the given transaction. The transaction
 // must be known to the remote node and included in the blockchain at the given block and
 // index. The sender is the one derived by the protocol at the time of inclusion.
@@ -301,12 +339,62 @@ func (ec *Client) SyncProgress(ctx context.Context) (*ethereumai.SyncProgress, e
 	}, nil
 }
 
+// SyncStatus is the detailed progress of the sync algorithm, including the state
+// entries pulled during fast sync.
+type SyncStatus struct {
+	Syncing       bool    // Whether a sync is currently running
+	StartingBlock uint64  // Block number where sync began
+	CurrentBlock  uint64  // Current block number where sync is at
+	HighestBlock  uint64  // Highest alleged block number in the chain
+	PulledStates  uint64  // Number of state trie entries already downloaded
+	KnownStates   uint64  // Total number of state trie entries known about
+	Percentage    float64 // Share of the blocks between start and highest already synced
+}
+
+// SyncProgressDetailed retrieves the current progress of the sync algorithm. In
+// contrast to SyncProgress, a status is returned even if no sync is running, in
+// which case it is reported as complete.
+func (ec *Client) SyncProgressDetailed(ctx context.Context) (*SyncStatus, error) {
+	progress, err := ec.SyncProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if progress == nil {
+		return &SyncStatus{Percentage: 100}, nil
+	}
+	status := &SyncStatus{
+		Syncing:       true,
+		StartingBlock: progress.StartingBlock,
+		CurrentBlock:  progress.CurrentBlock,
+		HighestBlock:  progress.HighestBlock,
+		PulledStates:  progress.PulledStates,
+		KnownStates:   progress.KnownStates,
+		Percentage:    100,
+	}
+	if progress.HighestBlock > progress.StartingBlock && progress.CurrentBlock < progress.HighestBlock {
+		done := float64(progress.CurrentBlock) - float64(progress.StartingBlock)
+		if done < 0 {
+			done = 0
+		}
+		status.Percentage = 100 * done / float64(progress.HighestBlock-progress.StartingBlock)
+	}
+	return status, nil
+}
+
 // SubscribeNewHead subscribes to notifications about the current blockchain head
 // on the given channel.
 func (ec *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereumai.Subscription, error) {
 	return ec.c.EaiSubscribe(ctx, ch, "newHeads")
 }
 
+// SubscribePendingTransactions subscribes to notifications about the hashes of
+// transactions entering the pending pool of the node. Subscriptions require a
+// websocket or IPC connection, over HTTP rpc.ErrNotificationsUnsupported is
+// returned.
+func (ec *Client) SubscribePendingTransactions(ctx context.Context, ch chan<- common.Hash) (ethereumai.Subscription, error) {
+	return ec.c.EaiSubscribe(ctx, ch, "newPendingTransactions")
+}
+
 // State Access
 
 // NetworkID returns the network ID (also known as the chain ID) for this chain.
@@ -330,6 +418,35 @@ func (ec *Client) BalanceAt(ctx context.Context, account common.Address, blockNu
 	return (*big.Int)(&result), err
 }
 
+// BatchBalanceAt returns the wei balances of the given accounts, retrieving all of
+// them in a single round trip. The block number can be nil, in which case the
+// balances are taken from the latest known block. If the retrieval of any of the
+// balances fails, the first such failure is returned.
+func (ec *Client) BatchBalanceAt(ctx context.Context, accounts []common.Address, blockNumber *big.Int) ([]*big.Int, error) {
+	var (
+		results = make([]hexutil.Big, len(accounts))
+		reqs    = make([]rpc.BatchElem, len(accounts))
+	)
+	for i, account := range accounts {
+		reqs[i] = rpc.BatchElem{
+			Method: "eai_getBalance",
+			Args:   []interface{}{account, toBlockNumArg(blockNumber)},
+			Result: &results[i],
+		}
+	}
+	if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
+		return nil, err
+	}
+	balances := make([]*big.Int, len(accounts))
+	for i, req := range reqs {
+		if req.Error != nil {
+			return nil, fmt.Errorf("balance of %x: %v", accounts[i], req.Error)
+		}
+		balances[i] = (*big.Int)(&results[i])
+	}
+	return balances, nil
+}
+
 // StorageAt returns the value of key in the contract storage of the given account.
 // The block number can be nil, in which case the value is taken from the latest known block.
 func (ec *Client) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
@@ -438,6 +555,29 @@ func (ec *Client) CallContract(ctx context.Context, msg ethereumai.CallMsg, bloc
 	return hex, nil
 }
 
+// OverrideAccount specifies the fields of an account to be replaced for the
+// duration of a call. Unset fields are left as found in the state. State replaces
+// the account's storage in full, whereas StateDiff only modifies the given slots.
+type OverrideAccount struct {
+	Balance   *big.Int
+	Nonce     *uint64
+	Code      []byte
+	State     map[common.Hash]common.Hash
+	StateDiff map[common.Hash]common.Hash
+}
+
+// CallContractWithOverrides executes a message call transaction like CallContract,
+// but on top of a state modified by the given account overrides. The changes are
+// discarded after the call, the chain state is never affected.
+func (ec *Client) CallContractWithOverrides(ctx context.Context, msg ethereumai.CallMsg, blockNumber *big.Int, overrides map[common.Address]OverrideAccount) ([]byte, error) {
+	var hex hexutil.Bytes
+	err := ec.c.CallContext(ctx, &hex, "eai_call", toCallArg(msg), toBlockNumArg(blockNumber), toOverrideArg(overrides))
+	if err != nil {
+		return nil, err
+	}
+	return hex, nil
+}
+
 // PendingCallContract executes a message call transaction using the EVM.
 // The state seen by the contract call is the pending state.
 func (ec *Client) PendingCallContract(ctx context.Context, msg ethereumai.CallMsg) ([]byte, error) {
@@ -503,3 +643,27 @@ func toCallArg(msg ethereumai.CallMsg) interface{} {
 	}
 	return arg
 }
+
+func toOverrideArg(overrides map[common.Address]OverrideAccount) interface{} {
+	arg := make(map[common.Address]interface{}, len(overrides))
+	for addr, account := range overrides {
+		fields := make(map[string]interface{})
+		if account.Balance != nil {
+			fields["balance"] = (*hexutil.Big)(account.Balance)
+		}
+		if account.Nonce != nil {
+			fields["nonce"] = hexutil.Uint64(*account.Nonce)
+		}
+		if account.Code != nil {
+			fields["code"] = hexutil.Bytes(account.Code)
+		}
+		if account.State != nil {
+			fields["state"] = account.State
+		}
+		if account.StateDiff != nil {
+			fields["stateDiff"] = account.StateDiff
+		}
+		arg[addr] = fields
+	}
+	return arg
+}
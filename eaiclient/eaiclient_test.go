@@ -16,7 +16,21 @@
 
 package eaiclient
 
-import "github.com/ethereumai/go-ethereumai"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereumai/go-ethereumai"
+	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/common/hexutil"
+	"github.com/ethereumai/go-ethereumai/core/types"
+	"github.com/ethereumai/go-ethereumai/crypto"
+	"github.com/ethereumai/go-ethereumai/internal/eaiapi"
+	"github.com/ethereumai/go-ethereumai/rpc"
+)
 
 // Verify that Client implements the ethereumai interfaces.
 var (
@@ -32,3 +46,219 @@ var (
 	// _ = ethereumai.PendingStateEventer(&Client{})
 	_ = ethereumai.PendingContractCaller(&Client{})
 )
+
+// SyncingStub is a stub of the eai_syncing endpoint.
+type SyncingStub struct {
+	progress interface{}
+}
+
+func (s *SyncingStub) Syncing() interface{} { return s.progress }
+
+// Tests that the detailed sync progress retains the state entries and computes
+// the share of synced blocks.
+func TestSyncProgressDetailed(t *testing.T) {
+	service := new(SyncingStub)
+	server := rpc.NewServer()
+	if err := server.RegisterName("eai", service); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	client := NewClient(rpc.DialInProc(server))
+	defer client.Close()
+
+	service.progress = false
+	status, err := client.SyncProgressDetailed(context.Background())
+	if err != nil {
+		t.Fatalf("failed to retrieve idle progress: %v", err)
+	}
+	if want := (SyncStatus{Percentage: 100}); *status != want {
+		t.Errorf("idle status mismatch: have %+v, want %+v", *status, want)
+	}
+	service.progress = map[string]interface{}{
+		"startingBlock": hexutil.Uint64(100),
+		"currentBlock":  hexutil.Uint64(150),
+		"highestBlock":  hexutil.Uint64(300),
+		"pulledStates":  hexutil.Uint64(1000),
+		"knownStates":   hexutil.Uint64(4000),
+	}
+	if status, err = client.SyncProgressDetailed(context.Background()); err != nil {
+		t.Fatalf("failed to retrieve sync progress: %v", err)
+	}
+	want := SyncStatus{true, 100, 150, 300, 1000, 4000, 25}
+	if *status != want {
+		t.Errorf("sync status mismatch: have %+v, want %+v", *status, want)
+	}
+}
+
+// BalanceStub is a stub of the eai_getBalance endpoint.
+type BalanceStub struct {
+	balances map[common.Address]*big.Int
+}
+
+func (s *BalanceStub) GetBalance(account common.Address, number rpc.BlockNumber) (*hexutil.Big, error) {
+	balance, ok := s.balances[account]
+	if !ok {
+		return nil, errors.New("unknown account")
+	}
+	return (*hexutil.Big)(balance), nil
+}
+
+// Tests that balances are retrieved in a single batch and that the failure of
+// any of them is reported.
+func TestBatchBalanceAt(t *testing.T) {
+	service := &BalanceStub{balances: map[common.Address]*big.Int{
+		{0x01}: big.NewInt(1),
+		{0x02}: big.NewInt(2),
+	}}
+	server := rpc.NewServer()
+	if err := server.RegisterName("eai", service); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	client := NewClient(rpc.DialInProc(server))
+	defer client.Close()
+
+	balances, err := client.BatchBalanceAt(context.Background(), []common.Address{{0x02}, {0x01}}, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve balances: %v", err)
+	}
+	if len(balances) != 2 || balances[0].Int64() != 2 || balances[1].Int64() != 1 {
+		t.Errorf("balances mismatch: have %v, want [2 1]", balances)
+	}
+	if _, err := client.BatchBalanceAt(context.Background(), []common.Address{{0x01}, {0x03}}, nil); err == nil {
+		t.Error("batch with unknown account succeeded")
+	}
+}
+
+// Tests that subscribing to pending transactions over HTTP fails right away.
+func TestSubscribePendingTransactionsHTTP(t *testing.T) {
+	rpcClient, err := rpc.DialHTTP("http://127.0.0.1:1")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	client := NewClient(rpcClient)
+	defer client.Close()
+
+	if _, err := client.SubscribePendingTransactions(context.Background(), make(chan common.Hash)); err != rpc.ErrNotificationsUnsupported {
+		t.Errorf("error mismatch: have %v, want %v", err, rpc.ErrNotificationsUnsupported)
+	}
+}
+
+// TransactionStub is a stub of the transaction and receipt lookup endpoints.
+type TransactionStub struct {
+	txs      map[common.Hash]map[string]interface{}
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (s *TransactionStub) GetTransactionByHash(hash common.Hash) map[string]interface{} {
+	return s.txs[hash]
+}
+
+func (s *TransactionStub) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	return s.receipts[hash]
+}
+
+// Tests that the transaction status tells pending, mined and unknown transactions
+// apart, double checking transactions reported without a block for a receipt.
+func TestTransactionByHashStatus(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := types.HomesteadSigner{}
+
+	service := &TransactionStub{
+		txs:      make(map[common.Hash]map[string]interface{}),
+		receipts: make(map[common.Hash]*types.Receipt),
+	}
+	// Create a transaction response of the given shape, optionally with a receipt
+	add := func(nonce uint64, block common.Hash, number *hexutil.Big, receipt bool) common.Hash {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil), signer, key)
+		blob, _ := json.Marshal(tx)
+
+		fields := make(map[string]interface{})
+		json.Unmarshal(blob, &fields)
+		fields["blockHash"] = block
+		fields["blockNumber"] = number
+		fields["from"] = crypto.PubkeyToAddress(key.PublicKey)
+
+		service.txs[tx.Hash()] = fields
+		if receipt {
+			service.receipts[tx.Hash()] = &types.Receipt{TxHash: tx.Hash(), GasUsed: 21000, CumulativeGasUsed: 21000, Logs: []*types.Log{}}
+		}
+		return tx.Hash()
+	}
+	var (
+		pending = add(0, common.Hash{}, nil, false)
+		mined   = add(1, common.Hash{0x01}, (*hexutil.Big)(big.NewInt(1)), true)
+		racing  = add(2, common.Hash{}, nil, true)
+	)
+	server := rpc.NewServer()
+	if err := server.RegisterName("eai", service); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	client := NewClient(rpc.DialInProc(server))
+	defer client.Close()
+
+	tests := []struct {
+		hash   common.Hash
+		status TxStatus
+	}{
+		{pending, TxStatusPending},
+		{mined, TxStatusMined},
+		{racing, TxStatusMined},
+		{common.Hash{0xff}, TxStatusUnknown},
+	}
+	for i, tt := range tests {
+		tx, status, err := client.TransactionByHashStatus(context.Background(), tt.hash)
+		if err != nil {
+			t.Fatalf("test %d: failed to retrieve status: %v", i, err)
+		}
+		if status != tt.status {
+			t.Errorf("test %d: status mismatch: have %v, want %v", i, status, tt.status)
+		}
+		if (tx == nil) != (tt.status == TxStatusUnknown) || (tx != nil && tx.Hash() != tt.hash) {
+			t.Errorf("test %d: transaction mismatch: have %v", i, tx)
+		}
+	}
+}
+
+// CallStub is a stub of the call endpoint, recording the state overrides received.
+type CallStub struct {
+	overrides *eaiapi.StateOverride
+}
+
+func (s *CallStub) Call(args map[string]interface{}, block string, overrides *eaiapi.StateOverride) hexutil.Bytes {
+	s.overrides = overrides
+	return hexutil.Bytes{0x01}
+}
+
+// Tests that state overrides are passed to the call endpoint in the format the
+// server side expects.
+func TestCallContractWithOverrides(t *testing.T) {
+	service := new(CallStub)
+	server := rpc.NewServer()
+	if err := server.RegisterName("eai", service); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	client := NewClient(rpc.DialInProc(server))
+	defer client.Close()
+
+	nonce := uint64(7)
+	overrides := map[common.Address]OverrideAccount{
+		{0x01}: {Balance: big.NewInt(1000), Nonce: &nonce},
+		{0x02}: {Code: []byte{0x60, 0x00}, StateDiff: map[common.Hash]common.Hash{{0x01}: {0x02}}},
+	}
+	res, err := client.CallContractWithOverrides(context.Background(), ethereumai.CallMsg{To: &common.Address{0x02}}, nil, overrides)
+	if err != nil {
+		t.Fatalf("failed to execute call: %v", err)
+	}
+	if len(res) != 1 || res[0] != 0x01 {
+		t.Errorf("result mismatch: have %x, want 01", res)
+	}
+	if service.overrides == nil || len(*service.overrides) != 2 {
+		t.Fatalf("overrides mismatch: have %v", service.overrides)
+	}
+	first, second := (*service.overrides)[common.Address{0x01}], (*service.overrides)[common.Address{0x02}]
+	if first.Balance == nil || first.Balance.ToInt().Int64() != 1000 || first.Nonce == nil || *first.Nonce != 7 || first.Code != nil {
+		t.Errorf("first account mismatch: have %+v", first)
+	}
+	if second.Balance != nil || second.Code == nil || len(*second.Code) != 2 || second.StateDiff == nil || (*second.StateDiff)[common.Hash{0x01}] != (common.Hash{0x02}) {
+		t.Errorf("second account mismatch: have %+v", second)
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/ethereumai/go-ethereumai/consensus/eaiash"
 	"github.com/ethereumai/go-ethereumai/core"
 	"github.com/ethereumai/go-ethereumai/core/rawdb"
+	"github.com/ethereumai/go-ethereumai/core/state"
 	"github.com/ethereumai/go-ethereumai/core/types"
 	"github.com/ethereumai/go-ethereumai/core/vm"
 	"github.com/ethereumai/go-ethereumai/crypto"
@@ -50,6 +51,9 @@ const (
 	defaultGasPrice = 50 * params.Shannon
 )
 
+// emptyCodeHash is the known hash of the empty EVM bytecode.
+var emptyCodeHash = crypto.Keccak256Hash(nil)
+
 // PublicEthereumAIAPI provides an API to access EthereumAI related information.
 // It offers only methods that operate on public data that is freely available to anyone.
 type PublicEthereumAIAPI struct {
@@ -141,6 +145,19 @@ func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
 	}
 }
 
+// PendingAccounts returns the distinct senders of the pending transactions in
+// the pool, ordered by address, along with the total number of pending ones.
+func (s *PublicTxPoolAPI) PendingAccounts() (map[string]interface{}, error) {
+	accounts, count, err := s.b.PendingAccounts()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"accounts": accounts,
+		"count":    hexutil.Uint(count),
+	}, nil
+}
+
 // Inspect retrieves the content of the transaction pool and flattens it into an
 // easily inspectable list.
 func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
@@ -504,6 +521,12 @@ func (s *PublicBlockChainAPI) GetBalance(ctx context.Context, address common.Add
 	return b, state.Error()
 }
 
+// GetHeaderChain returns the canonical headers within the inclusive range
+// [from, to], at most MaxHeaderChainRange of them, checking that they link up.
+func (s *PublicBlockChainAPI) GetHeaderChain(ctx context.Context, from, to hexutil.Uint64) ([]*types.Header, error) {
+	return RetrieveHeaderChain(ctx, s.b, uint64(from), uint64(to))
+}
+
 // GetBlockByNumber returns the requested block. When blockNr is -1 the chain head is returned. When fullTx is true all
 // transactions in the block are returned in full detail, otherwise only the transaction hash is returned.
 func (s *PublicBlockChainAPI) GetBlockByNumber(ctx context.Context, blockNr rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
@@ -531,6 +554,12 @@ func (s *PublicBlockChainAPI) GetBlockByHash(ctx context.Context, blockHash comm
 	return nil, err
 }
 
+// GetBlockReceipts returns the receipts of all transactions in the canonical block
+// with the given number, saving chain scanning tools a request per transaction.
+func (s *PublicBlockChainAPI) GetBlockReceipts(ctx context.Context, blockNr rpc.BlockNumber) (types.Receipts, error) {
+	return s.b.GetReceiptsByNumber(ctx, blockNr)
+}
+
 // GetUncleByBlockNumberAndIndex returns the uncle block for the given block hash and index. When fullTx is true
 // all transactions in the block are returned in full detail, otherwise only the transaction hash is returned.
 func (s *PublicBlockChainAPI) GetUncleByBlockNumberAndIndex(ctx context.Context, blockNr rpc.BlockNumber, index hexutil.Uint) (map[string]interface{}, error) {
@@ -591,6 +620,20 @@ func (s *PublicBlockChainAPI) GetCode(ctx context.Context, address common.Addres
 	return code, state.Error()
 }
 
+// IsContract returns whether the given address holds contract code in the state
+// for the given block number, without transferring the code itself.
+func (s *PublicBlockChainAPI) IsContract(ctx context.Context, address common.Address, blockNr rpc.BlockNumber) (bool, error) {
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if err != nil {
+		return false, err
+	}
+	if state == nil || header == nil {
+		return false, fmt.Errorf("block #%d not found", blockNr)
+	}
+	codeHash := state.GetCodeHash(address)
+	return codeHash != (common.Hash{}) && codeHash != emptyCodeHash, state.Error()
+}
+
 // GetStorageAt returns the storage from the state at the given address, key and
 // block number. The rpc.LatestBlockNumber and rpc.PendingBlockNumber meta block
 // numbers are also allowed.
@@ -603,6 +646,86 @@ func (s *PublicBlockChainAPI) GetStorageAt(ctx context.Context, address common.A
 	return res[:], state.Error()
 }
 
+// AccountResult is the merkle proof of an account and some of its storage slots,
+// with each proof being the list of RLP encoded trie nodes on the path from the
+// respective root down to the proven value.
+type AccountResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []StorageResult `json:"storageProof"`
+}
+
+// StorageResult is the merkle proof of a single storage slot of an account.
+type StorageResult struct {
+	Key   common.Hash     `json:"key"`
+	Value *hexutil.Big    `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// ProveAccount collects the merkle proofs of the account at the given address and
+// of the given storage slots from the state.
+func ProveAccount(statedb *state.StateDB, address common.Address, storageKeys []common.Hash) (*AccountResult, error) {
+	accountProof, err := statedb.GetProof(address)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		storageHash  = types.EmptyRootHash
+		codeHash     = statedb.GetCodeHash(address)
+		storageTrie  = statedb.StorageTrie(address)
+		storageProof = make([]StorageResult, len(storageKeys))
+	)
+	if storageTrie != nil {
+		storageHash = storageTrie.Hash()
+	} else {
+		// Non-existent accounts have the code hash of empty code
+		codeHash = crypto.Keccak256Hash(nil)
+	}
+	for i, key := range storageKeys {
+		storageProof[i] = StorageResult{Key: key, Value: new(hexutil.Big), Proof: []hexutil.Bytes{}}
+		if storageTrie == nil {
+			continue
+		}
+		proof, err := statedb.GetStorageProof(address, key)
+		if err != nil {
+			return nil, err
+		}
+		storageProof[i].Value = (*hexutil.Big)(statedb.GetState(address, key).Big())
+		storageProof[i].Proof = toHexSlice(proof)
+	}
+	if err := statedb.Error(); err != nil {
+		return nil, err
+	}
+	return &AccountResult{
+		Address:      address,
+		AccountProof: toHexSlice(accountProof),
+		Balance:      (*hexutil.Big)(statedb.GetBalance(address)),
+		CodeHash:     codeHash,
+		Nonce:        hexutil.Uint64(statedb.GetNonce(address)),
+		StorageHash:  storageHash,
+		StorageProof: storageProof,
+	}, nil
+}
+
+// toHexSlice converts a list of byte slices into their hex encodable form.
+func toHexSlice(b [][]byte) []hexutil.Bytes {
+	r := make([]hexutil.Bytes, len(b))
+	for i := range b {
+		r[i] = b[i]
+	}
+	return r
+}
+
+// GetProof returns the merkle proofs of the account at the given address and of
+// the given storage slots in the state of the given block.
+func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Address, storageKeys []common.Hash, blockNr rpc.BlockNumber) (*AccountResult, error) {
+	return s.b.GetProof(ctx, address, storageKeys, blockNr)
+}
+
 // CallArgs represents the arguments for a call.
 type CallArgs struct {
 	From     common.Address  `json:"from"`
@@ -613,13 +736,64 @@ type CallArgs struct {
 	Data     hexutil.Bytes   `json:"data"`
 }
 
-func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
+// OverrideAccount indicates the overriding fields of account during the execution
+// of a message call. Fields left unset keep the value found in the state. State
+// replaces the whole storage of the account while StateDiff only patches the
+// given slots, so at most one of them may be set.
+type OverrideAccount struct {
+	Balance   *hexutil.Big                 `json:"balance"`
+	Nonce     *hexutil.Uint64              `json:"nonce"`
+	Code      *hexutil.Bytes               `json:"code"`
+	State     *map[common.Hash]common.Hash `json:"state"`
+	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
+}
+
+// StateOverride is the collection of overridden accounts.
+type StateOverride map[common.Address]OverrideAccount
+
+// Apply overrides the fields of specified accounts into the given state. The
+// overrides are validated first, the state is left untouched if any is invalid.
+func (diff *StateOverride) Apply(statedb *state.StateDB) error {
+	if diff == nil {
+		return nil
+	}
+	for addr, account := range *diff {
+		if account.State != nil && account.StateDiff != nil {
+			return fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
+		}
+	}
+	for addr, account := range *diff {
+		if account.Balance != nil {
+			statedb.SetBalance(addr, (*big.Int)(account.Balance))
+		}
+		if account.Nonce != nil {
+			statedb.SetNonce(addr, uint64(*account.Nonce))
+		}
+		if account.Code != nil {
+			statedb.SetCode(addr, *account.Code)
+		}
+		if account.State != nil {
+			statedb.SetStorage(addr, *account.State)
+		}
+		if account.StateDiff != nil {
+			for key, value := range *account.StateDiff {
+				statedb.SetState(addr, key, value)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
 	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
 	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
 	if state == nil || err != nil {
 		return nil, 0, false, err
 	}
+	if err := overrides.Apply(state); err != nil {
+		return nil, 0, false, err
+	}
 	// Set sender address or use a default if none specified
 	addr := args.From
 	if addr == (common.Address{}) {
@@ -634,6 +808,10 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	if gas == 0 {
 		gas = math.MaxUint64 / 2
 	}
+	if gasCap := s.b.RPCGasCap(); gasCap != nil && gasCap.Sign() > 0 && gasCap.IsUint64() && gas > gasCap.Uint64() {
+		log.Debug("Caller gas above allowance, capping", "requested", gas, "cap", gasCap)
+		gas = gasCap.Uint64()
+	}
 	if gasPrice.Sign() == 0 {
 		gasPrice = new(big.Int).SetUint64(defaultGasPrice)
 	}
@@ -658,13 +836,6 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	if err != nil {
 		return nil, 0, false, err
 	}
-	// Wait for the context to be done and cancel the evm. Even if the
-	// EVM has finished, cancelling may be done (repeatedly)
-	go func() {
-		<-ctx.Done()
-		evm.Cancel()
-	}()
-
 	// Setup the gas pool (also for unmetered requests)
 	// and apply the message.
 	gp := new(core.GasPool).AddGas(math.MaxUint64)
@@ -677,8 +848,11 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 
 // Call executes the given transaction on the state for the given block number.
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
-func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
-	result, _, _, err := s.doCall(ctx, args, blockNr, vm.Config{}, 5*time.Second)
+//
+// The optional overrides replace the balance, nonce, code or storage slots of
+// accounts for the duration of the call only.
+func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride) (hexutil.Bytes, error) {
+	result, _, _, err := s.doCall(ctx, args, blockNr, overrides, vm.Config{}, 5*time.Second)
 	return (hexutil.Bytes)(result), err
 }
 
@@ -707,7 +881,7 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	executable := func(gas uint64) bool {
 		args.Gas = hexutil.Uint64(gas)
 
-		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, vm.Config{}, 0)
+		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, nil, vm.Config{}, 0)
 		if err != nil || failed {
 			return false
 		}
@@ -1315,6 +1489,29 @@ func (s *PublicTransactionPoolAPI) PendingTransactions() ([]*RPCTransaction, err
 	return transactions, nil
 }
 
+// RevertReason replays the given mined transaction and returns the reason it was
+// reverted with. Reasons not in the standard Error(string) format are returned
+// as hex encoded raw data.
+func (s *PublicTransactionPoolAPI) RevertReason(ctx context.Context, hash common.Hash) (string, error) {
+	return replayRevertReason(ctx, s.b, hash)
+}
+
+// PendingContractCreations returns the pooled transactions that deploy a new
+// contract, regardless of the sending account.
+func (s *PublicTransactionPoolAPI) PendingContractCreations() ([]*RPCTransaction, error) {
+	pending, err := s.b.GetPoolTransactions()
+	if err != nil {
+		return nil, err
+	}
+	transactions := make([]*RPCTransaction, 0)
+	for _, tx := range pending {
+		if tx.To() == nil {
+			transactions = append(transactions, newRPCPendingTransaction(tx))
+		}
+	}
+	return transactions, nil
+}
+
 // Resend accepts an existing transaction and a new gas price and limit. It will remove
 // the given transaction from the pool and reinsert it with the new gas price and limit.
 func (s *PublicTransactionPoolAPI) Resend(ctx context.Context, sendArgs SendTxArgs, gasPrice *hexutil.Big, gasLimit *hexutil.Uint64) (common.Hash, error) {
@@ -1402,6 +1599,17 @@ func (api *PublicDebugAPI) SeedHash(ctx context.Context, number uint64) (string,
 	return fmt.Sprintf("0x%x", eaiash.SeedHash(number)), nil
 }
 
+// BloomProgress returns the number of blocks covered by the bloom bits index and
+// the number of the current head. Log queries beyond the indexed blocks fall back
+// to scanning the block headers one by one.
+func (api *PublicDebugAPI) BloomProgress() map[string]hexutil.Uint64 {
+	indexed, head := api.b.BloomProgress()
+	return map[string]hexutil.Uint64{
+		"indexed": hexutil.Uint64(indexed),
+		"head":    hexutil.Uint64(head),
+	}
+}
+
 // PrivateDebugAPI is the collection of EthereumAI APIs exposed over the private
 // debugging endpoint.
 type PrivateDebugAPI struct {
@@ -1453,6 +1661,12 @@ func (api *PrivateDebugAPI) SetHead(number hexutil.Uint64) {
 	api.b.SetHead(uint64(number))
 }
 
+// GetTrieNode returns the raw RLP encoding of the trie node with the given hash.
+// Light clients only know the nodes of earlier proofs and the head state root.
+func (api *PrivateDebugAPI) GetTrieNode(ctx context.Context, hash common.Hash) (hexutil.Bytes, error) {
+	return api.b.TrieNode(ctx, hash)
+}
+
 // PublicNetAPI offers network related RPC methods
 type PublicNetAPI struct {
 	net            *p2p.Server
@@ -0,0 +1,89 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eaiapi
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/core/state"
+	"github.com/ethereumai/go-ethereumai/core/types"
+	"github.com/ethereumai/go-ethereumai/eaidb"
+	"github.com/ethereumai/go-ethereumai/rpc"
+)
+
+// testBackend is a Backend serving only the data set up by a test, calling any
+// other method panics.
+type testBackend struct {
+	Backend
+	pool  types.Transactions
+	state *state.StateDB
+}
+
+func (b *testBackend) GetPoolTransactions() (types.Transactions, error) { return b.pool, nil }
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	if b.state == nil {
+		return nil, nil, nil
+	}
+	return b.state, &types.Header{Number: big.NewInt(int64(blockNr))}, nil
+}
+
+// Tests that only contract deployments are reported as pending contract creations.
+func TestPendingContractCreations(t *testing.T) {
+	var (
+		call   = types.NewTransaction(0, common.Address{0x01}, big.NewInt(0), 21000, big.NewInt(1), nil)
+		create = types.NewContractCreation(1, big.NewInt(0), 100000, big.NewInt(1), []byte{0x60, 0x00})
+	)
+	api := NewPublicTransactionPoolAPI(&testBackend{pool: types.Transactions{call, create}}, nil)
+
+	creations, err := api.PendingContractCreations()
+	if err != nil {
+		t.Fatalf("failed to retrieve creations: %v", err)
+	}
+	if len(creations) != 1 || creations[0].Hash != create.Hash() {
+		t.Fatalf("creations mismatch: have %v, want [%x]", creations, create.Hash())
+	}
+}
+
+// Tests that only accounts with code are reported as contracts.
+func TestIsContract(t *testing.T) {
+	var (
+		contract = common.Address{0x01}
+		account  = common.Address{0x02}
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(eaidb.NewMemDatabase()))
+	statedb.SetCode(contract, []byte{0x60, 0x00})
+	statedb.SetBalance(account, big.NewInt(1))
+
+	api := NewPublicBlockChainAPI(&testBackend{state: statedb})
+	for addr, want := range map[common.Address]bool{contract: true, account: false, {0x03}: false} {
+		have, err := api.IsContract(context.Background(), addr, 0)
+		if err != nil {
+			t.Fatalf("%x: failed to check code: %v", addr, err)
+		}
+		if have != want {
+			t.Errorf("%x: contract mismatch: have %v, want %v", addr, have, want)
+		}
+	}
+	api = NewPublicBlockChainAPI(new(testBackend))
+	if _, err := api.IsContract(context.Background(), contract, 0); err == nil {
+		t.Error("check on a missing block succeeded")
+	}
+}
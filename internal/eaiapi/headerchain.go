@@ -0,0 +1,60 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eaiapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereumai/go-ethereumai/core/types"
+	"github.com/ethereumai/go-ethereumai/rpc"
+)
+
+// MaxHeaderChainRange is the maximum number of headers a single header chain
+// request may span, bounding the work a remote caller can trigger.
+const MaxHeaderChainRange = 1024
+
+// RetrieveHeaderChain collects the canonical headers within the inclusive range
+// [from, to] through the backend, so that light clients transparently retrieve
+// them on demand from the network. The parent hash linkage of the returned
+// headers is checked, failing if the canonical chain changed mid-retrieval.
+func RetrieveHeaderChain(ctx context.Context, b Backend, from, to uint64) ([]*types.Header, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid range: start block %d after end block %d", from, to)
+	}
+	if to-from >= MaxHeaderChainRange {
+		return nil, fmt.Errorf("range %d-%d exceeds limit of %d headers", from, to, MaxHeaderChainRange)
+	}
+	if head := b.CurrentBlock().NumberU64(); to > head {
+		return nil, fmt.Errorf("end block %d beyond current head %d", to, head)
+	}
+	headers := make([]*types.Header, 0, to-from+1)
+	for number := from; number <= to; number++ {
+		header, err := b.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		if header == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		if len(headers) > 0 && header.ParentHash != headers[len(headers)-1].Hash() {
+			return nil, fmt.Errorf("block #%d not linked to its parent, chain reorganised", number)
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
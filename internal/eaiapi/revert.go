@@ -0,0 +1,113 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eaiapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/common/hexutil"
+	"github.com/ethereumai/go-ethereumai/core"
+	"github.com/ethereumai/go-ethereumai/core/types"
+	"github.com/ethereumai/go-ethereumai/core/vm"
+	"github.com/ethereumai/go-ethereumai/rpc"
+)
+
+// revertSelector is the method selector of the standard Error(string) revert.
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+// replayRevertReason re-executes a mined transaction on top of the state of its
+// parent block and the transactions preceding it in its own block, returning the
+// reason of its revert. An empty reason is returned if the execution failed
+// without any revert data.
+func replayRevertReason(ctx context.Context, b Backend, hash common.Hash) (string, error) {
+	tx, blockHash, blockNumber, index, err := b.GetTransaction(ctx, hash)
+	if err != nil {
+		return "", err
+	}
+	if tx == nil {
+		return "", fmt.Errorf("transaction %x not found", hash)
+	}
+	if blockNumber == 0 {
+		return "", fmt.Errorf("transaction %x in genesis block", hash)
+	}
+	block, err := b.GetBlock(ctx, blockHash)
+	if err != nil {
+		return "", err
+	}
+	statedb, _, err := b.StateAndHeaderByNumber(ctx, rpc.BlockNumber(blockNumber-1))
+	if err != nil {
+		return "", err
+	}
+	var (
+		chainConfig = b.ChainConfig()
+		signer      = types.MakeSigner(chainConfig, block.Number())
+	)
+	for i, tx := range block.Transactions() {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		msg, err := tx.AsMessage(signer)
+		if err != nil {
+			return "", err
+		}
+		context := core.NewEVMContext(msg, block.Header(), b.ChainContext(), nil)
+		vmenv := vm.NewEVM(context, statedb, chainConfig, vm.Config{})
+
+		ret, _, failed, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(tx.Gas()))
+		if err != nil {
+			return "", fmt.Errorf("tx %x failed: %v", tx.Hash(), err)
+		}
+		if uint64(i) == index {
+			if !failed {
+				return "", fmt.Errorf("transaction %x did not fail", hash)
+			}
+			return UnpackRevertReason(ret), nil
+		}
+		// Ensure any modifications are committed to the state
+		statedb.Finalise(chainConfig.IsEIP158(block.Number()))
+	}
+	return "", fmt.Errorf("tx index %d out of range for block %x", index, blockHash)
+}
+
+// UnpackRevertReason decodes the ABI encoded Error(string) reason of a revert.
+// If the data doesn't follow the standard format, it is returned hex encoded.
+func UnpackRevertReason(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	// Standard revert data is the selector, a string offset, length and content
+	if len(data) < 4+32+32 || !bytes.Equal(data[:4], revertSelector) {
+		return hexutil.Encode(data)
+	}
+	payload := data[4:]
+
+	offset := new(big.Int).SetBytes(payload[:32])
+	if !offset.IsUint64() || offset.Uint64()+32 > uint64(len(payload)) {
+		return hexutil.Encode(data)
+	}
+	start := offset.Uint64() + 32
+
+	length := new(big.Int).SetBytes(payload[offset.Uint64():start])
+	if !length.IsUint64() || length.Uint64() > uint64(len(payload))-start {
+		return hexutil.Encode(data)
+	}
+	return string(payload[start : start+length.Uint64()])
+}
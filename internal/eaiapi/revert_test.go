@@ -0,0 +1,54 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package eaiapi
+
+import (
+	"testing"
+
+	"github.com/ethereumai/go-ethereumai/common"
+)
+
+// Tests that revert reasons in the standard Error(string) format are decoded and
+// everything else is returned hex encoded.
+func TestUnpackRevertReason(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"", ""},
+		// Error("Not enough Ether provided.")
+		{"0x08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"000000000000000000000000000000000000000000000000000000000000001a" +
+			"4e6f7420656e6f7567682045746865722070726f76696465642e000000000000",
+			"Not enough Ether provided."},
+		// Custom error data
+		{"0xdeadbeef", "0xdeadbeef"},
+		// Truncated string content
+		{"0x08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"00000000000000000000000000000000000000000000000000000000000000ff",
+			"0x08c379a0" +
+				"0000000000000000000000000000000000000000000000000000000000000020" +
+				"00000000000000000000000000000000000000000000000000000000000000ff"},
+	}
+	for i, tt := range tests {
+		if have := UnpackRevertReason(common.FromHex(tt.data)); have != tt.want {
+			t.Errorf("test %d: reason mismatch: have %q, want %q", i, have, tt.want)
+		}
+	}
+}
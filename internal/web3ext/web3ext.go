@@ -134,6 +134,32 @@ web3._extend({
 			call: 'admin_importChain',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'cancelSync',
+			call: 'admin_cancelSync',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'setLocalAccounts',
+			call: 'admin_setLocalAccounts',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'setJournalEnabled',
+			call: 'admin_setJournalEnabled',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'scheduleStateExport',
+			call: 'admin_scheduleStateExport',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'pruneAccountHistory',
+			call: 'admin_pruneAccountHistory',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.utils.fromDecimal]
+		}),
 		new web3._extend.Method({
 			name: 'sleepBlocks',
 			call: 'admin_sleepBlocks',
@@ -169,6 +195,10 @@ web3._extend({
 			name: 'peers',
 			getter: 'admin_peers'
 		}),
+		new web3._extend.Property({
+			name: 'lightServerInfo',
+			getter: 'admin_lightServerInfo'
+		}),
 		new web3._extend.Property({
 			name: 'datadir',
 			getter: 'admin_datadir'
@@ -385,6 +415,49 @@ web3._extend({
 			params: 2,
 			inputFormatter:[null, null],
 		}),
+		new web3._extend.Method({
+			name: 'stateAvailability',
+			call: 'debug_stateAvailability',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'tracePeerMessages',
+			call: 'debug_tracePeerMessages',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'activeSubscriptions',
+			call: 'debug_activeSubscriptions'
+		}),
+		new web3._extend.Method({
+			name: 'bandwidthStats',
+			call: 'debug_bandwidthStats'
+		}),
+		new web3._extend.Method({
+			name: 'futureBlockCount',
+			call: 'debug_futureBlockCount'
+		}),
+		new web3._extend.Method({
+			name: 'bloomProgress',
+			call: 'debug_bloomProgress'
+		}),
+		new web3._extend.Method({
+			name: 'futureBlocks',
+			call: 'debug_futureBlocks'
+		}),
+		new web3._extend.Method({
+			name: 'clockSkew',
+			call: 'debug_clockSkew'
+		}),
+		new web3._extend.Method({
+			name: 'storageGrowth',
+			call: 'debug_storageGrowth'
+		}),
+		new web3._extend.Method({
+			name: 'getTrieNode',
+			call: 'debug_getTrieNode',
+			params: 1
+		}),
 	],
 	properties: []
 });
@@ -431,6 +504,86 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.utils.toHex]
 		}),
+		new web3._extend.Method({
+			name: 'cumulativeGasBefore',
+			call: 'eai_cumulativeGasBefore',
+			params: 1,
+			outputFormatter: web3._extend.utils.toDecimal
+		}),
+		new web3._extend.Method({
+			name: 'replacementChain',
+			call: 'eai_replacementChain',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getHeaderChain',
+			call: 'eai_getHeaderChain',
+			params: 2,
+			inputFormatter: [web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'calldataGasCost',
+			call: 'eai_calldataGasCost',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'isContract',
+			call: 'eai_isContract',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'revertReason',
+			call: 'eai_revertReason',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'blocksMinedBy',
+			call: 'eai_blocksMinedBy',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'balanceHistory',
+			call: 'eai_balanceHistory',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'totalTransactions',
+			call: 'eai_totalTransactions',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'difficultyBomb',
+			call: 'eai_difficultyBomb'
+		}),
+		new web3._extend.Method({
+			name: 'getBlockReceipts',
+			call: 'eai_getBlockReceipts',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getProof',
+			call: 'eai_getProof',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'networkHashrate',
+			call: 'eai_networkHashrate',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal],
+			outputFormatter: web3._extend.utils.toDecimal
+		}),
+		new web3._extend.Method({
+			name: 'activeEIPs',
+			call: 'eai_activeEIPs',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -445,6 +598,35 @@ web3._extend({
 				return formatted;
 			}
 		}),
+		new web3._extend.Property({
+			name: 'pendingContractCreations',
+			getter: 'eai_pendingContractCreations',
+			outputFormatter: function(txs) {
+				var formatted = [];
+				for (var i = 0; i < txs.length; i++) {
+					formatted.push(web3._extend.formatters.outputTransactionFormatter(txs[i]));
+					formatted[i].blockHash = null;
+				}
+				return formatted;
+			}
+		}),
+		new web3._extend.Property({
+			name: 'nextEpoch',
+			getter: 'eai_nextEpoch'
+		}),
+		new web3._extend.Property({
+			name: 'networkInfo',
+			getter: 'eai_networkInfo'
+		}),
+		new web3._extend.Property({
+			name: 'headAge',
+			getter: 'eai_headAge'
+		}),
+		new web3._extend.Property({
+			name: 'minGasPrice',
+			getter: 'eai_minGasPrice',
+			outputFormatter: web3._extend.formatters.outputBigNumberFormatter
+		}),
 	]
 });
 `
@@ -484,6 +666,19 @@ web3._extend({
 			name: 'getHashrate',
 			call: 'miner_getHashrate'
 		}),
+		new web3._extend.Method({
+			name: 'getHashratePerThread',
+			call: 'miner_getHashratePerThread'
+		}),
+		new web3._extend.Method({
+			name: 'expectedWork',
+			call: 'miner_expectedWork'
+		}),
+		new web3._extend.Method({
+			name: 'forceInclude',
+			call: 'miner_forceInclude',
+			params: 1
+		}),
 	],
 	properties: []
 });
@@ -628,6 +823,14 @@ web3._extend({
 				return status;
 			}
 		}),
+		new web3._extend.Property({
+			name: 'pendingAccounts',
+			getter: 'txpool_pendingAccounts',
+			outputFormatter: function(result) {
+				result.count = web3._extend.utils.toDecimal(result.count);
+				return result;
+			}
+		}),
 	]
 });
 `
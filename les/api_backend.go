@@ -17,8 +17,12 @@
 package les
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"math/big"
+	"sort"
+	"sync"
 
 	"github.com/ethereumai/go-ethereumai/accounts"
 	"github.com/ethereumai/go-ethereumai/common"
@@ -33,6 +37,7 @@ import (
 	"github.com/ethereumai/go-ethereumai/eai/gasprice"
 	"github.com/ethereumai/go-ethereumai/eaidb"
 	"github.com/ethereumai/go-ethereumai/event"
+	"github.com/ethereumai/go-ethereumai/internal/eaiapi"
 	"github.com/ethereumai/go-ethereumai/light"
 	"github.com/ethereumai/go-ethereumai/params"
 	"github.com/ethereumai/go-ethereumai/rpc"
@@ -47,6 +52,12 @@ func (b *LesApiBackend) ChainConfig() *params.ChainConfig {
 	return b.eai.chainConfig
 }
 
+// RPCGasCap returns the maximum gas a single call over RPC may use, or nil if
+// calls are not capped.
+func (b *LesApiBackend) RPCGasCap() *big.Int {
+	return b.eai.config.RPCGasCap
+}
+
 func (b *LesApiBackend) CurrentBlock() *types.Block {
 	return types.NewBlockWithHeader(b.eai.BlockChain().CurrentHeader())
 }
@@ -72,6 +83,12 @@ func (b *LesApiBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumb
 	return b.GetBlock(ctx, header.Hash())
 }
 
+// HeaderChain returns the canonical headers within the inclusive range [from, to],
+// retrieving any missing ones on demand from the network.
+func (b *LesApiBackend) HeaderChain(ctx context.Context, from, to uint64) ([]*types.Header, error) {
+	return eaiapi.RetrieveHeaderChain(ctx, b, from, to)
+}
+
 func (b *LesApiBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
 	header, err := b.HeaderByNumber(ctx, blockNr)
 	if header == nil || err != nil {
@@ -80,10 +97,37 @@ func (b *LesApiBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.
 	return light.NewState(ctx, header, b.eai.odr), header, nil
 }
 
+// StateAndHeaderByHash returns the on-demand state and header of the block with
+// the given hash, regardless of whether it is canonical.
+func (b *LesApiBackend) StateAndHeaderByHash(ctx context.Context, hash common.Hash) (*state.StateDB, *types.Header, error) {
+	header := b.eai.blockchain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, nil, fmt.Errorf("block %x not found", hash)
+	}
+	return light.NewState(ctx, header, b.eai.odr), header, nil
+}
+
+// GetProof returns the merkle proofs of the account at the given address and of
+// the given storage slots in the state of the given block. The trie nodes missing
+// locally are retrieved from the network and verified against the state root.
+func (b *LesApiBackend) GetProof(ctx context.Context, address common.Address, storageKeys []common.Hash, blockNr rpc.BlockNumber) (*eaiapi.AccountResult, error) {
+	state, _, err := b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	return eaiapi.ProveAccount(state, address, storageKeys)
+}
+
 func (b *LesApiBackend) GetBlock(ctx context.Context, blockHash common.Hash) (*types.Block, error) {
 	return b.eai.blockchain.GetBlockByHash(ctx, blockHash)
 }
 
+// GetTransaction retrieves a canonical transaction and its location, querying
+// the network for its position if it is not known locally.
+func (b *LesApiBackend) GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	return light.GetTransaction(ctx, b.eai.odr, txHash)
+}
+
 func (b *LesApiBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
 	if number := rawdb.ReadHeaderNumber(b.eai.chainDb, hash); number != nil {
 		return light.GetBlockReceipts(ctx, b.eai.odr, hash, *number)
@@ -91,6 +135,27 @@ func (b *LesApiBackend) GetReceipts(ctx context.Context, hash common.Hash) (type
 	return nil, nil
 }
 
+// GetReceiptsByNumber returns the receipts of the canonical block with the given
+// number, retrieving them from the network if not available locally.
+func (b *LesApiBackend) GetReceiptsByNumber(ctx context.Context, blockNr rpc.BlockNumber) (types.Receipts, error) {
+	header, err := b.HeaderByNumber(ctx, blockNr)
+	if header == nil || err != nil {
+		return nil, err
+	}
+	hash, number := header.Hash(), header.Number.Uint64()
+
+	receipts, err := light.GetBlockReceipts(ctx, b.eai.odr, hash, number)
+	if err != nil {
+		return nil, err
+	}
+	for i, receipt := range receipts {
+		receipt.BlockHash = hash
+		receipt.BlockNumber = new(big.Int).SetUint64(number)
+		receipt.TransactionIndex = uint(i)
+	}
+	return receipts, nil
+}
+
 func (b *LesApiBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
 	if number := rawdb.ReadHeaderNumber(b.eai.chainDb, hash); number != nil {
 		return light.GetBlockLogs(ctx, b.eai.odr, hash, *number)
@@ -102,24 +167,95 @@ func (b *LesApiBackend) GetTd(hash common.Hash) *big.Int {
 	return b.eai.blockchain.GetTdByHash(hash)
 }
 
+// TrieNode retrieves a trie node from the local database. On a miss only the
+// root of the current head state can be fetched via ODR, any other node fails
+// with light.ErrTrieNodeUnavailable.
+func (b *LesApiBackend) TrieNode(ctx context.Context, hash common.Hash) ([]byte, error) {
+	return light.GetTrieNode(ctx, b.eai.odr, light.StateTrieID(b.eai.blockchain.CurrentHeader()), hash)
+}
+
+// ChainContext returns the chain the backend executes transactions against, as
+// needed to assemble EVM contexts for historical blocks.
+func (b *LesApiBackend) ChainContext() core.ChainContext {
+	return b.eai.blockchain
+}
+
+// GetEVM creates an EVM for executing a call on the given on-demand state. The
+// execution is aborted once ctx is cancelled, in which case the returned error
+// function (to be invoked after the call) reports the context's error.
 func (b *LesApiBackend) GetEVM(ctx context.Context, msg core.Message, state *state.StateDB, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
 	state.SetBalance(msg.From(), math.MaxBig256)
+	if vmCfg.MaxCallDepth == 0 {
+		vmCfg.MaxCallDepth = b.eai.config.EVMCallMaxDepth
+	}
+	if vmCfg.MaxMemory == 0 {
+		vmCfg.MaxMemory = b.eai.config.EVMCallMaxMemory
+	}
 	context := core.NewEVMContext(msg, header, b.eai.blockchain, nil)
-	return vm.NewEVM(context, state, b.eai.chainConfig, vmCfg), state.Error, nil
+	evm := vm.NewEVM(context, state, b.eai.chainConfig, vmCfg)
+
+	// Abort the execution if the request is cancelled before the call completes,
+	// the watcher being released by the error retrieval after the call.
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			evm.Cancel()
+		case <-done:
+		}
+	}()
+	var once sync.Once
+	vmError := func() error {
+		once.Do(func() { close(done) })
+		if err := state.Error(); err != nil {
+			return err
+		}
+		if err := evm.LimitError(); err != nil {
+			return err
+		}
+		return ctx.Err()
+	}
+	return evm, vmError, nil
 }
 
 func (b *LesApiBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
 	return b.eai.txPool.Add(ctx, signedTx)
 }
 
+// WouldAccept checks whether the transaction pool would accept the transaction if
+// it were sent, returning the reason of the rejection otherwise.
+func (b *LesApiBackend) WouldAccept(ctx context.Context, signedTx *types.Transaction) error {
+	return b.eai.txPool.Validate(ctx, signedTx)
+}
+
 func (b *LesApiBackend) RemoveTx(txHash common.Hash) {
 	b.eai.txPool.RemoveTx(txHash)
 }
 
+// RemoveTxs removes a batch of transactions from the pool at once, returning the
+// number of transactions actually removed.
+func (b *LesApiBackend) RemoveTxs(hashes []common.Hash) int {
+	return b.eai.txPool.RemoveTxs(hashes)
+}
+
 func (b *LesApiBackend) GetPoolTransactions() (types.Transactions, error) {
 	return b.eai.txPool.GetTransactions()
 }
 
+// PendingAccounts returns the distinct senders of the locally pending transactions,
+// ordered by address, along with the total number of pending transactions.
+func (b *LesApiBackend) PendingAccounts() ([]common.Address, int, error) {
+	pending, _ := b.eai.txPool.Content()
+
+	accounts, count := make([]common.Address, 0, len(pending)), 0
+	for addr, txs := range pending {
+		accounts = append(accounts, addr)
+		count += len(txs)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return bytes.Compare(accounts[i][:], accounts[j][:]) < 0 })
+	return accounts, count, nil
+}
+
 func (b *LesApiBackend) GetPoolTransaction(txHash common.Hash) *types.Transaction {
 	return b.eai.txPool.GetTransaction(txHash)
 }
@@ -156,6 +292,15 @@ func (b *LesApiBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscri
 	return b.eai.blockchain.SubscribeLogsEvent(ch)
 }
 
+// SubscribePendingLogsEvent returns a subscription that never delivers anything,
+// as light clients have no pending block.
+func (b *LesApiBackend) SubscribePendingLogsEvent(ch chan<- []*types.Log) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
 func (b *LesApiBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return b.eai.blockchain.SubscribeRemovedLogsEvent(ch)
 }
@@ -192,6 +337,13 @@ func (b *LesApiBackend) BloomStatus() (uint64, uint64) {
 	return light.BloomTrieFrequency, sections
 }
 
+// BloomProgress returns the number of blocks covered by the bloom trie and the
+// number of the current head header.
+func (b *LesApiBackend) BloomProgress() (indexed uint64, head uint64) {
+	size, sections := b.BloomStatus()
+	return size * sections, b.eai.blockchain.CurrentHeader().Number.Uint64()
+}
+
 func (b *LesApiBackend) ServiceFilter(ctx context.Context, session *bloombits.MatcherSession) {
 	for i := 0; i < bloomFilterThreads; i++ {
 		go session.Multiplex(bloomRetrievalBatch, bloomRetrievalWait, b.eai.bloomRequests)
@@ -19,6 +19,7 @@ package les
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -114,6 +115,7 @@ func New(ctx *node.ServiceContext, config *eai.Config) (*LightEthereumAI, error)
 	leai.relay = NewLesTxRelay(peers, leai.reqDist)
 	leai.serverPool = newServerPool(chainDb, quitSync, &leai.wg)
 	leai.retriever = newRetrieveManager(peers, leai.reqDist, leai.serverPool)
+	leai.retriever.timeout = config.OdrTimeout
 	leai.odr = NewLesOdr(chainDb, leai.chtIndexer, leai.bloomTrieIndexer, leai.bloomIndexer, leai.retriever)
 	if leai.blockchain, err = light.NewLightChain(leai.odr, leai.chainConfig, leai.engine); err != nil {
 		return nil, err
@@ -139,17 +141,35 @@ func New(ctx *node.ServiceContext, config *eai.Config) (*LightEthereumAI, error)
 	return leai, nil
 }
 
-func lesTopic(genesisHash common.Hash, protocolVersion uint) discv5.Topic {
-	var name string
-	switch protocolVersion {
-	case lpv1:
-		name = "LES"
-	case lpv2:
-		name = "LES2"
-	default:
-		panic(nil)
+// lesTopics returns the discovery topics of the given protocol versions, ordered
+// from the highest version down with duplicate topics removed.
+func lesTopics(genesisHash common.Hash, protocolVersions []uint) []discv5.Topic {
+	versions := append([]uint(nil), protocolVersions...)
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+	var (
+		topics []discv5.Topic
+		seen   = make(map[discv5.Topic]bool)
+	)
+	for _, version := range versions {
+		var name string
+		switch version {
+		case lpv1:
+			name = "LES"
+		case lpv2:
+			name = "LES2"
+		case lpv3:
+			name = "LES3"
+		default:
+			panic(nil)
+		}
+		topic := discv5.Topic(name + "@" + common.Bytes2Hex(genesisHash.Bytes()[0:8]))
+		if !seen[topic] {
+			seen[topic] = true
+			topics = append(topics, topic)
+		}
 	}
-	return discv5.Topic(name + "@" + common.Bytes2Hex(genesisHash.Bytes()[0:8]))
+	return topics
 }
 
 type LightDummyAPI struct{}
@@ -206,6 +226,20 @@ func (s *LightEthereumAI) ResetWithGenesisBlock(gb *types.Block) {
 	s.blockchain.ResetWithGenesisBlock(gb)
 }
 
+// PeersWithCapability returns the IDs of the connected servers that announced
+// the given capability (e.g. "getProofs" or "getTxStatus") during the handshake,
+// allowing requests to be routed only to peers able to serve them.
+func (s *LightEthereumAI) PeersWithCapability(name string) []string {
+	var ids []string
+	for _, p := range s.peers.AllPeers() {
+		if p.hasCapability(name) {
+			ids = append(ids, p.id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 func (s *LightEthereumAI) BlockChain() *light.LightChain      { return s.blockchain }
 func (s *LightEthereumAI) TxPool() *light.TxPool              { return s.txPool }
 func (s *LightEthereumAI) Engine() consensus.Engine           { return s.engine }
@@ -225,9 +259,9 @@ func (s *LightEthereumAI) Start(srvr *p2p.Server) error {
 	s.startBloomHandlers()
 	log.Warn("Light client mode is an experimental feature")
 	s.netRPCService = eaiapi.NewPublicNetAPI(srvr, s.networkId)
-	// clients are searching for the first advertised protocol in the list
-	protocolVersion := AdvertiseProtocolVersions[0]
-	s.serverPool.start(srvr, lesTopic(s.blockchain.Genesis().Hash(), protocolVersion))
+	// clients are searching for all the protocols they speak, so servers not yet
+	// advertising the latest one are still found, but prefer dialing newer ones
+	s.serverPool.start(srvr, lesTopics(s.blockchain.Genesis().Hash(), ClientProtocolVersions))
 	s.protocolManager.Start(s.config.LightPeers)
 	return nil
 }
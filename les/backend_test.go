@@ -0,0 +1,47 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/p2p/discv5"
+)
+
+// Tests that discovery topics are ordered from the highest protocol version down
+// and that duplicates are removed.
+func TestLesTopics(t *testing.T) {
+	genesis := common.HexToHash("0x0102030405060708090a")
+	suffix := "@" + common.Bytes2Hex(genesis.Bytes()[0:8])
+
+	tests := []struct {
+		versions []uint
+		topics   []discv5.Topic
+	}{
+		{[]uint{lpv2}, []discv5.Topic{discv5.Topic("LES2" + suffix)}},
+		{[]uint{lpv1, lpv2}, []discv5.Topic{discv5.Topic("LES2" + suffix), discv5.Topic("LES" + suffix)}},
+		{[]uint{lpv2, lpv1, lpv2, lpv1}, []discv5.Topic{discv5.Topic("LES2" + suffix), discv5.Topic("LES" + suffix)}},
+		{[]uint{lpv3, lpv2}, []discv5.Topic{discv5.Topic("LES3" + suffix), discv5.Topic("LES2" + suffix)}},
+	}
+	for i, tt := range tests {
+		if topics := lesTopics(genesis, tt.versions); !reflect.DeepEqual(topics, tt.topics) {
+			t.Errorf("test %d: topics mismatch: have %v, want %v", i, topics, tt.topics)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereumai/go-ethereumai/common/mclock"
+)
+
+// costLimitWindow is the period over which the cost of served requests is
+// accumulated and checked against the cost limits of the server.
+const costLimitWindow = time.Second
+
+// CostLimitError is returned to light clients if a server refused to serve a
+// request because it would exceed the server's cost limits.
+type CostLimitError struct {
+	Wait time.Duration // Time until the server's cost budgets are replenished
+}
+
+func (e *CostLimitError) Error() string {
+	return fmt.Sprintf("request exceeds server cost limit, retry in %v", e.Wait)
+}
+
+// costLimiter tracks the cost of the requests served to each peer and to all
+// peers together within a cost window, checking them against the cost limits.
+type costLimiter struct {
+	now func() mclock.AbsTime // Time source, replaced in tests
+
+	lock                  sync.Mutex
+	peerLimit, totalLimit uint64 // Maximum cost served per peer and in total per window (0 = unlimited)
+	windowStart           mclock.AbsTime
+	peerCosts             map[string]uint64
+	totalCost             uint64
+}
+
+// newCostLimiter creates a cost limiter without any limits set.
+func newCostLimiter() *costLimiter {
+	return &costLimiter{
+		now:       mclock.Now,
+		peerCosts: make(map[string]uint64),
+	}
+}
+
+// setLimits sets the maximum cost served to a single peer and to all peers
+// together within a cost window. Zero limits disable the checks.
+func (l *costLimiter) setLimits(peerLimit, totalLimit uint64) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.peerLimit, l.totalLimit = peerLimit, totalLimit
+}
+
+// accept charges the cost of a request from the given peer against the budgets
+// of the current window. If the request doesn't fit the remaining budgets it is
+// not charged and the time until the next window is returned.
+func (l *costLimiter) accept(id string, cost uint64) (time.Duration, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.peerLimit == 0 && l.totalLimit == 0 {
+		return 0, true
+	}
+	now := l.now()
+	if time.Duration(now-l.windowStart) >= costLimitWindow {
+		l.windowStart = now
+		l.peerCosts = make(map[string]uint64)
+		l.totalCost = 0
+	}
+	if (l.peerLimit > 0 && l.peerCosts[id]+cost > l.peerLimit) || (l.totalLimit > 0 && l.totalCost+cost > l.totalLimit) {
+		return costLimitWindow - time.Duration(now-l.windowStart), false
+	}
+	l.peerCosts[id] += cost
+	l.totalCost += cost
+	return 0, true
+}
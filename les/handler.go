@@ -112,6 +112,8 @@ type ProtocolManager struct {
 	peers      *peerSet
 	maxPeers   int
 
+	maxResponseSize int // Maximum size of a single response, served data beyond it is dropped (LES/3 peers are told)
+
 	SubProtocols []p2p.Protocol
 
 	eventMux *event.TypeMux
@@ -145,6 +147,8 @@ func NewProtocolManager(chainConfig *params.ChainConfig, lightSync bool, protoco
 		quitSync:    quitSync,
 		wg:          wg,
 		noMorePeers: make(chan struct{}),
+
+		maxResponseSize: softResponseLimit,
 	}
 	if odr != nil {
 		manager.retriever = odr.retriever
@@ -360,6 +364,31 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		}
 		return false
 	}
+	// overBudget charges the cost of an accepted request against the cost limits
+	// of the server. Requests over the limits are not served: LES/3 clients get a
+	// cost limit reply telling when to retry, older ones can't be told so they are
+	// rejected like requests violating flow control.
+	overBudget := func(reqID, reqCnt uint64) (bool, error) {
+		wait, ok := pm.server.costLimiter.accept(p.id, costs.baseCost+reqCnt*costs.reqCost)
+		if ok {
+			return false, nil
+		}
+		bv, _ := p.fcClient.RequestProcessed(0)
+		if p.version < lpv3 {
+			return true, errResp(ErrRequestRejected, "cost limit exceeded")
+		}
+		p.Log().Debug("Request over cost limit", "wait", common.PrettyDuration(wait))
+		return true, p.SendCostLimit(reqID, bv, wait)
+	}
+	// sendTruncated tells LES/3 peers that the response to a request was cut short
+	// by the maximum response size. It is sent right before the response itself.
+	sendTruncated := func(reqID uint64) error {
+		limitedResponseMeter.Mark(1)
+		if p.version < lpv3 {
+			return nil
+		}
+		return p.SendTruncated(reqID)
+	}
 
 	if msg.Size > ProtocolMaxMsgSize {
 		return errResp(ErrMsgTooLarge, "%v > %v", msg.Size, ProtocolMaxMsgSize)
@@ -415,6 +444,9 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if reject(query.Amount, MaxHeaderFetch) {
 			return errResp(ErrRequestRejected, "")
 		}
+		if limited, err := overBudget(req.ReqID, query.Amount); limited {
+			return err
+		}
 
 		hashMode := query.Origin.Hash != (common.Hash{})
 
@@ -424,7 +456,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			headers []*types.Header
 			unknown bool
 		)
-		for !unknown && len(headers) < int(query.Amount) && bytes < softResponseLimit {
+		for !unknown && len(headers) < int(query.Amount) && bytes < common.StorageSize(pm.maxResponseSize) {
 			// Retrieve the next header satisfying the query
 			var origin *types.Header
 			if hashMode {
@@ -476,7 +508,12 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 				query.Origin.Number += query.Skip + 1
 			}
 		}
-
+		if len(headers) < int(query.Amount) && bytes >= common.StorageSize(pm.maxResponseSize) {
+			if err := sendTruncated(req.ReqID); err != nil {
+				return err
+			}
+		}
+		servedHeaderMeter.Mark(int64(len(headers)))
 		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + query.Amount*costs.reqCost)
 		pm.server.fcCostStats.update(msg.Code, query.Amount, rcost)
 		return p.SendBlockHeaders(req.ReqID, bv, headers)
@@ -524,8 +561,13 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if reject(uint64(reqCnt), MaxBodyFetch) {
 			return errResp(ErrRequestRejected, "")
 		}
+		if limited, err := overBudget(req.ReqID, uint64(reqCnt)); limited {
+			return err
+		}
+		truncated := false
 		for _, hash := range req.Hashes {
-			if bytes >= softResponseLimit {
+			if bytes >= pm.maxResponseSize {
+				truncated = true
 				break
 			}
 			// Retrieve the requested block body, stopping if enough was found
@@ -536,6 +578,12 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 				}
 			}
 		}
+		if truncated {
+			if err := sendTruncated(req.ReqID); err != nil {
+				return err
+			}
+		}
+		servedBodyMeter.Mark(int64(len(bodies)))
 		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
 		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
 		return p.SendBlockBodiesRLP(req.ReqID, bv, bodies)
@@ -580,7 +628,11 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if reject(uint64(reqCnt), MaxCodeFetch) {
 			return errResp(ErrRequestRejected, "")
 		}
-		for _, req := range req.Reqs {
+		if limited, err := overBudget(req.ReqID, uint64(reqCnt)); limited {
+			return err
+		}
+		truncated := false
+		for i, req := range req.Reqs {
 			// Retrieve the requested state entry, stopping if enough was found
 			if number := rawdb.ReadHeaderNumber(pm.chainDb, req.BHash); number != nil {
 				if header := rawdb.ReadHeader(pm.chainDb, req.BHash, *number); header != nil {
@@ -595,12 +647,19 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 					code, _ := statedb.Database().TrieDB().Node(common.BytesToHash(account.CodeHash))
 
 					data = append(data, code)
-					if bytes += len(code); bytes >= softResponseLimit {
+					if bytes += len(code); bytes >= pm.maxResponseSize {
+						truncated = i < reqCnt-1
 						break
 					}
 				}
 			}
 		}
+		if truncated {
+			if err := sendTruncated(req.ReqID); err != nil {
+				return err
+			}
+		}
+		servedCodeMeter.Mark(int64(len(data)))
 		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
 		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
 		return p.SendCode(req.ReqID, bv, data)
@@ -645,8 +704,13 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if reject(uint64(reqCnt), MaxReceiptFetch) {
 			return errResp(ErrRequestRejected, "")
 		}
+		if limited, err := overBudget(req.ReqID, uint64(reqCnt)); limited {
+			return err
+		}
+		truncated := false
 		for _, hash := range req.Hashes {
-			if bytes >= softResponseLimit {
+			if bytes >= pm.maxResponseSize {
+				truncated = true
 				break
 			}
 			// Retrieve the requested block's receipts, skipping if unknown to us
@@ -667,6 +731,12 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 				bytes += len(encoded)
 			}
 		}
+		if truncated {
+			if err := sendTruncated(req.ReqID); err != nil {
+				return err
+			}
+		}
+		servedReceiptMeter.Mark(int64(len(receipts)))
 		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
 		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
 		return p.SendReceiptsRLP(req.ReqID, bv, receipts)
@@ -711,7 +781,11 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if reject(uint64(reqCnt), MaxProofsFetch) {
 			return errResp(ErrRequestRejected, "")
 		}
-		for _, req := range req.Reqs {
+		if limited, err := overBudget(req.ReqID, uint64(reqCnt)); limited {
+			return err
+		}
+		truncated := false
+		for i, req := range req.Reqs {
 			// Retrieve the requested state entry, stopping if enough was found
 			if number := rawdb.ReadHeaderNumber(pm.chainDb, req.BHash); number != nil {
 				if header := rawdb.ReadHeader(pm.chainDb, req.BHash, *number); header != nil {
@@ -734,13 +808,20 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 						trie.Prove(req.Key, 0, &proof)
 
 						proofs = append(proofs, proof)
-						if bytes += proof.DataSize(); bytes >= softResponseLimit {
+						if bytes += proof.DataSize(); bytes >= pm.maxResponseSize {
+							truncated = i < reqCnt-1
 							break
 						}
 					}
 				}
 			}
 		}
+		if truncated {
+			if err := sendTruncated(req.ReqID); err != nil {
+				return err
+			}
+		}
+		servedProofMeter.Mark(int64(len(proofs)))
 		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
 		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
 		return p.SendProofs(req.ReqID, bv, proofs)
@@ -765,10 +846,14 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if reject(uint64(reqCnt), MaxProofsFetch) {
 			return errResp(ErrRequestRejected, "")
 		}
+		if limited, err := overBudget(req.ReqID, uint64(reqCnt)); limited {
+			return err
+		}
 
 		nodes := light.NewNodeSet()
 
-		for _, req := range req.Reqs {
+		served, truncated := 0, false
+		for i, req := range req.Reqs {
 			// Look up the state belonging to the request
 			if statedb == nil || req.BHash != lastBHash {
 				statedb, root, lastBHash = nil, common.Hash{}, req.BHash
@@ -799,10 +884,18 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			}
 			// Prove the user's request from the account or stroage trie
 			trie.Prove(req.Key, req.FromLevel, nodes)
-			if nodes.DataSize() >= softResponseLimit {
+			served++
+			if nodes.DataSize() >= pm.maxResponseSize {
+				truncated = i < reqCnt-1
 				break
 			}
 		}
+		if truncated {
+			if err := sendTruncated(req.ReqID); err != nil {
+				return err
+			}
+		}
+		servedProofMeter.Mark(int64(served))
 		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
 		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
 		return p.SendProofsV2(req.ReqID, bv, nodes.NodeList())
@@ -868,8 +961,12 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if reject(uint64(reqCnt), MaxHelperTrieProofsFetch) {
 			return errResp(ErrRequestRejected, "")
 		}
+		if limited, err := overBudget(req.ReqID, uint64(reqCnt)); limited {
+			return err
+		}
 		trieDb := trie.NewDatabase(eaidb.NewTable(pm.chainDb, light.ChtTablePrefix))
-		for _, req := range req.Reqs {
+		truncated := false
+		for i, req := range req.Reqs {
 			if header := pm.blockchain.GetHeaderByNumber(req.BlockNum); header != nil {
 				sectionHead := rawdb.ReadCanonicalHash(pm.chainDb, req.ChtNum*light.CHTFrequencyServer-1)
 				if root := light.GetChtRoot(pm.chainDb, req.ChtNum-1, sectionHead); root != (common.Hash{}) {
@@ -884,12 +981,19 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 					trie.Prove(encNumber[:], 0, &proof)
 
 					proofs = append(proofs, ChtResp{Header: header, Proof: proof})
-					if bytes += proof.DataSize() + estHeaderRlpSize; bytes >= softResponseLimit {
+					if bytes += proof.DataSize() + estHeaderRlpSize; bytes >= pm.maxResponseSize {
+						truncated = i < reqCnt-1
 						break
 					}
 				}
 			}
 		}
+		if truncated {
+			if err := sendTruncated(req.ReqID); err != nil {
+				return err
+			}
+		}
+		servedHelperTrieMeter.Mark(int64(len(proofs)))
 		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
 		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
 		return p.SendHeaderProofs(req.ReqID, bv, proofs)
@@ -913,6 +1017,9 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if reject(uint64(reqCnt), MaxHelperTrieProofsFetch) {
 			return errResp(ErrRequestRejected, "")
 		}
+		if limited, err := overBudget(req.ReqID, uint64(reqCnt)); limited {
+			return err
+		}
 
 		var (
 			lastIdx  uint64
@@ -921,7 +1028,8 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			auxTrie  *trie.Trie
 		)
 		nodes := light.NewNodeSet()
-		for _, req := range req.Reqs {
+		served, truncated := 0, false
+		for i, req := range req.Reqs {
 			if auxTrie == nil || req.Type != lastType || req.TrieIdx != lastIdx {
 				auxTrie, lastType, lastIdx = nil, req.Type, req.TrieIdx
 
@@ -937,6 +1045,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 				}
 				auxData = append(auxData, data)
 				auxBytes += len(data)
+				served++
 			} else {
 				if auxTrie != nil {
 					auxTrie.Prove(req.Key, req.FromLevel, nodes)
@@ -946,11 +1055,21 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 					auxData = append(auxData, data)
 					auxBytes += len(data)
 				}
+				if auxTrie != nil || req.AuxReq != 0 {
+					served++
+				}
 			}
-			if nodes.DataSize()+auxBytes >= softResponseLimit {
+			if nodes.DataSize()+auxBytes >= pm.maxResponseSize {
+				truncated = i < reqCnt-1
 				break
 			}
 		}
+		if truncated {
+			if err := sendTruncated(req.ReqID); err != nil {
+				return err
+			}
+		}
+		servedHelperTrieMeter.Mark(int64(served))
 		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
 		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
 		return p.SendHelperTrieProofs(req.ReqID, bv, HelperTrieResps{Proofs: nodes.NodeList(), AuxData: auxData})
@@ -1009,8 +1128,12 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if reject(uint64(reqCnt), MaxTxSend) {
 			return errResp(ErrRequestRejected, "")
 		}
+		if limited, err := overBudget(0, uint64(reqCnt)); limited {
+			return err // LES/1 message without request ID
+		}
 		pm.txpool.AddRemotes(txs)
 
+		servedTxMeter.Mark(int64(reqCnt))
 		_, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
 		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
 
@@ -1030,6 +1153,9 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if reject(uint64(reqCnt), MaxTxSend) {
 			return errResp(ErrRequestRejected, "")
 		}
+		if limited, err := overBudget(req.ReqID, uint64(reqCnt)); limited {
+			return err
+		}
 
 		hashes := make([]common.Hash, len(req.Txs))
 		for i, tx := range req.Txs {
@@ -1046,6 +1172,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			}
 		}
 
+		servedTxMeter.Mark(int64(reqCnt))
 		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
 		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
 
@@ -1067,6 +1194,10 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if reject(uint64(reqCnt), MaxTxStatus) {
 			return errResp(ErrRequestRejected, "")
 		}
+		if limited, err := overBudget(req.ReqID, uint64(reqCnt)); limited {
+			return err
+		}
+		servedTxStatusMeter.Mark(int64(reqCnt))
 		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
 		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
 
@@ -1080,19 +1211,66 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		p.Log().Trace("Received tx status response")
 		var resp struct {
 			ReqID, BV uint64
-			Status    []txStatus
+			Status    []light.TxStatus
+		}
+		if err := msg.Decode(&resp); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+
+		p.fcServer.GotReply(resp.ReqID, resp.BV)
+		deliverMsg = &Msg{
+			MsgType: MsgTxStatus,
+			ReqID:   resp.ReqID,
+			Obj:     resp.Status,
+		}
+
+	case TruncatedMsg:
+		if pm.odr == nil {
+			return errResp(ErrUnexpectedResponse, "")
+		}
+
+		p.Log().Trace("Received truncated response notice")
+		var reqID uint64
+		if err := msg.Decode(&reqID); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		p.truncatedReq, p.truncated = reqID, true
+		return nil
+
+	case CostLimitMsg:
+		if pm.odr == nil {
+			return errResp(ErrUnexpectedResponse, "")
+		}
+
+		p.Log().Trace("Received cost limit response")
+		var resp struct {
+			ReqID, BV uint64
+			Wait      uint64
 		}
 		if err := msg.Decode(&resp); err != nil {
 			return errResp(ErrDecode, "msg %v: %v", msg, err)
 		}
 
 		p.fcServer.GotReply(resp.ReqID, resp.BV)
+		if err := pm.retriever.reject(p, resp.ReqID, &CostLimitError{Wait: time.Duration(resp.Wait) * time.Millisecond}); err != nil {
+			p.responseErrors++
+			if p.responseErrors > maxResponseErrors {
+				return err
+			}
+		}
 
 	default:
 		p.Log().Trace("Received unknown message", "code", msg.Code)
 		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
 	}
 
+	// Truncation notices only ever refer to the response right after them
+	if p.truncated {
+		if deliverMsg != nil {
+			deliverMsg.Truncated = p.truncatedReq == deliverMsg.ReqID
+		}
+		p.truncated = false
+	}
 	if deliverMsg != nil {
 		err := pm.retriever.deliver(p, deliverMsg)
 		if err != nil {
@@ -1146,8 +1324,8 @@ func (pm *ProtocolManager) getHelperTrieAuxData(req HelperTrieReq) []byte {
 	return nil
 }
 
-func (pm *ProtocolManager) txStatus(hashes []common.Hash) []txStatus {
-	stats := make([]txStatus, len(hashes))
+func (pm *ProtocolManager) txStatus(hashes []common.Hash) []light.TxStatus {
+	stats := make([]light.TxStatus, len(hashes))
 	for i, stat := range pm.txpool.Status(hashes) {
 		// Save the status we've got from the transaction pool
 		stats[i].Status = stat
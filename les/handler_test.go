@@ -24,11 +24,13 @@ import (
 	"time"
 
 	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/common/mclock"
 	"github.com/ethereumai/go-ethereumai/consensus/eaiash"
 	"github.com/ethereumai/go-ethereumai/core"
 	"github.com/ethereumai/go-ethereumai/core/rawdb"
 	"github.com/ethereumai/go-ethereumai/core/types"
 	"github.com/ethereumai/go-ethereumai/crypto"
+	"github.com/ethereumai/go-ethereumai/eai"
 	"github.com/ethereumai/go-ethereumai/eai/downloader"
 	"github.com/ethereumai/go-ethereumai/eaidb"
 	"github.com/ethereumai/go-ethereumai/light"
@@ -243,12 +245,159 @@ func testGetBlockBodies(t *testing.T, protocol int) {
 		}
 		reqID++
 		// Send the hash request and verify the response
+		served := servedBodyMeter.Count()
 		cost := peer.GetRequestCost(GetBlockBodiesMsg, len(hashes))
 		sendRequest(peer.app, GetBlockBodiesMsg, reqID, cost, hashes)
 		if err := expectResponse(peer.app, BlockBodiesMsg, reqID, testBufLimit, bodies); err != nil {
 			t.Errorf("test %d: bodies mismatch: %v", i, err)
 		}
+		// Only the bodies actually returned count as served
+		if have := servedBodyMeter.Count() - served; have != int64(len(bodies)) {
+			t.Errorf("test %d: served bodies mismatch: have %d, want %d", i, have, len(bodies))
+		}
+	}
+}
+
+// Tests that responses are cut short once the configured maximum size is reached.
+func TestMaxResponseSizeLes1(t *testing.T) { testMaxResponseSize(t, 1) }
+func TestMaxResponseSizeLes2(t *testing.T) { testMaxResponseSize(t, 2) }
+func TestMaxResponseSizeLes3(t *testing.T) { testMaxResponseSize(t, 3) }
+
+func testMaxResponseSize(t *testing.T, protocol int) {
+	pm := newTestProtocolManagerMust(t, false, 10, nil, nil, nil, eaidb.NewMemDatabase())
+	pm.maxResponseSize = 1
+	bc := pm.blockchain.(*core.BlockChain)
+	peer, _ := newTestPeer(t, "peer", protocol, pm, true)
+	defer peer.close()
+
+	// expectTruncated checks that LES/3 peers are told about cut responses
+	expectTruncated := func(reqID uint64) {
+		if protocol < lpv3 {
+			return
+		}
+		if err := p2p.ExpectMsg(peer.app, TruncatedMsg, reqID); err != nil {
+			t.Errorf("request %d: truncation notice mismatch: %v", reqID, err)
+		}
+	}
+	// Only the first header should fit into the response
+	query := &getBlockHeadersData{Origin: hashOrNumber{Number: 1}, Amount: 5}
+	cost := peer.GetRequestCost(GetBlockHeadersMsg, int(query.Amount))
+	sendRequest(peer.app, GetBlockHeadersMsg, 1, cost, query)
+	expectTruncated(1)
+	if err := expectResponse(peer.app, BlockHeadersMsg, 1, testBufLimit, []*types.Header{bc.GetHeaderByNumber(1)}); err != nil {
+		t.Errorf("headers mismatch: %v", err)
+	}
+	// Only the first body should fit into the response
+	hashes := []common.Hash{bc.GetBlockByNumber(1).Hash(), bc.GetBlockByNumber(2).Hash()}
+	block := bc.GetBlockByNumber(1)
+	cost = peer.GetRequestCost(GetBlockBodiesMsg, len(hashes))
+	sendRequest(peer.app, GetBlockBodiesMsg, 2, cost, hashes)
+	expectTruncated(2)
+	if err := expectResponse(peer.app, BlockBodiesMsg, 2, testBufLimit, []*types.Body{{Transactions: block.Transactions(), Uncles: block.Uncles()}}); err != nil {
+		t.Errorf("bodies mismatch: %v", err)
+	}
+	// Complete responses must not be announced as truncated
+	cost = peer.GetRequestCost(GetBlockHeadersMsg, 1)
+	sendRequest(peer.app, GetBlockHeadersMsg, 3, cost, &getBlockHeadersData{Origin: hashOrNumber{Number: 1}, Amount: 1})
+	if err := expectResponse(peer.app, BlockHeadersMsg, 3, testBufLimit, []*types.Header{bc.GetHeaderByNumber(1)}); err != nil {
+		t.Errorf("single header mismatch: %v", err)
+	}
+}
+
+func TestCostLimitLes1(t *testing.T) { testCostLimit(t, 1) }
+func TestCostLimitLes2(t *testing.T) { testCostLimit(t, 2) }
+func TestCostLimitLes3(t *testing.T) { testCostLimit(t, 3) }
+
+// Tests that a flood of requests is refused once the per-peer or total cost
+// limits of the server are exceeded, until the next cost window starts.
+func testCostLimit(t *testing.T, protocol int) {
+	pm := newTestProtocolManagerMust(t, false, 4, nil, nil, nil, eaidb.NewMemDatabase())
+	pm.server.SetCostLimit(60, 80)
+
+	var now mclock.AbsTime
+	pm.server.costLimiter.now = func() mclock.AbsTime { return now }
+
+	peer1, errc1 := newTestPeer(t, "peer1", protocol, pm, true)
+	defer peer1.close()
+	peer2, _ := newTestPeer(t, "peer2", protocol, pm, true)
+	defer peer2.close()
+
+	// Price header requests, the test cost table has everything for free
+	for _, p := range []*testPeer{peer1, peer2} {
+		p.peer.fcCosts[GetBlockHeadersMsg] = &requestCosts{baseCost: 10, reqCost: 10}
+	}
+	reqID := uint64(0)
+	request := func(p *testPeer) uint64 {
+		reqID++
+		sendRequest(p.app, GetBlockHeadersMsg, reqID, 0, &getBlockHeadersData{Origin: hashOrNumber{Number: 1}, Amount: 1})
+		return reqID
+	}
+	// expect checks that the next reply of the peer is either the requested header
+	// or a cost limit reply with the given time to wait
+	expect := func(p *testPeer, id uint64, wait time.Duration) {
+		msg, err := p.app.ReadMsg()
+		if err != nil {
+			t.Fatalf("request %d: failed to read reply: %v", id, err)
+		}
+		defer msg.Discard()
+
+		var resp struct {
+			ReqID, BV uint64
+			Data      rlp.RawValue
+		}
+		if err := msg.Decode(&resp); err != nil {
+			t.Fatalf("request %d: failed to decode reply: %v", id, err)
+		}
+		if resp.ReqID != id {
+			t.Fatalf("request %d: reply ID mismatch: have %d", id, resp.ReqID)
+		}
+		switch {
+		case wait == 0 && msg.Code != BlockHeadersMsg:
+			t.Errorf("request %d: reply code mismatch: have %d, want %d", id, msg.Code, BlockHeadersMsg)
+		case wait > 0 && msg.Code != CostLimitMsg:
+			t.Errorf("request %d: reply code mismatch: have %d, want %d", id, msg.Code, CostLimitMsg)
+		case wait > 0:
+			var have uint64
+			if err := rlp.DecodeBytes(resp.Data, &have); err != nil {
+				t.Fatalf("request %d: failed to decode wait time: %v", id, err)
+			}
+			if time.Duration(have)*time.Millisecond != wait {
+				t.Errorf("request %d: wait time mismatch: have %dms, want %v", id, have, wait)
+			}
+		}
+	}
+	if protocol < lpv3 {
+		// LES/1 and LES/2 can't be told about the limit, the peer is dropped instead
+		for i := 0; i < 3; i++ {
+			expect(peer1, request(peer1), 0)
+		}
+		request(peer1)
+		select {
+		case err := <-errc1:
+			if want := errResp(ErrRequestRejected, "cost limit exceeded"); err == nil || err.Error() != want.Error() {
+				t.Errorf("disconnect error mismatch: have %v, want %v", err, want)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("peer over the cost limit not dropped")
+		}
+		return
+	}
+	// Exhaust the per-peer budget of the first peer, the total one with the second
+	for i := 0; i < 3; i++ {
+		expect(peer1, request(peer1), 0)
 	}
+	expect(peer1, request(peer1), costLimitWindow)
+	expect(peer2, request(peer2), 0)
+
+	now += mclock.AbsTime(costLimitWindow / 4)
+	expect(peer2, request(peer2), costLimitWindow*3/4)
+
+	// Budgets are only replenished once the window is over
+	now = mclock.AbsTime(costLimitWindow - time.Millisecond)
+	expect(peer1, request(peer1), time.Millisecond)
+	now = mclock.AbsTime(costLimitWindow)
+	expect(peer1, request(peer1), 0)
+	expect(peer2, request(peer2), 0)
 }
 
 // Tests that the contract codes can be retrieved based on account addresses.
@@ -500,7 +649,7 @@ func TestTransactionStatusLes2(t *testing.T) {
 
 	var reqID uint64
 
-	test := func(tx *types.Transaction, send bool, expStatus txStatus) {
+	test := func(tx *types.Transaction, send bool, expStatus light.TxStatus) {
 		reqID++
 		if send {
 			cost := peer.GetRequestCost(SendTxV2Msg, 1)
@@ -509,7 +658,7 @@ func TestTransactionStatusLes2(t *testing.T) {
 			cost := peer.GetRequestCost(GetTxStatusMsg, 1)
 			sendRequest(peer.app, GetTxStatusMsg, reqID, cost, []common.Hash{tx.Hash()})
 		}
-		if err := expectResponse(peer.app, TxStatusMsg, reqID, testBufLimit, []txStatus{expStatus}); err != nil {
+		if err := expectResponse(peer.app, TxStatusMsg, reqID, testBufLimit, []light.TxStatus{expStatus}); err != nil {
 			t.Errorf("transaction status mismatch")
 		}
 	}
@@ -518,20 +667,20 @@ func TestTransactionStatusLes2(t *testing.T) {
 
 	// test error status by sending an underpriced transaction
 	tx0, _ := types.SignTx(types.NewTransaction(0, acc1Addr, big.NewInt(10000), params.TxGas, nil, nil), signer, testBankKey)
-	test(tx0, true, txStatus{Status: core.TxStatusUnknown, Error: core.ErrUnderpriced.Error()})
+	test(tx0, true, light.TxStatus{Status: core.TxStatusUnknown, Error: core.ErrUnderpriced.Error()})
 
 	tx1, _ := types.SignTx(types.NewTransaction(0, acc1Addr, big.NewInt(10000), params.TxGas, big.NewInt(100000000000), nil), signer, testBankKey)
-	test(tx1, false, txStatus{Status: core.TxStatusUnknown}) // query before sending, should be unknown
-	test(tx1, true, txStatus{Status: core.TxStatusPending})  // send valid processable tx, should return pending
-	test(tx1, true, txStatus{Status: core.TxStatusPending})  // adding it again should not return an error
+	test(tx1, false, light.TxStatus{Status: core.TxStatusUnknown}) // query before sending, should be unknown
+	test(tx1, true, light.TxStatus{Status: core.TxStatusPending})  // send valid processable tx, should return pending
+	test(tx1, true, light.TxStatus{Status: core.TxStatusPending})  // adding it again should not return an error
 
 	tx2, _ := types.SignTx(types.NewTransaction(1, acc1Addr, big.NewInt(10000), params.TxGas, big.NewInt(100000000000), nil), signer, testBankKey)
 	tx3, _ := types.SignTx(types.NewTransaction(2, acc1Addr, big.NewInt(10000), params.TxGas, big.NewInt(100000000000), nil), signer, testBankKey)
 	// send transactions in the wrong order, tx3 should be queued
-	test(tx3, true, txStatus{Status: core.TxStatusQueued})
-	test(tx2, true, txStatus{Status: core.TxStatusPending})
+	test(tx3, true, light.TxStatus{Status: core.TxStatusQueued})
+	test(tx2, true, light.TxStatus{Status: core.TxStatusPending})
 	// query again, now tx3 should be pending too
-	test(tx3, false, txStatus{Status: core.TxStatusPending})
+	test(tx3, false, light.TxStatus{Status: core.TxStatusPending})
 
 	// generate and add a block with tx1 and tx2 included
 	gchain, _ := core.GenerateChain(params.TestChainConfig, chain.GetBlockByNumber(0), eaiash.NewFaker(), db, 1, func(i int, block *core.BlockGen) {
@@ -554,8 +703,8 @@ func TestTransactionStatusLes2(t *testing.T) {
 
 	// check if their status is included now
 	block1hash := rawdb.ReadCanonicalHash(db, 1)
-	test(tx1, false, txStatus{Status: core.TxStatusIncluded, Lookup: &rawdb.TxLookupEntry{BlockHash: block1hash, BlockIndex: 1, Index: 0}})
-	test(tx2, false, txStatus{Status: core.TxStatusIncluded, Lookup: &rawdb.TxLookupEntry{BlockHash: block1hash, BlockIndex: 1, Index: 1}})
+	test(tx1, false, light.TxStatus{Status: core.TxStatusIncluded, Lookup: &rawdb.TxLookupEntry{BlockHash: block1hash, BlockIndex: 1, Index: 0}})
+	test(tx2, false, light.TxStatus{Status: core.TxStatusIncluded, Lookup: &rawdb.TxLookupEntry{BlockHash: block1hash, BlockIndex: 1, Index: 1}})
 
 	// create a reorg that rolls them back
 	gchain, _ = core.GenerateChain(params.TestChainConfig, chain.GetBlockByNumber(0), eaiash.NewFaker(), db, 2, func(i int, block *core.BlockGen) {})
@@ -573,6 +722,72 @@ func TestTransactionStatusLes2(t *testing.T) {
 		t.Fatalf("pending count mismatch: have %d, want 3", pending)
 	}
 	// check if their status is pending again
-	test(tx1, false, txStatus{Status: core.TxStatusPending})
-	test(tx2, false, txStatus{Status: core.TxStatusPending})
+	test(tx1, false, light.TxStatus{Status: core.TxStatusPending})
+	test(tx2, false, light.TxStatus{Status: core.TxStatusPending})
+}
+
+// Tests that the capabilities a server announces during the handshake are
+// recorded on the client side according to the negotiated protocol version.
+func TestPeerCapabilitiesLes1(t *testing.T) { testPeerCapabilities(t, 1) }
+func TestPeerCapabilitiesLes2(t *testing.T) { testPeerCapabilities(t, 2) }
+
+func testPeerCapabilities(t *testing.T, protocol int) {
+	peers := newPeerSet()
+	dist := newRequestDistributor(peers, make(chan struct{}))
+	rm := newRetrieveManager(peers, dist, nil)
+	db := eaidb.NewMemDatabase()
+	ldb := eaidb.NewMemDatabase()
+	odr := NewLesOdr(ldb, light.NewChtIndexer(db, true), light.NewBloomTrieIndexer(db, true), eai.NewBloomIndexer(db, light.BloomTrieFrequency), rm)
+	pm := newTestProtocolManagerMust(t, false, 0, nil, nil, nil, db)
+	lpm := newTestProtocolManagerMust(t, true, 0, nil, peers, odr, ldb)
+	_, err1, lpeer, err2 := newTestPeerPair("peer", protocol, pm, lpm)
+	select {
+	case <-time.After(time.Millisecond * 100):
+	case err := <-err1:
+		t.Fatalf("server handshake error: %v", err)
+	case err := <-err2:
+		t.Fatalf("client handshake error: %v", err)
+	}
+	for _, name := range []string{"serveHeaders", "txRelay", "getBlockHeaders", "getProofs", "getHelperTrieProofs"} {
+		if !lpeer.hasCapability(name) {
+			t.Errorf("capability %q missing", name)
+		}
+	}
+	if have, want := lpeer.hasCapability("getTxStatus"), protocol >= lpv2; have != want {
+		t.Errorf("getTxStatus capability mismatch: have %v, want %v", have, want)
+	}
+	if lpeer.hasCapability("unknown") {
+		t.Errorf("unknown capability reported")
+	}
+}
+
+// Tests that the server counts the items actually served, even with metrics
+// disabled.
+func TestServedRequests(t *testing.T) {
+	pm := newTestProtocolManagerMust(t, false, 4, nil, nil, nil, eaidb.NewMemDatabase())
+	bc := pm.blockchain.(*core.BlockChain)
+	peer, _ := newTestPeer(t, "peer", 2, pm, true)
+	defer peer.close()
+
+	before := pm.server.ServedRequests()
+
+	// Request more headers than available, only the existing ones count
+	sendRequest(peer.app, GetBlockHeadersMsg, 1, 0, &getBlockHeadersData{Origin: hashOrNumber{Number: 2}, Amount: 10})
+	headers := []*types.Header{bc.GetHeaderByNumber(2), bc.GetHeaderByNumber(3), bc.GetHeaderByNumber(4)}
+	if err := expectResponse(peer.app, BlockHeadersMsg, 1, testBufLimit, headers); err != nil {
+		t.Fatalf("headers mismatch: %v", err)
+	}
+	hashes := []common.Hash{bc.GetBlockByNumber(1).Hash(), bc.GetBlockByNumber(2).Hash()}
+	sendRequest(peer.app, GetBlockBodiesMsg, 2, 0, hashes)
+	bodies := []*types.Body{bc.GetBody(hashes[0]), bc.GetBody(hashes[1])}
+	if err := expectResponse(peer.app, BlockBodiesMsg, 2, testBufLimit, bodies); err != nil {
+		t.Fatalf("bodies mismatch: %v", err)
+	}
+	after := pm.server.ServedRequests()
+	if served := after["headers"] - before["headers"]; served != 3 {
+		t.Errorf("served headers mismatch: have %d, want %d", served, 3)
+	}
+	if served := after["bodies"] - before["bodies"]; served != 2 {
+		t.Errorf("served bodies mismatch: have %d, want %d", served, 2)
+	}
 }
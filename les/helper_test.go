@@ -193,6 +193,7 @@ func newTestProtocolManager(lightSync bool, blocks int, generator func(int, *cor
 
 		srv.fcManager = flowcontrol.NewClientManager(50, 10, 1000000000)
 		srv.fcCostStats = newCostStats(nil)
+		srv.costLimiter = newCostLimiter()
 	}
 	pm.Start(1000)
 	return pm, nil
@@ -17,6 +17,8 @@
 package les
 
 import (
+	"sync/atomic"
+
 	"github.com/ethereumai/go-ethereumai/metrics"
 	"github.com/ethereumai/go-ethereumai/p2p"
 )
@@ -62,8 +64,44 @@ var (
 	miscInTrafficMeter  = metrics.NewRegisteredMeter("les/misc/in/traffic", nil)
 	miscOutPacketsMeter = metrics.NewRegisteredMeter("les/misc/out/packets", nil)
 	miscOutTrafficMeter = metrics.NewRegisteredMeter("les/misc/out/traffic", nil)
+
+	limitedResponseMeter = metrics.NewRegisteredMeter("les/server/responses/limited", nil) // Responses cut short by the maximum response size
+
+	// Number of items served by the server, by request type
+	servedHeaderMeter     = newServedMeter("les/server/req/headers")
+	servedBodyMeter       = newServedMeter("les/server/req/bodies")
+	servedCodeMeter       = newServedMeter("les/server/req/code")
+	servedReceiptMeter    = newServedMeter("les/server/req/receipts")
+	servedProofMeter      = newServedMeter("les/server/req/proofs")
+	servedHelperTrieMeter = newServedMeter("les/server/req/helpertrie")
+	servedTxMeter         = newServedMeter("les/server/req/txs")
+	servedTxStatusMeter   = newServedMeter("les/server/req/txstatus")
 )
 
+// servedMeter counts the items served by the server, feeding a registered meter.
+// The count is kept separately, as meters don't count if metrics are disabled.
+type servedMeter struct {
+	count int64 // Number of items served since startup (atomic access)
+	meter metrics.Meter
+}
+
+// newServedMeter creates a served item counter feeding the meter registered
+// under the given name.
+func newServedMeter(name string) *servedMeter {
+	return &servedMeter{meter: metrics.NewRegisteredMeter(name, nil)}
+}
+
+// Mark records the serving of n items.
+func (m *servedMeter) Mark(n int64) {
+	atomic.AddInt64(&m.count, n)
+	m.meter.Mark(n)
+}
+
+// Count returns the number of items served since startup.
+func (m *servedMeter) Count() int64 {
+	return atomic.LoadInt64(&m.count)
+}
+
 // meteredMsgReadWriter is a wrapper around a p2p.MsgReadWriter, capable of
 // accumulating the above defined metrics based on the data stream contents.
 type meteredMsgReadWriter struct {
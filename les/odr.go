@@ -77,13 +77,15 @@ const (
 	MsgProofsV2
 	MsgHeaderProofs
 	MsgHelperTrieProofs
+	MsgTxStatus
 )
 
 // Msg encodes a LES message that delivers reply data for a request
 type Msg struct {
-	MsgType int
-	ReqID   uint64
-	Obj     interface{}
+	MsgType   int
+	ReqID     uint64
+	Obj       interface{}
+	Truncated bool // Response cut short by the maximum response size of the server
 }
 
 // Retrieve tries to fetch an object from the LES network.
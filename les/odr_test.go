@@ -109,6 +109,71 @@ func odrAccounts(ctx context.Context, db eaidb.Database, config *params.ChainCon
 	return res
 }
 
+func TestOdrTrieNodeLes1(t *testing.T) { testOdr(t, 1, 1, odrTrieNode) }
+
+func TestOdrTrieNodeLes2(t *testing.T) { testOdr(t, 2, 1, odrTrieNode) }
+
+func odrTrieNode(ctx context.Context, db eaidb.Database, config *params.ChainConfig, bc *core.BlockChain, lc *light.LightChain, bhash common.Hash) []byte {
+	if bc != nil {
+		header := bc.GetHeaderByHash(bhash)
+		blob, _ := db.Get(header.Root[:])
+		return blob
+	}
+	header := lc.GetHeaderByHash(bhash)
+	blob, _ := light.GetTrieNode(ctx, lc.Odr(), light.StateTrieID(header), header.Root)
+	return blob
+}
+
+// Tests that transactions are located through the status reported by the server
+// and retrieved from their block bodies.
+func TestOdrTransactionLes2(t *testing.T) {
+	peers := newPeerSet()
+	dist := newRequestDistributor(peers, make(chan struct{}))
+	rm := newRetrieveManager(peers, dist, nil)
+	db := eaidb.NewMemDatabase()
+	ldb := eaidb.NewMemDatabase()
+	odr := NewLesOdr(ldb, light.NewChtIndexer(db, true), light.NewBloomTrieIndexer(db, true), eai.NewBloomIndexer(db, light.BloomTrieFrequency), rm)
+	pm := newTestProtocolManagerMust(t, false, 4, testChainGen, nil, nil, db)
+	lpm := newTestProtocolManagerMust(t, true, 0, nil, peers, odr, ldb)
+
+	// Transaction status is served from the pool, falling back to the chain
+	config := core.DefaultTxPoolConfig
+	config.Journal = ""
+	txpool := core.NewTxPool(config, params.TestChainConfig, pm.blockchain.(*core.BlockChain))
+	defer txpool.Stop()
+	pm.txpool = txpool
+
+	_, err1, lpeer, err2 := newTestPeerPair("peer", 2, pm, lpm)
+	select {
+	case <-time.After(time.Millisecond * 100):
+	case err := <-err1:
+		t.Fatalf("peer 1 handshake error: %v", err)
+	case err := <-err2:
+		t.Fatalf("peer 1 handshake error: %v", err)
+	}
+	lpm.synchronise(lpeer)
+
+	for i := uint64(1); i <= pm.blockchain.CurrentHeader().Number.Uint64(); i++ {
+		block := pm.blockchain.(*core.BlockChain).GetBlockByNumber(i)
+		for j, tx := range block.Transactions() {
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			ltx, blockHash, blockNumber, index, err := light.GetTransaction(ctx, odr, tx.Hash())
+			cancel()
+			if err != nil {
+				t.Fatalf("block %d, tx %d: failed to retrieve transaction: %v", i, j, err)
+			}
+			if ltx.Hash() != tx.Hash() || blockHash != block.Hash() || blockNumber != i || index != uint64(j) {
+				t.Errorf("block %d, tx %d: location mismatch: have %x/%x/%d/%d", i, j, ltx.Hash(), blockHash, blockNumber, index)
+			}
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, _, _, _, err := light.GetTransaction(ctx, odr, common.Hash{0x01}); err == nil {
+		t.Errorf("unknown transaction retrieved")
+	}
+}
+
 func TestOdrContractCallLes1(t *testing.T) { testOdr(t, 1, 2, odrContractCall) }
 
 func TestOdrContractCallLes2(t *testing.T) { testOdr(t, 2, 2, odrContractCall) }
@@ -27,8 +27,6 @@ import (
 	"math/big"
 
 	"github.com/ethereumai/go-ethereumai/common"
-	"github.com/ethereumai/go-ethereumai/core"
-	"github.com/ethereumai/go-ethereumai/core/rawdb"
 	"github.com/ethereumai/go-ethereumai/crypto"
 	"github.com/ethereumai/go-ethereumai/crypto/secp256k1"
 	"github.com/ethereumai/go-ethereumai/rlp"
@@ -38,17 +36,18 @@ import (
 const (
 	lpv1 = 1
 	lpv2 = 2
+	lpv3 = 3
 )
 
 // Supported versions of the les protocol (first is primary)
 var (
-	ClientProtocolVersions    = []uint{lpv2, lpv1}
-	ServerProtocolVersions    = []uint{lpv2, lpv1}
-	AdvertiseProtocolVersions = []uint{lpv2} // clients are searching for the first advertised protocol in the list
+	ClientProtocolVersions    = []uint{lpv3, lpv2, lpv1}
+	ServerProtocolVersions    = []uint{lpv3, lpv2, lpv1}
+	AdvertiseProtocolVersions = []uint{lpv3, lpv2} // clients are searching for the first advertised protocol in the list
 )
 
 // Number of implemented message corresponding to different protocol versions.
-var ProtocolLengths = map[uint]uint64{lpv1: 15, lpv2: 22}
+var ProtocolLengths = map[uint]uint64{lpv1: 15, lpv2: 22, lpv3: 24}
 
 const (
 	NetworkId          = 1
@@ -81,6 +80,47 @@ const (
 	SendTxV2Msg            = 0x13
 	GetTxStatusMsg         = 0x14
 	TxStatusMsg            = 0x15
+	// Protocol messages belonging to LPV3
+	CostLimitMsg = 0x16
+	TruncatedMsg = 0x17
+)
+
+// Capabilities a server may offer, as named by the handshake keys announcing
+// them and by the request messages priced in its flow control cost table.
+var (
+	serviceCapabilities = []string{"serveHeaders", "serveChainSince", "serveStateSince", "txRelay"}
+
+	requestCapabilities = map[int]map[uint64]string{
+		lpv1: {
+			GetBlockHeadersMsg: "getBlockHeaders",
+			GetBlockBodiesMsg:  "getBlockBodies",
+			GetReceiptsMsg:     "getReceipts",
+			GetProofsV1Msg:     "getProofs",
+			GetCodeMsg:         "getCode",
+			SendTxMsg:          "sendTx",
+			GetHeaderProofsMsg: "getHelperTrieProofs",
+		},
+		lpv2: {
+			GetBlockHeadersMsg:     "getBlockHeaders",
+			GetBlockBodiesMsg:      "getBlockBodies",
+			GetReceiptsMsg:         "getReceipts",
+			GetProofsV2Msg:         "getProofs",
+			GetCodeMsg:             "getCode",
+			SendTxV2Msg:            "sendTx",
+			GetHelperTrieProofsMsg: "getHelperTrieProofs",
+			GetTxStatusMsg:         "getTxStatus",
+		},
+		lpv3: {
+			GetBlockHeadersMsg:     "getBlockHeaders",
+			GetBlockBodiesMsg:      "getBlockBodies",
+			GetReceiptsMsg:         "getReceipts",
+			GetProofsV2Msg:         "getProofs",
+			GetCodeMsg:             "getCode",
+			SendTxV2Msg:            "sendTx",
+			GetHelperTrieProofsMsg: "getHelperTrieProofs",
+			GetTxStatusMsg:         "getTxStatus",
+		},
+	}
 )
 
 type errCode int
@@ -221,9 +261,3 @@ type CodeData []struct {
 }
 
 type proofsData [][]rlp.RawValue
-
-type txStatus struct {
-	Status core.TxStatus
-	Lookup *rawdb.TxLookupEntry `rlp:"nil"`
-	Error  string
-}
@@ -22,6 +22,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -29,6 +30,14 @@ import (
 	"github.com/ethereumai/go-ethereumai/common/mclock"
 )
 
+// ErrOdrTimeout is returned if an on-demand retrieval didn't succeed before the
+// deadline of its context expired.
+var ErrOdrTimeout = errors.New("odr request timed out")
+
+// ErrResponseTruncated is returned if the responses of all servers were cut short
+// by their maximum response size and were unusable without the missing part.
+var ErrResponseTruncated = errors.New("response truncated by server size limit")
+
 var (
 	retryQueue         = time.Millisecond * 100
 	softRequestTimeout = time.Millisecond * 500
@@ -42,6 +51,8 @@ type retrieveManager struct {
 	peers      *peerSet
 	serverPool peerSelector
 
+	timeout time.Duration // Retrieval timeout applied if the caller set no deadline (0 = none)
+
 	lock     sync.RWMutex
 	sentReqs map[uint64]*sentReq
 }
@@ -66,6 +77,8 @@ type sentReq struct {
 	stopped  bool
 	err      error
 
+	rejectErr error // last rejection by a server, returned if no other peers can serve the request
+
 	lock   sync.RWMutex // protect access to sentTo map
 	sentTo map[distPeer]sentReqToPeer
 
@@ -76,11 +89,11 @@ type sentReq struct {
 
 // sentReqToPeer notifies the request-from-peer goroutine (tryRequest) about a response
 // delivered by the given peer. Only one delivery is allowed per request per peer,
-// after which delivered is set to true, the validity of the response is sent on the
-// valid channel and no more responses are accepted.
+// after which delivered is set to true, the outcome (rpDeliveredValid, rpDeliveredInvalid
+// or rpRejected) is sent on the event channel and no more responses are accepted.
 type sentReqToPeer struct {
 	delivered bool
-	valid     chan bool
+	event     chan int
 }
 
 // reqPeerEvent is sent by the request-from-peer goroutine (tryRequest) to the
@@ -96,6 +109,7 @@ const (
 	rpHardTimeout
 	rpDeliveredValid
 	rpDeliveredInvalid
+	rpRejected
 )
 
 // newRetrieveManager creates the retrieve manager
@@ -111,13 +125,23 @@ func newRetrieveManager(peers *peerSet, dist *requestDistributor, serverPool pee
 // retrieve sends a request (to multiple peers if necessary) and waits for an answer
 // that is delivered through the deliver function and successfully validated by the
 // validator callback. It returns when a valid answer is delivered or the context is
-// cancelled.
+// cancelled, an expired deadline being reported as ErrOdrTimeout. Contexts without
+// a deadline are bounded by the retrieval timeout of the manager, if set.
 func (rm *retrieveManager) retrieve(ctx context.Context, reqID uint64, req *distReq, val validatorFunc, shutdown chan struct{}) error {
+	if _, ok := ctx.Deadline(); !ok && rm.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, rm.timeout)
+		defer cancel()
+	}
 	sentReq := rm.sendReq(reqID, req, val)
 	select {
 	case <-sentReq.stopCh:
 	case <-ctx.Done():
-		sentReq.stop(ctx.Err())
+		err := ctx.Err()
+		if err == context.DeadlineExceeded {
+			err = ErrOdrTimeout
+		}
+		sentReq.stop(err)
 	case <-shutdown:
 		sentReq.stop(fmt.Errorf("Client is shutting down"))
 	}
@@ -150,7 +174,7 @@ func (rm *retrieveManager) sendReq(reqID uint64, req *distReq, val validatorFunc
 	req.request = func(p distPeer) func() {
 		// before actually sending the request, put an entry into the sentTo map
 		r.lock.Lock()
-		r.sentTo[p] = sentReqToPeer{false, make(chan bool, 1)}
+		r.sentTo[p] = sentReqToPeer{false, make(chan int, 1)}
 		r.lock.Unlock()
 		return request(p)
 	}
@@ -174,6 +198,19 @@ func (rm *retrieveManager) deliver(peer distPeer, msg *Msg) error {
 	return errResp(ErrUnexpectedResponse, "reqID = %v", msg.ReqID)
 }
 
+// reject is called by the LES protocol manager if a server refused to serve a
+// request, passing the reason reported by the server.
+func (rm *retrieveManager) reject(peer distPeer, reqID uint64, err error) error {
+	rm.lock.RLock()
+	req, ok := rm.sentReqs[reqID]
+	rm.lock.RUnlock()
+
+	if ok {
+		return req.reject(peer, reqID, err)
+	}
+	return errResp(ErrUnexpectedResponse, "reqID = %v", reqID)
+}
+
 // reqStateFn represents a state of the retrieve loop state machine
 type reqStateFn func() reqStateFn
 
@@ -207,12 +244,18 @@ func (r *sentReq) stateRequesting() reqStateFn {
 					return r.stateNoMorePeers
 				}
 				// nothing to wait for, no more peers to ask, return with error
-				r.stop(ErrNoPeers)
+				r.lock.RLock()
+				err := r.rejectErr
+				r.lock.RUnlock()
+				if err == nil {
+					err = ErrNoPeers
+				}
+				r.stop(err)
 				// no need to go to stopped state because waiting() already returned false
 				return nil
 			}
-		case rpSoftTimeout:
-			// last request timed out, try asking a new peer
+		case rpSoftTimeout, rpRejected:
+			// last request timed out or was refused, try asking a new peer
 			go r.tryRequest()
 			r.reqQueued = true
 			return r.stateRequesting
@@ -267,6 +310,8 @@ func (r *sentReq) update(ev reqPeerEvent) {
 	case rpSoftTimeout:
 		r.reqSent = false
 		r.reqSrtoCount++
+	case rpRejected:
+		r.reqSent = false
 	case rpHardTimeout, rpDeliveredValid, rpDeliveredInvalid:
 		r.reqSrtoCount--
 	}
@@ -300,7 +345,7 @@ func (r *sentReq) tryRequest() {
 	}
 
 	reqSent := mclock.Now()
-	srto, hrto := false, false
+	srto, hrto, rejected := false, false, false
 
 	r.lock.RLock()
 	s, ok := r.sentTo[p]
@@ -323,18 +368,18 @@ func (r *sentReq) tryRequest() {
 			}
 		}
 
-		r.lock.Lock()
-		delete(r.sentTo, p)
-		r.lock.Unlock()
+		// servers that refused the request are not asked again
+		if !rejected {
+			r.lock.Lock()
+			delete(r.sentTo, p)
+			r.lock.Unlock()
+		}
 	}()
 
 	select {
-	case ok := <-s.valid:
-		if ok {
-			r.eventsCh <- reqPeerEvent{rpDeliveredValid, p}
-		} else {
-			r.eventsCh <- reqPeerEvent{rpDeliveredInvalid, p}
-		}
+	case ev := <-s.event:
+		rejected = ev == rpRejected
+		r.eventsCh <- reqPeerEvent{ev, p}
 		return
 	case <-time.After(softRequestTimeout):
 		srto = true
@@ -342,12 +387,12 @@ func (r *sentReq) tryRequest() {
 	}
 
 	select {
-	case ok := <-s.valid:
-		if ok {
-			r.eventsCh <- reqPeerEvent{rpDeliveredValid, p}
-		} else {
-			r.eventsCh <- reqPeerEvent{rpDeliveredInvalid, p}
+	case ev := <-s.event:
+		if ev == rpRejected {
+			// a new request has already been sent after the soft timeout
+			rejected, ev = true, rpDeliveredInvalid
 		}
+		r.eventsCh <- reqPeerEvent{ev, p}
 	case <-time.After(hardRequestTimeout):
 		hrto = true
 		r.eventsCh <- reqPeerEvent{rpHardTimeout, p}
@@ -364,11 +409,34 @@ func (r *sentReq) deliver(peer distPeer, msg *Msg) error {
 		return errResp(ErrUnexpectedResponse, "reqID = %v", msg.ReqID)
 	}
 	valid := r.validate(peer, msg) == nil
-	r.sentTo[peer] = sentReqToPeer{true, s.valid}
-	s.valid <- valid
+	r.sentTo[peer] = sentReqToPeer{true, s.event}
+	if !valid && msg.Truncated {
+		// the server announced the cut, don't hold it against it but ask others
+		r.rejectErr = ErrResponseTruncated
+		s.event <- rpRejected
+		return nil
+	}
 	if !valid {
+		s.event <- rpDeliveredInvalid
 		return errResp(ErrInvalidResponse, "reqID = %v", msg.ReqID)
 	}
+	s.event <- rpDeliveredValid
+	return nil
+}
+
+// reject a request sent to the given peer, retrying with other peers or failing
+// with the given error if there are none left
+func (r *sentReq) reject(peer distPeer, reqID uint64, err error) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	s, ok := r.sentTo[peer]
+	if !ok || s.delivered {
+		return errResp(ErrUnexpectedResponse, "reqID = %v", reqID)
+	}
+	r.sentTo[peer] = sentReqToPeer{true, s.event}
+	r.rejectErr = err
+	s.event <- rpRejected
 	return nil
 }
 
@@ -0,0 +1,150 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Tests that retrievals without a caller deadline are bounded by the retrieval
+// timeout, and that expired deadlines are reported as ErrOdrTimeout.
+func TestRetrieveTimeout(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	// Register a peer swallowing all requests without ever answering
+	dist := newRequestDistributor(nil, stop)
+	peer := &testDistPeer{}
+	dist.registerTestPeer(peer)
+
+	rm := newRetrieveManager(nil, dist, nil)
+	rm.timeout = 50 * time.Millisecond
+
+	retrieve := func(ctx context.Context) error {
+		req := &testDistReq{cost: 1, canSendTo: map[*testDistPeer]struct{}{peer: {}}}
+		errc := make(chan error, 1)
+		go func() {
+			errc <- rm.retrieve(ctx, genReqID(), &distReq{getCost: req.getCost, canSend: req.canSend, request: req.request}, func(distPeer, *Msg) error { return nil }, stop)
+		}()
+		select {
+		case err := <-errc:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("retrieval not aborted")
+			return nil
+		}
+	}
+	if err := retrieve(context.Background()); err != ErrOdrTimeout {
+		t.Errorf("retrieval without deadline: error mismatch: have %v, want %v", err, ErrOdrTimeout)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := retrieve(ctx); err != ErrOdrTimeout {
+		t.Errorf("retrieval with deadline: error mismatch: have %v, want %v", err, ErrOdrTimeout)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := retrieve(ctx); err != context.Canceled {
+		t.Errorf("cancelled retrieval: error mismatch: have %v, want %v", err, context.Canceled)
+	}
+}
+
+// Tests that requests refused by a server or answered with an announced cut are
+// retried with other servers, and that the reason is returned if none can serve
+// them.
+func TestRetrieveReject(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	dist := newRequestDistributor(nil, stop)
+	for i := 0; i < 2; i++ {
+		dist.registerTestPeer(&testDistPeer{})
+	}
+	rm := newRetrieveManager(nil, dist, nil)
+
+	// retrieve runs a retrieval, handing the requests sent to the respond callback
+	retrieve := func(respond func(reqID uint64, p distPeer), validate validatorFunc) error {
+		var (
+			reqID = genReqID()
+			sent  = make(chan distPeer, 2)
+			errc  = make(chan error, 1)
+		)
+		req := &distReq{
+			getCost: func(distPeer) uint64 { return 1 },
+			canSend: func(distPeer) bool { return true },
+			request: func(p distPeer) func() { return func() { sent <- p } },
+		}
+		go func() {
+			errc <- rm.retrieve(context.Background(), reqID, req, validate, stop)
+		}()
+		for {
+			select {
+			case p := <-sent:
+				respond(reqID, p)
+			case err := <-errc:
+				return err
+			case <-time.After(5 * time.Second):
+				t.Fatal("retrieval not finished")
+				return nil
+			}
+		}
+	}
+	limit := &CostLimitError{Wait: time.Second}
+	valid := func(distPeer, *Msg) error { return nil }
+
+	// A request refused by all servers fails with the reason they reported
+	rejects := 0
+	err := retrieve(func(reqID uint64, p distPeer) {
+		rejects++
+		if err := rm.reject(p, reqID, limit); err != nil {
+			t.Errorf("reject %d failed: %v", rejects, err)
+		}
+	}, valid)
+	if err != limit {
+		t.Errorf("error mismatch: have %v, want %v", err, limit)
+	}
+	if rejects != 2 {
+		t.Errorf("rejection count mismatch: have %d, want 2", rejects)
+	}
+	// A request refused by one server is served by the other one
+	rejects = 0
+	err = retrieve(func(reqID uint64, p distPeer) {
+		if rejects == 0 {
+			rejects++
+			rm.reject(p, reqID, limit)
+			return
+		}
+		if err := rm.deliver(p, &Msg{ReqID: reqID}); err != nil {
+			t.Errorf("delivery failed: %v", err)
+		}
+	}, valid)
+	if err != nil {
+		t.Errorf("retrieval failed: %v", err)
+	}
+	// Unusable responses announced as truncated are treated as refusals
+	err = retrieve(func(reqID uint64, p distPeer) {
+		if err := rm.deliver(p, &Msg{ReqID: reqID, Truncated: true}); err != nil {
+			t.Errorf("truncated delivery failed: %v", err)
+		}
+	}, func(distPeer, *Msg) error { return errors.New("incomplete") })
+	if err != ErrResponseTruncated {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrResponseTruncated)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/binary"
 	"math"
 	"sync"
+	"time"
 
 	"github.com/ethereumai/go-ethereumai/common"
 	"github.com/ethereumai/go-ethereumai/core"
@@ -43,9 +44,11 @@ type LesServer struct {
 	fcManager       *flowcontrol.ClientManager // nil if our node is client only
 	fcCostStats     *requestCostStats
 	defParams       *flowcontrol.ServerParams
+	costLimiter     *costLimiter
 	lesTopics       []discv5.Topic
 	privateKey      *ecdsa.PrivateKey
 	quitSync        chan struct{}
+	startTime       time.Time
 
 	chtIndexer, bloomTrieIndexer *core.ChainIndexer
 }
@@ -57,16 +60,11 @@ func NewLesServer(eai *eai.EthereumAI, config *eai.Config) (*LesServer, error) {
 		return nil, err
 	}
 
-	lesTopics := make([]discv5.Topic, len(AdvertiseProtocolVersions))
-	for i, pv := range AdvertiseProtocolVersions {
-		lesTopics[i] = lesTopic(eai.BlockChain().Genesis().Hash(), pv)
-	}
-
 	srv := &LesServer{
 		config:           config,
 		protocolManager:  pm,
 		quitSync:         quitSync,
-		lesTopics:        lesTopics,
+		lesTopics:        lesTopics(eai.BlockChain().Genesis().Hash(), AdvertiseProtocolVersions),
 		chtIndexer:       light.NewChtIndexer(eai.ChainDb(), false),
 		bloomTrieIndexer: light.NewBloomTrieIndexer(eai.ChainDb(), false),
 	}
@@ -93,6 +91,9 @@ func NewLesServer(eai *eai.EthereumAI, config *eai.Config) (*LesServer, error) {
 
 	srv.chtIndexer.Start(eai.BlockChain())
 	pm.server = srv
+	if config.LightServMaxResponseSize > 0 {
+		pm.maxResponseSize = config.LightServMaxResponseSize
+	}
 
 	srv.defParams = &flowcontrol.ServerParams{
 		BufLimit:    300000000,
@@ -100,13 +101,38 @@ func NewLesServer(eai *eai.EthereumAI, config *eai.Config) (*LesServer, error) {
 	}
 	srv.fcManager = flowcontrol.NewClientManager(uint64(config.LightServ), 10, 1000000000)
 	srv.fcCostStats = newCostStats(eai.ChainDb())
+	srv.costLimiter = newCostLimiter()
 	return srv, nil
 }
 
+// SetCostLimit limits the cost of the requests served to a single client and to
+// all clients together per second, protecting public light servers from bursts
+// of expensive requests. Requests exceeding the remaining budgets are refused,
+// LES/3 clients are told when to retry, older clients are disconnected. Zero
+// disables a limit.
+func (s *LesServer) SetCostLimit(peerLimit, totalLimit uint64) {
+	s.costLimiter.setLimits(peerLimit, totalLimit)
+}
+
 func (s *LesServer) Protocols() []p2p.Protocol {
 	return s.protocolManager.SubProtocols
 }
 
+// ServedRequests returns the number of items served by the server since startup,
+// keyed by request type.
+func (s *LesServer) ServedRequests() map[string]int64 {
+	return map[string]int64{
+		"headers":    servedHeaderMeter.Count(),
+		"bodies":     servedBodyMeter.Count(),
+		"code":       servedCodeMeter.Count(),
+		"receipts":   servedReceiptMeter.Count(),
+		"proofs":     servedProofMeter.Count(),
+		"helpertrie": servedHelperTrieMeter.Count(),
+		"txs":        servedTxMeter.Count(),
+		"txstatus":   servedTxStatusMeter.Count(),
+	}
+}
+
 // Start starts the LES server
 func (s *LesServer) Start(srvr *p2p.Server) {
 	s.protocolManager.Start(s.config.LightPeers)
@@ -123,9 +149,28 @@ func (s *LesServer) Start(srvr *p2p.Server) {
 		}
 	}
 	s.privateKey = srvr.PrivateKey
+	s.startTime = time.Now()
 	s.protocolManager.blockLoop()
 }
 
+// PeerCount returns the number of light clients currently connected.
+func (s *LesServer) PeerCount() int {
+	return s.protocolManager.peers.Len()
+}
+
+// RequestRate returns the average number of requests served per second since
+// the server was started.
+func (s *LesServer) RequestRate() float64 {
+	if s.startTime.IsZero() {
+		return 0
+	}
+	elapsed := time.Since(s.startTime).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(s.fcCostStats.servedCount()) / elapsed
+}
+
 func (s *LesServer) SetBloomBitsIndexer(bloomIndexer *core.ChainIndexer) {
 	bloomIndexer.AddChildIndexer(s.bloomTrieIndexer)
 }
@@ -224,9 +269,10 @@ func linRegFromBytes(data []byte) *linReg {
 }
 
 type requestCostStats struct {
-	lock  sync.RWMutex
-	db    eaidb.Database
-	stats map[uint64]*linReg
+	lock   sync.RWMutex
+	db     eaidb.Database
+	stats  map[uint64]*linReg
+	served uint64 // Number of requests served since startup
 }
 
 type requestCostStatsRlp []struct {
@@ -308,6 +354,8 @@ func (s *requestCostStats) update(msgCode, reqCnt, cost uint64) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
+	s.served++
+
 	c, ok := s.stats[msgCode]
 	if !ok || reqCnt == 0 {
 		return
@@ -315,6 +363,14 @@ func (s *requestCostStats) update(msgCode, reqCnt, cost uint64) {
 	c.add(float64(reqCnt), float64(cost))
 }
 
+// servedCount returns the number of requests served since startup.
+func (s *requestCostStats) servedCount() uint64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.served
+}
+
 func (pm *ProtocolManager) blockLoop() {
 	pm.wg.Add(1)
 	headCh := make(chan core.ChainHeadEvent, 10)
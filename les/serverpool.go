@@ -85,6 +85,12 @@ const (
 	// initStatsWeight is used to initialize previously unknown peers with good
 	// statistics to give a chance to prove themselves
 	initStatsWeight = 1
+	// topicPreference is the factor by which newly discovered servers are less
+	// likely to be selected for each discovery topic they rank below the preferred one
+	topicPreference = 10
+	// fastDiscoverLookups is the number of converged lookups per discovery topic
+	// after which the topic is searched at a slower pace
+	fastDiscoverLookups = 50
 )
 
 // serverPool implements a pool for storing and selecting newly discovered and already
@@ -98,11 +104,11 @@ type serverPool struct {
 	wg     *sync.WaitGroup
 	connWg sync.WaitGroup
 
-	topic discv5.Topic
+	topics []discv5.Topic // Searched discovery topics, the preferred one first
 
-	discSetPeriod chan time.Duration
-	discNodes     chan *discv5.Node
-	discLookups   chan bool
+	discSetPeriod []chan time.Duration // Search period setters of the topics
+	discNodes     chan topicNode
+	discLookups   chan topicLookup
 
 	entries              map[discover.NodeID]*poolEntry
 	lock                 sync.Mutex
@@ -115,6 +121,20 @@ type serverPool struct {
 	fastDiscover               bool
 }
 
+// topicNode is a server found by the search of the discovery topic with the
+// given index.
+type topicNode struct {
+	node  *discv5.Node
+	topic int
+}
+
+// topicLookup is a lookup finished by the search of the discovery topic with the
+// given index.
+type topicLookup struct {
+	converged bool
+	topic     int
+}
+
 // newServerPool creates a new serverPool instance
 func newServerPool(db eaidb.Database, quit chan struct{}, wg *sync.WaitGroup) *serverPool {
 	pool := &serverPool{
@@ -134,24 +154,58 @@ func newServerPool(db eaidb.Database, quit chan struct{}, wg *sync.WaitGroup) *s
 	return pool
 }
 
-func (pool *serverPool) start(server *p2p.Server, topic discv5.Topic) {
+// start launches the pool, searching for servers on all the given discovery
+// topics. The topics are expected in order of preference, the known servers
+// being stored under the first one.
+func (pool *serverPool) start(server *p2p.Server, topics []discv5.Topic) {
 	pool.server = server
-	pool.topic = topic
-	pool.dbKey = append([]byte("serverPool/"), []byte(topic)...)
+	pool.topics = topics
+	pool.dbKey = append([]byte("serverPool/"), []byte(topics[0])...)
 	pool.wg.Add(1)
 	pool.loadNodes()
 
 	if pool.server.DiscV5 != nil {
-		pool.discSetPeriod = make(chan time.Duration, 1)
-		pool.discNodes = make(chan *discv5.Node, 100)
-		pool.discLookups = make(chan bool, 100)
-		go pool.server.DiscV5.SearchTopic(pool.topic, pool.discSetPeriod, pool.discNodes, pool.discLookups)
+		pool.discNodes = make(chan topicNode, 100)
+		pool.discLookups = make(chan topicLookup, 100)
+		for i, topic := range pool.topics {
+			var (
+				setPeriod = make(chan time.Duration, 1)
+				nodes     = make(chan *discv5.Node, 100)
+				lookups   = make(chan bool, 100)
+			)
+			pool.discSetPeriod = append(pool.discSetPeriod, setPeriod)
+			go pool.server.DiscV5.SearchTopic(topic, setPeriod, nodes, lookups)
+			go pool.forwardTopic(i, nodes, lookups)
+		}
 	}
 
 	go pool.eventLoop()
 	pool.checkDial()
 }
 
+// forwardTopic tags the servers and lookups found by the search of a discovery
+// topic with the topic's index and forwards them to the event loop.
+func (pool *serverPool) forwardTopic(topic int, nodes <-chan *discv5.Node, lookups <-chan bool) {
+	for {
+		select {
+		case node := <-nodes:
+			select {
+			case pool.discNodes <- topicNode{node, topic}:
+			case <-pool.quit:
+				return
+			}
+		case converged := <-lookups:
+			select {
+			case pool.discLookups <- topicLookup{converged, topic}:
+			case <-pool.quit:
+				return
+			}
+		case <-pool.quit:
+			return
+		}
+	}
+}
+
 // connect should be called upon any incoming connection. If the connection has been
 // dialed by the server pool recently, the appropriate pool entry is returned.
 // Otherwise, the connection should be rejected.
@@ -270,13 +324,23 @@ func (pool *serverPool) adjustResponseTime(entry *poolEntry, time time.Duration,
 	}
 }
 
+// setDiscPeriod sets the search period of all the discovery topics.
+func (pool *serverPool) setDiscPeriod(period time.Duration) {
+	for _, setPeriod := range pool.discSetPeriod {
+		setPeriod <- period
+	}
+}
+
 // eventLoop handles pool events and mutex locking for all internal functions
 func (pool *serverPool) eventLoop() {
-	lookupCnt := 0
-	var convTime mclock.AbsTime
-	if pool.discSetPeriod != nil {
-		pool.discSetPeriod <- time.Millisecond * 100
-	}
+	// Converged lookups are counted per topic, searches slow down one by one
+	var (
+		lookupCnt = make([]int, len(pool.topics))
+		convTime  = make([]mclock.AbsTime, len(pool.topics))
+		slowed    = make([]bool, len(pool.topics))
+		slowCnt   int
+	)
+	pool.setDiscPeriod(time.Millisecond * 100)
 	for {
 		select {
 		case entry := <-pool.timeout:
@@ -307,29 +371,36 @@ func (pool *serverPool) eventLoop() {
 			}
 			pool.lock.Unlock()
 
-		case node := <-pool.discNodes:
+		case found := <-pool.discNodes:
 			pool.lock.Lock()
+			node := found.node
 			entry := pool.findOrNewNode(discover.NodeID(node.ID), node.IP, node.TCP)
+			if found.topic < entry.topic {
+				entry.topic = found.topic
+			}
 			pool.updateCheckDial(entry)
 			pool.lock.Unlock()
 
-		case conv := <-pool.discLookups:
-			if conv {
-				if lookupCnt == 0 {
-					convTime = mclock.Now()
+		case lookup := <-pool.discLookups:
+			if i := lookup.topic; lookup.converged && !slowed[i] {
+				if lookupCnt[i] == 0 {
+					convTime[i] = mclock.Now()
 				}
-				lookupCnt++
-				if pool.fastDiscover && (lookupCnt == 50 || time.Duration(mclock.Now()-convTime) > time.Minute) {
-					pool.fastDiscover = false
-					if pool.discSetPeriod != nil {
-						pool.discSetPeriod <- time.Minute
+				lookupCnt[i]++
+				if lookupCnt[i] == fastDiscoverLookups || time.Duration(mclock.Now()-convTime[i]) > time.Minute {
+					slowed[i] = true
+					if slowCnt++; slowCnt == len(slowed) {
+						pool.lock.Lock()
+						pool.fastDiscover = false
+						pool.lock.Unlock()
 					}
+					pool.discSetPeriod[i] <- time.Minute
 				}
 			}
 
 		case <-pool.quit:
-			if pool.discSetPeriod != nil {
-				close(pool.discSetPeriod)
+			for _, setPeriod := range pool.discSetPeriod {
+				close(setPeriod)
 			}
 			pool.connWg.Wait()
 			pool.saveNodes()
@@ -350,6 +421,7 @@ func (pool *serverPool) findOrNewNode(id discover.NodeID, ip net.IP, port uint16
 			addr:       make(map[string]*poolEntryAddress),
 			addrSelect: *newWeightedRandomSelect(),
 			shortRetry: shortRetryCnt,
+			topic:      len(pool.topics),
 		}
 		pool.entries[id] = entry
 		// initialize previously unknown peers with good statistics to give a chance to prove themselves
@@ -557,6 +629,7 @@ type poolEntry struct {
 
 	delayedRetry bool
 	shortRetry   int
+	topic        int // Index of the most preferred topic the entry was found on (len(topics) = none)
 }
 
 func (e *poolEntry) EncodeRLP(w io.Writer) error {
@@ -598,11 +671,12 @@ func (e *discoveredEntry) Weight() int64 {
 	if e.state != psNotConnected || e.delayedRetry {
 		return 0
 	}
+	weight := float64(1000000000) / math.Pow(topicPreference, float64(e.topic))
 	t := time.Duration(mclock.Now() - e.lastDiscovered)
 	if t <= discoverExpireStart {
-		return 1000000000
+		return int64(weight)
 	}
-	return int64(1000000000 * math.Exp(-float64(t-discoverExpireStart)/float64(discoverExpireConst)))
+	return int64(weight * math.Exp(-float64(t-discoverExpireStart)/float64(discoverExpireConst)))
 }
 
 // knownEntry implements wrsItem
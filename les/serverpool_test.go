@@ -0,0 +1,109 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereumai/go-ethereumai/common/mclock"
+	"github.com/ethereumai/go-ethereumai/eaidb"
+	"github.com/ethereumai/go-ethereumai/p2p/discv5"
+)
+
+// Tests that servers discovered on a more preferred topic are weighted higher
+// when selecting new servers to dial.
+func TestServerPoolTopicWeight(t *testing.T) {
+	now := mclock.Now()
+
+	preferred := discoveredEntry{topic: 0, lastDiscovered: now}
+	fallback := discoveredEntry{topic: 1, lastDiscovered: now}
+	if have, want := preferred.Weight(), fallback.Weight()*topicPreference; have != want {
+		t.Errorf("weight mismatch: have %d, want %d", have, want)
+	}
+}
+
+// Tests that discovery only slows down once the lookups of every topic have
+// converged enough times, each topic being counted separately.
+func TestServerPoolLookupsPerTopic(t *testing.T) {
+	var (
+		quit = make(chan struct{})
+		wg   = new(sync.WaitGroup)
+		pool = newServerPool(eaidb.NewMemDatabase(), quit, wg)
+	)
+	pool.topics = []discv5.Topic{"LES2", "LES"}
+	pool.dbKey = []byte("serverPool/LES2")
+	pool.discNodes = make(chan topicNode)
+	pool.discLookups = make(chan topicLookup)
+	for range pool.topics {
+		pool.discSetPeriod = append(pool.discSetPeriod, make(chan time.Duration, 1))
+	}
+	wg.Add(1)
+	go pool.eventLoop()
+	defer func() {
+		close(quit)
+		wg.Wait()
+	}()
+	// Drain the initial fast search periods
+	for i, setPeriod := range pool.discSetPeriod {
+		if period := <-setPeriod; period != 100*time.Millisecond {
+			t.Fatalf("topic %d: initial period mismatch: have %v, want %v", i, period, 100*time.Millisecond)
+		}
+	}
+	fast := func() bool {
+		pool.lock.Lock()
+		defer pool.lock.Unlock()
+		return pool.fastDiscover
+	}
+	// Converge the lookups of the first topic, only that search should slow down
+	for i := 0; i < fastDiscoverLookups; i++ {
+		pool.discLookups <- topicLookup{converged: true, topic: 0}
+	}
+	select {
+	case period := <-pool.discSetPeriod[0]:
+		if period != time.Minute {
+			t.Fatalf("topic 0: period mismatch: have %v, want %v", period, time.Minute)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("topic 0: search not slowed down")
+	}
+	if !fast() {
+		t.Fatalf("fast discovery stopped with a topic still searching")
+	}
+	// Converge the lookups of the second topic too, discovery should slow down
+	for i := 0; i < fastDiscoverLookups-1; i++ {
+		pool.discLookups <- topicLookup{converged: true, topic: 1}
+	}
+	select {
+	case period := <-pool.discSetPeriod[1]:
+		t.Fatalf("topic 1: search slowed down early to %v", period)
+	default:
+	}
+	pool.discLookups <- topicLookup{converged: true, topic: 1}
+	select {
+	case period := <-pool.discSetPeriod[1]:
+		if period != time.Minute {
+			t.Fatalf("topic 1: period mismatch: have %v, want %v", period, time.Minute)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("topic 1: search not slowed down")
+	}
+	if fast() {
+		t.Fatalf("fast discovery not stopped with all topics slowed down")
+	}
+}
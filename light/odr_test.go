@@ -156,6 +156,48 @@ func odrAccounts(ctx context.Context, db eaidb.Database, bc *core.BlockChain, lc
 	return res, st.Error()
 }
 
+// Tests that missing trie roots are retrieved through proofs, while other nodes
+// are only served if an earlier proof already fetched them.
+func TestGetTrieNode(t *testing.T) {
+	var (
+		sdb   = eaidb.NewMemDatabase()
+		ldb   = eaidb.NewMemDatabase()
+		gspec = core.Genesis{Alloc: core.GenesisAlloc{testBankAddress: {Balance: testBankFunds}, acc1Addr: {Balance: big.NewInt(1)}}}
+		root  = gspec.MustCommit(sdb).Root()
+		odr   = &testOdr{sdb: sdb, ldb: ldb}
+		id    = &TrieID{Root: root}
+	)
+	// Find a node below the root, only reachable through a key proof
+	it, _ := trie.New(root, trie.NewDatabase(sdb))
+	nodes := it.NodeIterator(nil)
+	nodes.Next(true)
+	for nodes.Next(true) && nodes.Hash() == (common.Hash{}) {
+	}
+	child := nodes.Hash()
+	if child == (common.Hash{}) {
+		t.Fatal("no hashed node below the root")
+	}
+	if _, err := GetTrieNode(context.Background(), odr, id, child); err != ErrTrieNodeUnavailable {
+		t.Fatalf("child node error mismatch: have %v, want %v", err, ErrTrieNodeUnavailable)
+	}
+	blob, err := GetTrieNode(context.Background(), odr, id, root)
+	if err != nil {
+		t.Fatalf("failed to retrieve root: %v", err)
+	}
+	if want, _ := sdb.Get(root[:]); !bytes.Equal(blob, want) {
+		t.Errorf("root mismatch: have %x, want %x", blob, want)
+	}
+	// Fetching the accounts proves every node below the root
+	for _, addr := range []common.Address{testBankAddress, acc1Addr} {
+		if err := odr.Retrieve(context.Background(), &TrieRequest{Id: id, Key: crypto.Keccak256(addr[:])}); err != nil {
+			t.Fatalf("failed to prove account %x: %v", addr, err)
+		}
+	}
+	if _, err := GetTrieNode(context.Background(), odr, id, child); err != nil {
+		t.Errorf("failed to retrieve proven child node: %v", err)
+	}
+}
+
 func TestOdrContractCallLes1(t *testing.T) { testChainOdr(t, 1, odrContractCall) }
 
 type callmsg struct {
@@ -19,6 +19,8 @@ package light
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/ethereumai/go-ethereumai/common"
 	"github.com/ethereumai/go-ethereumai/core"
@@ -173,6 +175,62 @@ func GetBlockLogs(ctx context.Context, odr OdrBackend, hash common.Hash, number
 	return logs, nil
 }
 
+// GetTransaction retrieves a canonical transaction and its location in the chain
+// by its hash. The position reported by the network is not trusted, the body of
+// the including block is retrieved and the transaction looked up in it.
+func GetTransaction(ctx context.Context, odr OdrBackend, txHash common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	if tx, blockHash, blockNumber, index := rawdb.ReadTransaction(odr.Database(), txHash); tx != nil {
+		return tx, blockHash, blockNumber, index, nil
+	}
+	r := &TxStatusRequest{Hashes: []common.Hash{txHash}}
+	if err := odr.Retrieve(ctx, r); err != nil {
+		return nil, common.Hash{}, 0, 0, err
+	}
+	status := r.Status[0]
+	if status.Status != core.TxStatusIncluded || status.Lookup == nil {
+		return nil, common.Hash{}, 0, 0, fmt.Errorf("transaction %x not mined", txHash)
+	}
+	lookup := status.Lookup
+	if canonical, err := GetCanonicalHash(ctx, odr, lookup.BlockIndex); err != nil {
+		return nil, common.Hash{}, 0, 0, err
+	} else if canonical != lookup.BlockHash {
+		return nil, common.Hash{}, 0, 0, fmt.Errorf("transaction %x not in the canonical chain", txHash)
+	}
+	body, err := GetBody(ctx, odr, lookup.BlockHash, lookup.BlockIndex)
+	if err != nil {
+		return nil, common.Hash{}, 0, 0, err
+	}
+	if lookup.Index >= uint64(len(body.Transactions)) || body.Transactions[lookup.Index].Hash() != txHash {
+		return nil, common.Hash{}, 0, 0, fmt.Errorf("transaction %x not found at reported position", txHash)
+	}
+	return body.Transactions[lookup.Index], lookup.BlockHash, lookup.BlockIndex, lookup.Index, nil
+}
+
+// ErrTrieNodeUnavailable is returned if a trie node is neither known locally nor
+// retrievable from the network, which only serves nodes along key proof paths.
+var ErrTrieNodeUnavailable = errors.New("trie node not available locally and not retrievable by hash")
+
+// GetTrieNode retrieves a trie node of the given trie by its hash. Servers only
+// serve nodes as part of Merkle proofs for a key, so on a local miss only the
+// root of the trie can be retrieved, which every proof contains. Any other node
+// is only available if an earlier proof already included it.
+func GetTrieNode(ctx context.Context, odr OdrBackend, id *TrieID, hash common.Hash) ([]byte, error) {
+	if blob, _ := odr.Database().Get(hash[:]); len(blob) > 0 {
+		return blob, nil
+	}
+	if hash != id.Root {
+		return nil, ErrTrieNodeUnavailable
+	}
+	r := &TrieRequest{Id: id, Key: hash[:]}
+	if err := odr.Retrieve(ctx, r); err != nil {
+		return nil, err
+	}
+	if blob, _ := odr.Database().Get(hash[:]); len(blob) > 0 {
+		return blob, nil
+	}
+	return nil, fmt.Errorf("trie root %x not found", hash)
+}
+
 // GetBloomBits retrieves a batch of compressed bloomBits vectors belonging to the given bit index and section indexes
 func GetBloomBits(ctx context.Context, odr OdrBackend, bitIdx uint, sectionIdxList []uint64) ([][]byte, error) {
 	db := odr.Database()
@@ -18,7 +18,6 @@ package light
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
 	"github.com/ethereumai/go-ethereumai/common"
@@ -141,8 +140,12 @@ func (t *odrTrie) GetKey(sha []byte) []byte {
 	return nil
 }
 
+// Prove constructs a merkle proof for the already hashed key, retrieving the
+// missing nodes on its path from the network and verifying them on the way.
 func (t *odrTrie) Prove(key []byte, fromLevel uint, proofDb eaidb.Putter) error {
-	return errors.New("not implemented, needs client/server interface split")
+	return t.do(key, func() error {
+		return t.trie.Prove(key, fromLevel, proofDb)
+	})
 }
 
 // do tries and retries to execute a function until it returns with no error or
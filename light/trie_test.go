@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -80,3 +81,35 @@ func diffTries(t1, t2 state.Trie) error {
 	}
 	return nil
 }
+
+// Tests that proofs built from an on-demand state retrieve the missing nodes and
+// match the ones of the full state.
+func TestProve(t *testing.T) {
+	var (
+		fulldb  = eaidb.NewMemDatabase()
+		lightdb = eaidb.NewMemDatabase()
+		gspec   = core.Genesis{Alloc: core.GenesisAlloc{testBankAddress: {Balance: testBankFunds}}}
+		genesis = gspec.MustCommit(fulldb)
+	)
+	gspec.MustCommit(lightdb)
+	blockchain, _ := core.NewBlockChain(fulldb, nil, params.TestChainConfig, eaiash.NewFullFaker(), vm.Config{})
+	gchain, _ := core.GenerateChain(params.TestChainConfig, genesis, eaiash.NewFaker(), fulldb, 4, testChainGen)
+	if _, err := blockchain.InsertChain(gchain); err != nil {
+		panic(err)
+	}
+	head := blockchain.CurrentHeader()
+	fullState, _ := state.New(head.Root, state.NewDatabase(fulldb))
+	lightState := NewState(context.Background(), head, &testOdr{sdb: fulldb, ldb: lightdb})
+
+	want, err := fullState.GetProof(testBankAddress)
+	if err != nil {
+		t.Fatalf("failed to prove full state: %v", err)
+	}
+	have, err := lightState.GetProof(testBankAddress)
+	if err != nil {
+		t.Fatalf("failed to prove light state: %v", err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("proof mismatch: have %x, want %x", have, want)
+	}
+}
@@ -441,6 +441,18 @@ func (self *TxPool) Add(ctx context.Context, tx *types.Transaction) error {
 	return nil
 }
 
+// Validate checks whether a transaction would pass the validation Add subjects it
+// to, without adding it to the pool.
+func (self *TxPool) Validate(ctx context.Context, tx *types.Transaction) error {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+
+	if hash := tx.Hash(); self.pending[hash] != nil {
+		return fmt.Errorf("Known transaction (%x)", hash[:4])
+	}
+	return self.validateTx(ctx, tx)
+}
+
 // AddTransactions adds all valid transactions to the pool and passes them to
 // the tx relay backend
 func (self *TxPool) AddBatch(ctx context.Context, txs []*types.Transaction) {
@@ -524,3 +536,21 @@ func (pool *TxPool) RemoveTx(hash common.Hash) {
 	pool.chainDb.Delete(hash[:])
 	pool.relay.Discard([]common.Hash{hash})
 }
+
+// RemoveTxs removes the transactions with the given hashes from the pool, taking
+// the pool lock only once. It returns the number of transactions actually removed.
+func (pool *TxPool) RemoveTxs(hashes []common.Hash) int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	removed := 0
+	for _, hash := range hashes {
+		if _, ok := pool.pending[hash]; ok {
+			delete(pool.pending, hash)
+			removed++
+		}
+		pool.chainDb.Delete(hash[:])
+	}
+	pool.relay.Discard(hashes)
+	return removed
+}
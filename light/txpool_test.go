@@ -141,3 +141,29 @@ func TestTxPool(t *testing.T) {
 		}
 	}
 }
+
+// Tests that a batch of transactions is removed at once, counting only the ones
+// actually pending and relaying a single discard.
+func TestTxPoolRemoveTxs(t *testing.T) {
+	relay := &testTxRelay{discard: make(chan int, 1)}
+	pool := &TxPool{
+		chainDb: eaidb.NewMemDatabase(),
+		relay:   relay,
+		pending: make(map[common.Hash]*types.Transaction),
+	}
+	var hashes []common.Hash
+	for i := 0; i < 3; i++ {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), acc1Addr, big.NewInt(10000), params.TxGas, nil, nil), types.HomesteadSigner{}, testBankKey)
+		pool.pending[tx.Hash()] = tx
+		hashes = append(hashes, tx.Hash())
+	}
+	if removed := pool.RemoveTxs([]common.Hash{hashes[0], hashes[2], {0x01}}); removed != 2 {
+		t.Errorf("removed count mismatch: have %d, want 2", removed)
+	}
+	if discarded := <-relay.discard; discarded != 3 {
+		t.Errorf("discarded count mismatch: have %d, want 3", discarded)
+	}
+	if pool.Stats() != 1 || pool.pending[hashes[1]] == nil {
+		t.Errorf("pending transactions mismatch: have %v", pool.pending)
+	}
+}
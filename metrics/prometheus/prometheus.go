@@ -0,0 +1,145 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package prometheus exposes go-metrics registries in the Prometheus text
+// exposition format.
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/ethereumai/go-ethereumai/metrics"
+)
+
+var (
+	// quantiles are the histogram and timer percentiles exported as summaries.
+	quantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999}
+
+	// resettingQuantiles are the resetting timer percentiles exported as summaries,
+	// in the percent notation the resetting timers expect.
+	resettingQuantiles = []float64{50, 95, 99}
+)
+
+// Handler returns an HTTP handler serving all the metrics of the registry in the
+// Prometheus text exposition format.
+func Handler(reg metrics.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write(Collect(reg))
+	})
+}
+
+// Collect renders all the metrics of the registry in the Prometheus text
+// exposition format, ordered by name.
+func Collect(reg metrics.Registry) []byte {
+	var names []string
+	reg.Each(func(name string, _ interface{}) {
+		names = append(names, name)
+	})
+	sort.Strings(names)
+
+	buf := new(bytes.Buffer)
+	for _, name := range names {
+		var (
+			metric = reg.Get(name)
+			key    = mutateKey(name)
+		)
+		switch m := metric.(type) {
+		case metrics.Counter:
+			writeGauge(buf, key, float64(m.Count()))
+		case metrics.Gauge:
+			writeGauge(buf, key, float64(m.Value()))
+		case metrics.GaugeFloat64:
+			writeGauge(buf, key, m.Value())
+		case metrics.Meter:
+			ms := m.Snapshot()
+			writeCounter(buf, key+"_total", ms.Count())
+			writeGauge(buf, key+"_rate1", ms.Rate1())
+			writeGauge(buf, key+"_rate5", ms.Rate5())
+			writeGauge(buf, key+"_rate15", ms.Rate15())
+			writeGauge(buf, key+"_rate_mean", ms.RateMean())
+		case metrics.Histogram:
+			hs := m.Snapshot()
+			writeSummary(buf, key, quantiles, hs.Percentiles(quantiles), float64(hs.Sum()), hs.Count())
+		case metrics.Timer:
+			ts := m.Snapshot()
+			writeSummary(buf, key, quantiles, ts.Percentiles(quantiles), float64(ts.Sum()), ts.Count())
+			writeGauge(buf, key+"_rate1", ts.Rate1())
+		case metrics.ResettingTimer:
+			ts := m.Snapshot()
+			if len(ts.Values()) == 0 {
+				continue
+			}
+			var (
+				ps  = ts.Percentiles(resettingQuantiles)
+				qs  = make([]float64, len(ps))
+				vs  = make([]float64, len(ps))
+				sum float64
+			)
+			for i, p := range ps {
+				qs[i], vs[i] = resettingQuantiles[i]/100, float64(p)
+			}
+			for _, v := range ts.Values() {
+				sum += float64(v)
+			}
+			writeSummary(buf, key, qs, vs, sum, int64(len(ts.Values())))
+		}
+	}
+	return buf.Bytes()
+}
+
+// writeCounter renders a single monotonic counter value.
+func writeCounter(buf *bytes.Buffer, key string, value int64) {
+	fmt.Fprintf(buf, "# TYPE %s counter\n%s %d\n\n", key, key, value)
+}
+
+// writeGauge renders a single gauge value.
+func writeGauge(buf *bytes.Buffer, key string, value float64) {
+	fmt.Fprintf(buf, "# TYPE %s gauge\n%s %s\n\n", key, key, formatFloat(value))
+}
+
+// writeSummary renders a set of quantiles along with their sum and count.
+func writeSummary(buf *bytes.Buffer, key string, quantiles, values []float64, sum float64, count int64) {
+	fmt.Fprintf(buf, "# TYPE %s summary\n", key)
+	for i, q := range quantiles {
+		fmt.Fprintf(buf, "%s{quantile=\"%s\"} %s\n", key, formatFloat(q), formatFloat(values[i]))
+	}
+	fmt.Fprintf(buf, "%s_sum %s\n%s_count %d\n\n", key, formatFloat(sum), key, count)
+}
+
+// formatFloat renders a float in the shortest form Prometheus accepts.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// mutateKey converts a go-metrics name (e.g. "eai/fetcher/fetch/bodies") into a
+// valid Prometheus metric name (e.g. "eai_fetcher_fetch_bodies").
+func mutateKey(name string) string {
+	key := []byte(name)
+	for i, c := range key {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == ':':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			key[i] = '_'
+		}
+	}
+	return string(key)
+}
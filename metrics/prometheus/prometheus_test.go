@@ -0,0 +1,72 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereumai/go-ethereumai/metrics"
+)
+
+func TestMutateKey(t *testing.T) {
+	tests := map[string]string{
+		"eai/fetcher/fetch/bodies":   "eai_fetcher_fetch_bodies",
+		"p2p/InboundTraffic":         "p2p_InboundTraffic",
+		"eai/db/chaindata/disk.read": "eai_db_chaindata_disk_read",
+		"1st-metric":                 "_st_metric",
+	}
+	for name, want := range tests {
+		if have := mutateKey(name); have != want {
+			t.Errorf("%s: key mismatch: have %s, want %s", name, have, want)
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = false }()
+
+	reg := metrics.NewRegistry()
+
+	metrics.NewRegisteredCounter("test/counter", reg).Inc(3)
+	metrics.NewRegisteredGauge("test/gauge", reg).Update(7)
+	metrics.NewRegisteredMeter("test/meter", reg).Mark(5)
+
+	timer := metrics.NewRegisteredTimer("test/timer", reg)
+	timer.Update(time.Second)
+	timer.Update(3 * time.Second)
+
+	out := string(Collect(reg))
+	for _, want := range []string{
+		"# TYPE test_counter gauge\ntest_counter 3\n",
+		"# TYPE test_gauge gauge\ntest_gauge 7\n",
+		"# TYPE test_meter_total counter\ntest_meter_total 5\n",
+		"# TYPE test_meter_rate1 gauge\n",
+		"# TYPE test_timer summary\n",
+		"test_timer{quantile=\"0.5\"} 2e+09\n",
+		"test_timer_sum 4e+09\ntest_timer_count 2\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "test_counter") > strings.Index(out, "test_timer") {
+		t.Errorf("metrics not sorted by name:\n%s", out)
+	}
+}
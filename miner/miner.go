@@ -19,7 +19,9 @@ package miner
 
 import (
 	"fmt"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/ethereumai/go-ethereumai/accounts"
 	"github.com/ethereumai/go-ethereumai/common"
@@ -31,9 +33,13 @@ import (
 	"github.com/ethereumai/go-ethereumai/eaidb"
 	"github.com/ethereumai/go-ethereumai/event"
 	"github.com/ethereumai/go-ethereumai/log"
+	"github.com/ethereumai/go-ethereumai/metrics"
 	"github.com/ethereumai/go-ethereumai/params"
 )
 
+// watchdogRestartCounter counts the times the watchdog restarted an idle miner.
+var watchdogRestartCounter = metrics.NewRegisteredCounter("miner/watchdog/restarts", nil)
+
 // Backend wraps all methods required for mining.
 type Backend interface {
 	AccountManager() *accounts.Manager
@@ -48,6 +54,7 @@ type Miner struct {
 
 	worker *worker
 
+	lock     sync.Mutex // Serialises starting and stopping the mining operation
 	coinbase common.Address
 	mining   int32
 	eai      Backend
@@ -55,6 +62,9 @@ type Miner struct {
 
 	canStart    int32 // can start indicates whether we can start the mining operation
 	shouldStart int32 // should start indicates whether we should start after sync
+
+	watchdogQuit chan struct{} // Quit channel of the running idle watchdog, if any
+	watchdogLock sync.Mutex    // Protects the watchdog quit channel
 }
 
 func New(eai Backend, config *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine) *Miner {
@@ -104,8 +114,16 @@ out:
 }
 
 func (self *Miner) Start(coinbase common.Address) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	self.start(coinbase)
+}
+
+// start is the unlocked version of Start, the caller must hold the miner lock.
+func (self *Miner) start(coinbase common.Address) {
 	atomic.StoreInt32(&self.shouldStart, 1)
-	self.SetEtherAIbase(coinbase)
+	self.setEtherAIbase(coinbase)
 
 	if atomic.LoadInt32(&self.canStart) == 0 {
 		log.Info("Network syncing, will start miner afterwards")
@@ -119,11 +137,92 @@ func (self *Miner) Start(coinbase common.Address) {
 }
 
 func (self *Miner) Stop() {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	self.stop()
+}
+
+// stop is the unlocked version of Stop, the caller must hold the miner lock.
+func (self *Miner) stop() {
 	self.worker.stop()
 	atomic.StoreInt32(&self.mining, 0)
 	atomic.StoreInt32(&self.shouldStart, 0)
 }
 
+// SetWatchdogTimeout configures the miner to be restarted if, while mining, it
+// neither attempted to seal nor mined a block within the given timeout, e.g.
+// because the consensus engine hung. A seal still in progress on the current
+// head counts as activity. A non-positive timeout disables the watchdog.
+func (self *Miner) SetWatchdogTimeout(timeout time.Duration) {
+	self.watchdogLock.Lock()
+	defer self.watchdogLock.Unlock()
+
+	if self.watchdogQuit != nil {
+		close(self.watchdogQuit)
+		self.watchdogQuit = nil
+	}
+	if timeout > 0 {
+		self.watchdogQuit = make(chan struct{})
+		go self.watchdog(timeout, self.watchdogQuit)
+	}
+}
+
+// watchdog periodically checks the sealing activity of the worker, restarting
+// the mining operation if it has been idle for longer than the timeout.
+func (self *Miner) watchdog(timeout time.Duration, quit chan struct{}) {
+	ticker := time.NewTicker(timeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			self.restartIdle(timeout)
+		case <-quit:
+			return
+		}
+	}
+}
+
+// restartIdle restarts the mining operation if it has been idle for longer than
+// the timeout. The check and the restart are done under the miner lock, so a
+// concurrent Stop is never undone by the restart.
+func (self *Miner) restartIdle(timeout time.Duration) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if !self.Mining() || self.Paused() || atomic.LoadInt32(&self.shouldStart) == 0 {
+		return
+	}
+	idle := self.worker.idle()
+	if idle < timeout {
+		return
+	}
+	log.Warn("Miner idle for too long, restarting", "idle", common.PrettyDuration(idle), "timeout", common.PrettyDuration(timeout))
+	watchdogRestartCounter.Inc(1)
+
+	self.stop()
+	self.start(self.coinbase)
+	log.Info("Miner restarted by watchdog")
+}
+
+// Pause suspends sealing while keeping the pending block up to date, so that
+// mining can be resumed without any warm-up. A pause lasts until Resume is called,
+// even if the miner is restarted meanwhile (e.g. around a sync).
+func (self *Miner) Pause() {
+	self.worker.pause()
+}
+
+// Resume restarts sealing on a paused miner.
+func (self *Miner) Resume() {
+	self.worker.resume()
+}
+
+// Paused reports whether sealing is suspended until the miner is resumed.
+func (self *Miner) Paused() bool {
+	return atomic.LoadInt32(&self.worker.paused) == 1
+}
+
 func (self *Miner) Register(agent Agent) {
 	if self.Mining() {
 		agent.Start()
@@ -176,7 +275,35 @@ func (self *Miner) PendingBlock() *types.Block {
 	return self.worker.pendingBlock()
 }
 
+// PendingStats returns the number of transactions included in the pending block
+// and the number skipped while assembling it, e.g. for exceeding the gas limit
+// or having nonce gaps. Zeros are returned if the miner isn't running.
+func (self *Miner) PendingStats() (included int, skipped int) {
+	return self.worker.pendingStats()
+}
+
+// SubscribePendingLogs starts delivering the logs of the transactions included
+// in the pending block whenever the miner reassembles it.
+func (self *Miner) SubscribePendingLogs(ch chan<- []*types.Log) event.Subscription {
+	return self.worker.subscribePendingLogs(ch)
+}
+
+// ForceInclude marks a pending transaction to be placed at the front of the
+// blocks the miner builds until it is mined.
+func (self *Miner) ForceInclude(hash common.Hash) error {
+	return self.worker.forceInclude(hash)
+}
+
 func (self *Miner) SetEtherAIbase(addr common.Address) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	self.setEtherAIbase(addr)
+}
+
+// setEtherAIbase is the unlocked version of SetEtherAIbase, the caller must hold
+// the miner lock.
+func (self *Miner) setEtherAIbase(addr common.Address) {
 	self.coinbase = addr
 	self.worker.setEtherAIbase(addr)
 }
@@ -71,6 +71,8 @@ type Work struct {
 	family    *set.Set       // family set (used for checking uncle invalidity)
 	uncles    *set.Set       // uncle set
 	tcount    int            // tx count in cycle
+	skipped   int            // number of transactions skipped in cycle
+	gasPool   *core.GasPool  // available gas used to pack transactions
 
 	Block *types.Block // the new block
 
@@ -95,6 +97,7 @@ type worker struct {
 
 	// update loop
 	mux          *event.TypeMux
+	logsFeed     event.Feed // pending logs, sent whenever the pending block is reassembled
 	txCh         chan core.TxPreEvent
 	txSub        event.Subscription
 	chainHeadCh  chan core.ChainHeadEvent
@@ -126,8 +129,15 @@ type worker struct {
 
 	unconfirmed *unconfirmedBlocks // set of locally mined blocks pending canonicalness confirmations
 
+	forced map[common.Hash]struct{} // transactions to commit ahead of all others until mined
+
+	lastActivity int64 // Unix nanoseconds of the last seal attempt or mined block (atomic)
+	heads        int64 // Number of chain head events received (atomic)
+	sealHeads    int64 // Number of chain head events received when work was last pushed (atomic)
+
 	// atomic status counters
 	mining int32
+	paused int32 // sealing suspended while the pending block is still assembled
 	atWork int32
 }
 
@@ -148,6 +158,7 @@ func newWorker(config *params.ChainConfig, engine consensus.Engine, coinbase com
 		coinbase:       coinbase,
 		agents:         make(map[Agent]struct{}),
 		unconfirmed:    newUnconfirmedBlocks(eai.BlockChain(), miningLogAtDepth),
+		forced:         make(map[common.Hash]struct{}),
 	}
 	// Subscribe TxPreEvent for tx pool
 	worker.txSub = eai.TxPool().SubscribeTxPreEvent(worker.txCh)
@@ -200,13 +211,38 @@ func (self *worker) pendingBlock() *types.Block {
 	return self.current.Block
 }
 
+// subscribePendingLogs registers a subscription for the logs of the pending block,
+// delivered each time transactions are committed to it.
+func (self *worker) subscribePendingLogs(ch chan<- []*types.Log) event.Subscription {
+	return self.logsFeed.Subscribe(ch)
+}
+
+// pendingStats returns the number of transactions included in the block being
+// mined and the number skipped while assembling it, or zeros if not mining.
+func (self *worker) pendingStats() (included int, skipped int) {
+	if atomic.LoadInt32(&self.mining) == 0 {
+		return 0, 0
+	}
+	self.currentMu.Lock()
+	defer self.currentMu.Unlock()
+
+	if self.current == nil {
+		return 0, 0
+	}
+	return len(self.current.txs), self.current.skipped
+}
+
 func (self *worker) start() {
 	self.mu.Lock()
 	defer self.mu.Unlock()
 
 	atomic.StoreInt32(&self.mining, 1)
+	self.markActivity()
 
-	// spin up agents
+	// spin up agents, unless sealing was paused before a restart
+	if atomic.LoadInt32(&self.paused) == 1 {
+		return
+	}
 	for agent := range self.agents {
 		agent.Start()
 	}
@@ -226,6 +262,38 @@ func (self *worker) stop() {
 	atomic.StoreInt32(&self.atWork, 0)
 }
 
+// pause stops all agents from sealing, but keeps the worker assembling pending
+// blocks for the configured coinbase so that sealing can resume instantly. The
+// pause survives stopping and restarting the worker until resumed explicitly.
+func (self *worker) pause() {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if atomic.LoadInt32(&self.mining) == 0 || !atomic.CompareAndSwapInt32(&self.paused, 0, 1) {
+		return
+	}
+	for agent := range self.agents {
+		agent.Stop()
+	}
+	atomic.StoreInt32(&self.atWork, 0)
+}
+
+// resume lifts the pause of the worker. If the worker is mining, its agents are
+// restarted and handed fresh work.
+func (self *worker) resume() {
+	self.mu.Lock()
+	if !atomic.CompareAndSwapInt32(&self.paused, 1, 0) || atomic.LoadInt32(&self.mining) == 0 {
+		self.mu.Unlock()
+		return
+	}
+	for agent := range self.agents {
+		agent.Start()
+	}
+	self.mu.Unlock()
+
+	self.commitNewWork()
+}
+
 func (self *worker) register(agent Agent) {
 	self.mu.Lock()
 	defer self.mu.Unlock()
@@ -250,6 +318,7 @@ func (self *worker) update() {
 		select {
 		// Handle ChainHeadEvent
 		case <-self.chainHeadCh:
+			atomic.AddInt64(&self.heads, 1)
 			self.commitNewWork()
 
 		// Handle ChainSideEvent
@@ -260,14 +329,14 @@ func (self *worker) update() {
 
 		// Handle TxPreEvent
 		case ev := <-self.txCh:
-			// Apply transaction to the pending state if we're not mining
-			if atomic.LoadInt32(&self.mining) == 0 {
+			// Apply transaction to the pending state if we're not sealing
+			if atomic.LoadInt32(&self.mining) == 0 || atomic.LoadInt32(&self.paused) == 1 {
 				self.currentMu.Lock()
 				acc, _ := types.Sender(self.current.signer, ev.Tx)
 				txs := map[common.Address]types.Transactions{acc: {ev.Tx}}
 				txset := types.NewTransactionsByPriceAndNonce(self.current.signer, txs)
 
-				self.current.commitTransactions(self.mux, txset, self.chain, self.coinbase)
+				self.current.commitTransactions(self.mux, &self.logsFeed, txset, self.chain, self.coinbase)
 				self.updateSnapshot()
 				self.currentMu.Unlock()
 			} else {
@@ -297,6 +366,8 @@ func (self *worker) wait() {
 			if result == nil {
 				continue
 			}
+			self.markActivity()
+
 			block := result.Block
 			work := result.Work
 
@@ -344,17 +415,35 @@ func (self *worker) wait() {
 
 // push sends a new work task to currently live miner agents.
 func (self *worker) push(work *Work) {
-	if atomic.LoadInt32(&self.mining) != 1 {
+	if atomic.LoadInt32(&self.mining) != 1 || atomic.LoadInt32(&self.paused) == 1 {
 		return
 	}
+	atomic.StoreInt64(&self.sealHeads, atomic.LoadInt64(&self.heads))
 	for agent := range self.agents {
 		atomic.AddInt32(&self.atWork, 1)
 		if ch := agent.Work(); ch != nil {
 			ch <- work
+			self.markActivity()
 		}
 	}
 }
 
+// markActivity records that the worker just attempted to seal or mined a block.
+func (self *worker) markActivity() {
+	atomic.StoreInt64(&self.lastActivity, time.Now().UnixNano())
+}
+
+// idle returns the time elapsed since the worker last attempted to seal or mined
+// a block. A seal still in progress counts as activity as long as no new head
+// arrived since its work was pushed, as sealing at a high difficulty may take
+// long without anything being wrong.
+func (self *worker) idle() time.Duration {
+	if atomic.LoadInt32(&self.atWork) > 0 && atomic.LoadInt64(&self.heads) == atomic.LoadInt64(&self.sealHeads) {
+		return 0
+	}
+	return time.Since(time.Unix(0, atomic.LoadInt64(&self.lastActivity)))
+}
+
 // makeCurrent creates a new environment for the current cycle.
 func (self *worker) makeCurrent(parent *types.Block, header *types.Header) error {
 	state, err := self.chain.StateAt(parent.Root())
@@ -454,8 +543,11 @@ func (self *worker) commitNewWork() {
 		log.Error("Failed to fetch pending transactions", "err", err)
 		return
 	}
+	if forced := self.takeForced(pending); len(forced) > 0 {
+		work.commitTransactions(self.mux, &self.logsFeed, types.NewTransactionsByPriceAndNonce(self.current.signer, forced), self.chain, self.coinbase)
+	}
 	txs := types.NewTransactionsByPriceAndNonce(self.current.signer, pending)
-	work.commitTransactions(self.mux, txs, self.chain, self.coinbase)
+	work.commitTransactions(self.mux, &self.logsFeed, txs, self.chain, self.coinbase)
 
 	// compute uncles for the new block.
 	var (
@@ -521,8 +613,73 @@ func (self *worker) updateSnapshot() {
 	self.snapshotState = self.current.state.Copy()
 }
 
-func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsByPriceAndNonce, bc *core.BlockChain, coinbase common.Address) {
-	gp := new(core.GasPool).AddGas(env.header.GasLimit)
+// forceInclude marks a pending transaction to be committed ahead of all others
+// in the blocks the worker builds, until it gets mined or leaves the pool. The
+// transaction must be the next executable one of its sender and fit into a block.
+func (self *worker) forceInclude(hash common.Hash) error {
+	pending, err := self.eai.TxPool().Pending()
+	if err != nil {
+		return err
+	}
+	for _, txs := range pending {
+		for i, tx := range txs {
+			if tx.Hash() != hash {
+				continue
+			}
+			if i > 0 {
+				return fmt.Errorf("transaction %x preceded by %d pending transactions of its sender", hash, i)
+			}
+			if limit := self.chain.CurrentBlock().GasLimit(); tx.Gas() > limit {
+				return fmt.Errorf("transaction %x gas %d exceeds block gas limit %d", hash, tx.Gas(), limit)
+			}
+			self.mu.Lock()
+			self.forced[hash] = struct{}{}
+			self.mu.Unlock()
+
+			log.Info("Forcing transaction inclusion", "hash", hash)
+			return nil
+		}
+	}
+	if self.eai.TxPool().Get(hash) != nil {
+		return fmt.Errorf("transaction %x not executable", hash)
+	}
+	return fmt.Errorf("transaction %x not found in pool", hash)
+}
+
+// takeForced moves the forced transactions out of the pending set so they can be
+// committed first. Marks of transactions no longer heading their sender's pending
+// list (mined, evicted or reordered by a reorg) are dropped.
+//
+// The method assumes the worker lock is held.
+func (self *worker) takeForced(pending map[common.Address]types.Transactions) map[common.Address]types.Transactions {
+	forced := make(map[common.Address]types.Transactions)
+	for hash := range self.forced {
+		found := false
+		for from, txs := range pending {
+			if txs[0].Hash() != hash {
+				continue
+			}
+			forced[from] = txs[:1]
+			if len(txs) > 1 {
+				pending[from] = txs[1:]
+			} else {
+				delete(pending, from)
+			}
+			found = true
+			break
+		}
+		if !found {
+			delete(self.forced, hash)
+		}
+	}
+	return forced
+}
+
+func (env *Work) commitTransactions(mux *event.TypeMux, logsFeed *event.Feed, txs *types.TransactionsByPriceAndNonce, bc *core.BlockChain, coinbase common.Address) {
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	gp := env.gasPool
 
 	var coalescedLogs []*types.Log
 
@@ -547,6 +704,7 @@ func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsB
 		if tx.Protected() && !env.config.IsEIP155(env.header.Number) {
 			log.Trace("Ignoring reply protected transaction", "hash", tx.Hash(), "eip155", env.config.EIP155Block)
 
+			env.skipped++
 			txs.Pop()
 			continue
 		}
@@ -558,16 +716,19 @@ func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsB
 		case core.ErrGasLimitReached:
 			// Pop the current out-of-gas transaction without shifting in the next from the account
 			log.Trace("Gas limit exceeded for current block", "sender", from)
+			env.skipped++
 			txs.Pop()
 
 		case core.ErrNonceTooLow:
 			// New head notification data race between the transaction pool and miner, shift
 			log.Trace("Skipping transaction with low nonce", "sender", from, "nonce", tx.Nonce())
+			env.skipped++
 			txs.Shift()
 
 		case core.ErrNonceTooHigh:
 			// Reorg notification data race between the transaction pool and miner, skip account =
 			log.Trace("Skipping account with hight nonce", "sender", from, "nonce", tx.Nonce())
+			env.skipped++
 			txs.Pop()
 
 		case nil:
@@ -580,6 +741,7 @@ func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsB
 			// Strange error, discard the transaction and get the next in line (note, the
 			// nonce-too-high clause will prevent us from executing in vain).
 			log.Debug("Transaction failed, account skipped", "hash", tx.Hash(), "err", err)
+			env.skipped++
 			txs.Shift()
 		}
 	}
@@ -596,6 +758,7 @@ func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsB
 		go func(logs []*types.Log, tcount int) {
 			if len(logs) > 0 {
 				mux.Post(core.PendingLogsEvent{Logs: logs})
+				logsFeed.Send(logs)
 			}
 			if tcount > 0 {
 				mux.Post(core.PendingStateEvent{})
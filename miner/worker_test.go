@@ -0,0 +1,170 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereumai/go-ethereumai/common"
+	"github.com/ethereumai/go-ethereumai/core/types"
+)
+
+// Tests that forced transactions are split off the pending set, and that marks
+// of transactions no longer heading their sender's list are dropped.
+func TestTakeForced(t *testing.T) {
+	var (
+		alice = common.Address{0x01}
+		bob   = common.Address{0x02}
+
+		a0   = types.NewTransaction(0, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+		a1   = types.NewTransaction(1, common.Address{}, big.NewInt(0), 21000, big.NewInt(1), nil)
+		b0   = types.NewTransaction(0, common.Address{0xff}, big.NewInt(0), 21000, big.NewInt(1), nil)
+		gone = common.Hash{0xde, 0xad}
+	)
+	w := &worker{forced: map[common.Hash]struct{}{
+		a0.Hash(): {},
+		b0.Hash(): {},
+		gone:      {},
+	}}
+	pending := map[common.Address]types.Transactions{
+		alice: {a0, a1},
+		bob:   {b0},
+	}
+	forced := w.takeForced(pending)
+
+	if len(forced) != 2 || forced[alice][0] != a0 || forced[bob][0] != b0 {
+		t.Fatalf("forced set mismatch: %v", forced)
+	}
+	if len(pending) != 1 || len(pending[alice]) != 1 || pending[alice][0] != a1 {
+		t.Fatalf("pending set mismatch: %v", pending)
+	}
+	if _, ok := w.forced[gone]; ok {
+		t.Errorf("stale forced mark retained")
+	}
+	if len(w.forced) != 2 {
+		t.Errorf("forced marks mismatch: have %d, want 2", len(w.forced))
+	}
+}
+
+// testAgent is a mock sealing agent recording whether it is running.
+type testAgent struct {
+	running bool
+	workCh  chan *Work
+}
+
+func (a *testAgent) Work() chan<- *Work         { return a.workCh }
+func (a *testAgent) SetReturnCh(chan<- *Result) {}
+func (a *testAgent) Start()                     { a.running = true }
+func (a *testAgent) Stop()                      { a.running = false }
+func (a *testAgent) GetHashRate() int64         { return 0 }
+
+// Tests that pausing a mining worker stops its agents and withholds new work
+// from them, while keeping the worker itself in mining mode.
+func TestWorkerPause(t *testing.T) {
+	agent := &testAgent{workCh: make(chan *Work, 1)}
+	w := &worker{agents: map[Agent]struct{}{agent: {}}}
+
+	// Pausing an idle worker is a noop
+	w.pause()
+	if w.paused != 0 {
+		t.Fatalf("idle worker paused")
+	}
+	w.start()
+	if !agent.running {
+		t.Fatalf("agent not started")
+	}
+	w.pause()
+	if agent.running {
+		t.Errorf("agent running while paused")
+	}
+	if w.mining != 1 {
+		t.Errorf("worker left mining mode while paused")
+	}
+	w.push(new(Work))
+	if len(agent.workCh) != 0 {
+		t.Errorf("work pushed to paused agent")
+	}
+	w.stop()
+}
+
+// Tests that a pause survives the worker being stopped and restarted, as done by
+// the miner around a sync, and is only lifted by an explicit resume.
+func TestWorkerPauseRestart(t *testing.T) {
+	agent := &testAgent{workCh: make(chan *Work, 1)}
+	w := &worker{agents: map[Agent]struct{}{agent: {}}}
+
+	w.start()
+	w.pause()
+
+	// Downloader start stops the miner, done restarts it
+	w.stop()
+	w.start()
+	if agent.running {
+		t.Errorf("agent restarted while paused")
+	}
+	if w.paused != 1 {
+		t.Errorf("pause lost across restart")
+	}
+	w.push(new(Work))
+	if len(agent.workCh) != 0 {
+		t.Errorf("work pushed to paused agent")
+	}
+	// Resuming a stopped worker only lifts the pause
+	w.stop()
+	w.resume()
+	if agent.running || w.paused != 0 {
+		t.Errorf("stopped worker resume mismatch: agent running %v, paused %d", agent.running, w.paused)
+	}
+	w.start()
+	if !agent.running {
+		t.Errorf("agent not started after resume")
+	}
+}
+
+// Tests that handing work to the agents counts as sealing activity for the idle
+// watchdog, unless the worker is paused, and that a seal in progress keeps the
+// worker active until a new head arrives.
+func TestWorkerIdle(t *testing.T) {
+	agent := &testAgent{workCh: make(chan *Work, 2)}
+	w := &worker{agents: map[Agent]struct{}{agent: {}}}
+
+	w.start()
+	atomic.StoreInt64(&w.lastActivity, time.Now().Add(-time.Hour).UnixNano())
+
+	w.pause()
+	w.push(new(Work))
+	if idle := w.idle(); idle < time.Hour {
+		t.Errorf("paused worker marked active: idle %v", idle)
+	}
+	atomic.StoreInt32(&w.paused, 0)
+	w.push(new(Work))
+	if idle := w.idle(); idle >= time.Minute {
+		t.Errorf("work push not marked as activity: idle %v", idle)
+	}
+	// A long seal on the current head is not idle, one on a stale head is
+	atomic.StoreInt64(&w.lastActivity, time.Now().Add(-time.Hour).UnixNano())
+	if idle := w.idle(); idle != 0 {
+		t.Errorf("seal in progress marked idle: idle %v", idle)
+	}
+	atomic.AddInt64(&w.heads, 1)
+	if idle := w.idle(); idle < time.Hour {
+		t.Errorf("seal on stale head marked active: idle %v", idle)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sort"
 	"time"
 
 	"github.com/ethereumai/go-ethereumai/log"
@@ -38,6 +39,10 @@ const (
 	// once every few seconds.
 	lookupInterval = 4 * time.Second
 
+	// While the node has fewer peers than its configured minimum, lookups are
+	// ramped up to run at this faster pace.
+	urgentLookupInterval = time.Second
+
 	// If no peers are found for this amount of time, the initial bootnodes are
 	// attempted to be connected.
 	fallbackInterval = 20 * time.Second
@@ -70,8 +75,10 @@ func (t TCPDialer) Dial(dest *discover.Node) (net.Conn, error) {
 // of the main loop in Server.run.
 type dialstate struct {
 	maxDynDials int
+	minPeers    int // peer count below which discovery lookups are ramped up
 	ntab        discoverTable
 	netrestrict *netutil.Netlist
+	prefer      ipFamily // address family to dial first among dynamic candidates
 
 	lookupRunning bool
 	dialing       map[discover.NodeID]connFlag
@@ -84,6 +91,34 @@ type dialstate struct {
 	bootnodes []*discover.Node // default dials when there are no peers
 }
 
+// ipFamily identifies an IP address family to favour when dialing.
+type ipFamily int
+
+const (
+	familyMixed ipFamily = iota // no preference, dial in discovery order
+	familyIPv4
+	familyIPv6
+)
+
+// matches returns whether the IP address belongs to the family.
+func (f ipFamily) matches(ip net.IP) bool {
+	if ip.To4() != nil {
+		return f == familyIPv4
+	}
+	return f == familyIPv6
+}
+
+// prioritize stably reorders the nodes so that the ones of the preferred family
+// are dialed first. It is a no-op in mixed mode.
+func (f ipFamily) prioritize(nodes []*discover.Node) {
+	if f == familyMixed {
+		return
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return f.matches(nodes[i].IP) && !f.matches(nodes[j].IP)
+	})
+}
+
 type discoverTable interface {
 	Self() *discover.Node
 	Close()
@@ -118,6 +153,7 @@ type dialTask struct {
 // Only one discoverTask is active at any time.
 // discoverTask.Do performs a random lookup.
 type discoverTask struct {
+	urgent  bool // whether the node is short of its minimum peer count
 	results []*discover.Node
 }
 
@@ -220,6 +256,7 @@ func (s *dialstate) newTasks(nRunning int, peers map[discover.NodeID]*Peer, now
 	randomCandidates := needDynDials / 2
 	if randomCandidates > 0 {
 		n := s.ntab.ReadRandomNodes(s.randomNodes)
+		s.prefer.prioritize(s.randomNodes[:n])
 		for i := 0; i < randomCandidates && i < n; i++ {
 			if addDial(dynDialedConn, s.randomNodes[i]) {
 				needDynDials--
@@ -235,10 +272,13 @@ func (s *dialstate) newTasks(nRunning int, peers map[discover.NodeID]*Peer, now
 		}
 	}
 	s.lookupBuf = s.lookupBuf[:copy(s.lookupBuf, s.lookupBuf[i:])]
-	// Launch a discovery lookup if more candidates are needed.
-	if len(s.lookupBuf) < needDynDials && !s.lookupRunning {
+	// Launch a discovery lookup if more candidates are needed. While short of
+	// the minimum peer count, keep looking up until a full set of candidates
+	// is buffered.
+	urgent := len(peers) < s.minPeers
+	if !s.lookupRunning && (len(s.lookupBuf) < needDynDials || urgent && len(s.lookupBuf) < s.maxDynDials) {
 		s.lookupRunning = true
-		newtasks = append(newtasks, &discoverTask{})
+		newtasks = append(newtasks, &discoverTask{urgent: urgent})
 	}
 
 	// Launch a timer to wait for the next node to expire if all
@@ -285,6 +325,7 @@ func (s *dialstate) taskDone(t task, now time.Time) {
 	case *discoverTask:
 		s.lookupRunning = false
 		s.lookupBuf = append(s.lookupBuf, t.results...)
+		s.prefer.prioritize(s.lookupBuf)
 	}
 }
 
@@ -362,7 +403,11 @@ func (t *discoverTask) Do(srv *Server) {
 	// newTasks generates a lookup task whenever dynamic dials are
 	// necessary. Lookups need to take some time, otherwise the
 	// event loop spins too fast.
-	next := srv.lastLookup.Add(lookupInterval)
+	interval := lookupInterval
+	if t.urgent {
+		interval = urgentLookupInterval
+	}
+	next := srv.lastLookup.Add(interval)
 	if now := time.Now(); now.Before(next) {
 		time.Sleep(next.Sub(now))
 	}
@@ -627,6 +627,79 @@ func TestDialStateCache(t *testing.T) {
 	})
 }
 
+// Tests that discovery lookups are launched eagerly while the node is short of its
+// minimum peer count, even if no dynamic dial slots are free.
+func TestDialStateMinPeers(t *testing.T) {
+	state := newDialState(nil, nil, fakeTable{}, 2, nil)
+	state.minPeers = 3
+
+	runDialTest(t, dialtest{
+		init: state,
+		rounds: []round{
+			// An urgent lookup is launched with all dynamic slots taken.
+			{
+				peers: []*Peer{
+					{rw: &conn{flags: dynDialedConn, id: uintID(0)}},
+					{rw: &conn{flags: dynDialedConn, id: uintID(1)}},
+				},
+				new: []task{&discoverTask{urgent: true}},
+			},
+			// No further lookups once a full set of candidates is buffered.
+			{
+				peers: []*Peer{
+					{rw: &conn{flags: dynDialedConn, id: uintID(0)}},
+					{rw: &conn{flags: dynDialedConn, id: uintID(1)}},
+				},
+				done: []task{
+					&discoverTask{urgent: true, results: []*discover.Node{
+						{ID: uintID(2)},
+						{ID: uintID(3)},
+					}},
+				},
+				new: nil,
+			},
+			// Dials resume from the buffer once a slot frees up, without an
+			// urgent lookup as the minimum is reached through a static peer.
+			{
+				peers: []*Peer{
+					{rw: &conn{flags: staticDialedConn, id: uintID(4)}},
+					{rw: &conn{flags: dynDialedConn, id: uintID(0)}},
+					{rw: &conn{flags: staticDialedConn, id: uintID(5)}},
+				},
+				new: []task{
+					&dialTask{flags: dynDialedConn, dest: &discover.Node{ID: uintID(2)}},
+				},
+			},
+		},
+	})
+}
+
+// Tests that dial candidates are reordered by the preferred address family while
+// keeping the discovery order within each family.
+func TestDialFamilyPreference(t *testing.T) {
+	var (
+		v4a = discover.NewNode(uintID(1), net.IP{10, 0, 0, 1}, 30303, 30303)
+		v6a = discover.NewNode(uintID(2), net.ParseIP("fd00::1"), 30303, 30303)
+		v4b = discover.NewNode(uintID(3), net.IP{10, 0, 0, 2}, 30303, 30303)
+		v6b = discover.NewNode(uintID(4), net.ParseIP("fd00::2"), 30303, 30303)
+	)
+	tests := []struct {
+		prefer ipFamily
+		want   []*discover.Node
+	}{
+		{familyMixed, []*discover.Node{v4a, v6a, v4b, v6b}},
+		{familyIPv4, []*discover.Node{v4a, v4b, v6a, v6b}},
+		{familyIPv6, []*discover.Node{v6a, v6b, v4a, v4b}},
+	}
+	for i, tt := range tests {
+		nodes := []*discover.Node{v4a, v6a, v4b, v6b}
+		tt.prefer.prioritize(nodes)
+		if !reflect.DeepEqual(nodes, tt.want) {
+			t.Errorf("test %d: order mismatch: have %v, want %v", i, nodes, tt.want)
+		}
+	}
+}
+
 func TestDialResolve(t *testing.T) {
 	resolved := discover.NewNode(uintID(1), net.IP{127, 0, 55, 234}, 3333, 4444)
 	table := &resolveMock{answer: resolved}
@@ -22,6 +22,7 @@ import (
 	"net"
 
 	"github.com/ethereumai/go-ethereumai/metrics"
+	"github.com/ethereumai/go-ethereumai/p2p/discover"
 )
 
 var (
@@ -29,8 +30,35 @@ var (
 	ingressTrafficMeter = metrics.NewRegisteredMeter("p2p/InboundTraffic", nil)
 	egressConnectMeter  = metrics.NewRegisteredMeter("p2p/OutboundConnects", nil)
 	egressTrafficMeter  = metrics.NewRegisteredMeter("p2p/OutboundTraffic", nil)
+
+	peerGauge     = metrics.NewRegisteredGauge("p2p/peers", nil)
+	minPeerGauge  = metrics.NewRegisteredGauge("p2p/peers/min", nil)
+	ipv4PeerGauge = metrics.NewRegisteredGauge("p2p/peers/ipv4", nil)
+	ipv6PeerGauge = metrics.NewRegisteredGauge("p2p/peers/ipv6", nil)
 )
 
+// updatePeerGauges counts the connected peers, in total to be compared against
+// the minimum peer count and per IP address family, allowing operators to verify
+// that a dialing preference takes effect.
+func updatePeerGauges(peers map[discover.NodeID]*Peer) {
+	peerGauge.Update(int64(len(peers)))
+
+	var ipv4, ipv6 int64
+	for _, p := range peers {
+		addr, ok := p.RemoteAddr().(*net.TCPAddr)
+		if !ok {
+			continue
+		}
+		if addr.IP.To4() != nil {
+			ipv4++
+		} else {
+			ipv6++
+		}
+	}
+	ipv4PeerGauge.Update(ipv4)
+	ipv6PeerGauge.Update(ipv6)
+}
+
 // meteredConn is a wrapper around a network TCP connection that meters both the
 // inbound and outbound network traffic.
 type meteredConn struct {
@@ -98,6 +98,41 @@ func TestPeerProtoReadMsg(t *testing.T) {
 	}
 }
 
+// Tests that the payload of sub-protocol messages is accounted per message type
+// in both directions.
+func TestPeerProtoTraffic(t *testing.T) {
+	proto := Protocol{
+		Name:    "traffic",
+		Version: 1,
+		Length:  5,
+		Run: func(peer *Peer, rw MsgReadWriter) error {
+			if err := ExpectMsg(rw, 2, []uint{1}); err != nil {
+				t.Error(err)
+			}
+			return SendItems(rw, 3, []uint{1, 2, 3})
+		},
+	}
+	closer, rw, _, errc := testPeer([]Protocol{proto})
+	defer closer()
+
+	Send(rw, baseProtocolLength+2, []uint{1})
+	if err := ExpectMsg(rw, baseProtocolLength+3, []interface{}{[]uint{1, 2, 3}}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-errc:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("protocol timeout")
+	}
+	stats := TrafficStats()
+	if in := stats[TrafficKey{"traffic", 1, 2}]; in.Ingress != 2 || in.Egress != 0 {
+		t.Errorf("ingress traffic mismatch: have %+v, want 2 bytes in", in)
+	}
+	if out := stats[TrafficKey{"traffic", 1, 3}]; out.Egress != 5 || out.Ingress != 0 {
+		t.Errorf("egress traffic mismatch: have %+v, want 5 bytes out", out)
+	}
+}
+
 func TestPeerProtoEncodeMsg(t *testing.T) {
 	proto := Protocol{
 		Name:   "a",
@@ -62,6 +62,11 @@ type Config struct {
 	// connected. It must be greater than zero.
 	MaxPeers int
 
+	// MinPeers is the number of connected peers below which the node actively
+	// seeks new ones, running discovery lookups at a faster pace. Zero disables
+	// the ramp-up.
+	MinPeers int `toml:",omitempty"`
+
 	// MaxPendingPeers is the maximum number of peers that can be pending in the
 	// handshake phase, counted separately for inbound and outbound connections.
 	// Zero defaults to preset values.
@@ -106,6 +111,12 @@ type Config struct {
 	// IP networks contained in the list are considered.
 	NetRestrict *netutil.Netlist `toml:",omitempty"`
 
+	// PreferIPv6 makes the dialer try discovered IPv6 peers before IPv4 ones and
+	// PreferIPv4 the other way around. With neither set (mixed mode) candidates
+	// are dialed in the order discovery returns them.
+	PreferIPv6 bool `toml:",omitempty"`
+	PreferIPv4 bool `toml:",omitempty"`
+
 	// NodeDatabase is the path to the database containing the previously seen
 	// live nodes in the network.
 	NodeDatabase string `toml:",omitempty"`
@@ -484,6 +495,21 @@ func (srv *Server) Start() (err error) {
 
 	dynPeers := srv.maxDialedConns()
 	dialer := newDialState(srv.StaticNodes, srv.BootstrapNodes, srv.ntab, dynPeers, srv.NetRestrict)
+	switch {
+	case srv.PreferIPv6 && srv.PreferIPv4:
+		srv.log.Warn("Conflicting IP family preferences, dialing in mixed mode")
+	case srv.PreferIPv6:
+		dialer.prefer = familyIPv6
+	case srv.PreferIPv4:
+		dialer.prefer = familyIPv4
+	}
+	if srv.MinPeers > srv.MaxPeers {
+		srv.log.Warn("Minimum peer count above the maximum, capping", "min", srv.MinPeers, "max", srv.MaxPeers)
+		dialer.minPeers = srv.MaxPeers
+	} else {
+		dialer.minPeers = srv.MinPeers
+	}
+	minPeerGauge.Update(int64(dialer.minPeers))
 
 	// handshake
 	srv.ourHandshake = &protoHandshake{Version: baseProtocolVersion, Name: srv.Name, ID: discover.PubkeyID(&srv.PrivateKey.PublicKey)}
@@ -648,6 +674,7 @@ running:
 				if p.Inbound() {
 					inboundCount++
 				}
+				updatePeerGauges(peers)
 			}
 			// The dialer logic relies on the assumption that
 			// dial tasks complete after the peer has been added or
@@ -665,6 +692,7 @@ running:
 			if pd.Inbound() {
 				inboundCount--
 			}
+			updatePeerGauges(peers)
 		}
 	}
 
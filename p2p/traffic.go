@@ -0,0 +1,116 @@
+// Copyright 2018 The go-ethereumai Authors
+// This file is part of the go-ethereumai library.
+//
+// The go-ethereumai library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereumai library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereumai library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereumai/go-ethereumai/metrics"
+)
+
+// TrafficKey identifies a sub-protocol message type in the traffic statistics.
+type TrafficKey struct {
+	Protocol string // Name of the sub-protocol
+	Version  uint   // Version of the sub-protocol
+	Code     uint64 // Message code within the sub-protocol
+}
+
+// Traffic is the number of message payload bytes exchanged for a message type.
+type Traffic struct {
+	Ingress uint64
+	Egress  uint64
+}
+
+// trafficEntry accumulates the traffic of a single message type, mirroring it
+// into meters if the metrics system is enabled. The counters are only updated
+// atomically, so peers account their messages without any shared lock.
+type trafficEntry struct {
+	ingress      uint64 // Accessed atomically
+	egress       uint64 // Accessed atomically
+	ingressMeter metrics.Meter
+	egressMeter  metrics.Meter
+}
+
+// trafficProto identifies a sub-protocol whose traffic is accounted.
+type trafficProto struct {
+	name    string
+	version uint
+}
+
+var (
+	trafficLock  sync.Mutex                               // Protects the registry, not the counters
+	trafficStats = make(map[trafficProto][]*trafficEntry) // Counters of each protocol, indexed by message code
+)
+
+// trafficCounters returns the counters of every message type of a sub-protocol,
+// creating any missing ones. It's called once per protocol when a peer connects,
+// keeping the registry lock off the message path.
+func trafficCounters(proto Protocol) []*trafficEntry {
+	key := trafficProto{name: proto.Name, version: proto.Version}
+
+	trafficLock.Lock()
+	defer trafficLock.Unlock()
+
+	entries := trafficStats[key]
+	for code := uint64(len(entries)); code < proto.Length; code++ {
+		prefix := fmt.Sprintf("p2p/traffic/%s/%d/%#02x", proto.Name, proto.Version, code)
+		entries = append(entries, &trafficEntry{
+			ingressMeter: metrics.NewRegisteredMeter(prefix+"/ingress", nil),
+			egressMeter:  metrics.NewRegisteredMeter(prefix+"/egress", nil),
+		})
+	}
+	trafficStats[key] = entries
+	return entries
+}
+
+// recordTraffic accounts the payload of a sub-protocol message sent to or
+// received from a peer.
+func (rw *protoRW) recordTraffic(code uint64, size uint32, ingress bool) {
+	entry := rw.traffic[code]
+	if ingress {
+		atomic.AddUint64(&entry.ingress, uint64(size))
+		entry.ingressMeter.Mark(int64(size))
+	} else {
+		atomic.AddUint64(&entry.egress, uint64(size))
+		entry.egressMeter.Mark(int64(size))
+	}
+}
+
+// TrafficStats returns the message payload bytes exchanged with peers since the
+// process started, broken down by sub-protocol message type. Transport framing
+// and encryption overhead is not included, and message types never exchanged
+// are omitted.
+func TrafficStats() map[TrafficKey]Traffic {
+	trafficLock.Lock()
+	defer trafficLock.Unlock()
+
+	stats := make(map[TrafficKey]Traffic)
+	for proto, entries := range trafficStats {
+		for code, entry := range entries {
+			traffic := Traffic{
+				Ingress: atomic.LoadUint64(&entry.ingress),
+				Egress:  atomic.LoadUint64(&entry.egress),
+			}
+			if traffic.Ingress > 0 || traffic.Egress > 0 {
+				stats[TrafficKey{Protocol: proto.name, Version: proto.version, Code: uint64(code)}] = traffic
+			}
+		}
+	}
+	return stats
+}